@@ -0,0 +1,497 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/teawithsand/crypka"
+)
+
+// Size of chunks read from input, when stream encryption is used.
+const streamChunkSize = 32 * 1024
+
+func runKeygen(env *cliEnv, args []string) (err error) {
+	fs := env.newFlagSet("keygen")
+	out := fs.String("out", "-", "file to write secret key to")
+	err = fs.Parse(args)
+	if err != nil {
+		return
+	}
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+
+	algo, algoName, err := resolveAlgorithm(fs.Arg(0))
+	if err != nil {
+		return
+	}
+
+	var key interface{}
+	switch typedAlgo := algo.(type) {
+	case crypka.EncSymmAlgo:
+		// each encrypt command creates new encryptor, so key with counter nonces would reuse them
+		if !typedAlgo.GetInfo().IsRandomized {
+			err = fmt.Errorf("algorithm %s: %w", algoName, crypka.ErrEncNotRandomized)
+			break
+		}
+		key, err = typedAlgo.GenerateKey(nil, nil)
+	case crypka.EncAsymAlgo:
+		_, key, err = typedAlgo.GenerateKeyPair(nil, nil)
+	case crypka.SignSymmAlgo:
+		key, err = typedAlgo.GenerateKey(nil, nil)
+	case crypka.SignAsymAlgo:
+		key, _, err = typedAlgo.GenerateKeyPair(nil, nil)
+	case crypka.KXAlgo:
+		_, key, err = typedAlgo.GenerateKXPair(nil, nil)
+	default:
+		err = fmt.Errorf("algorithm %s does not use keys", algoName)
+	}
+	if err != nil {
+		return
+	}
+
+	return env.writeKeyFile(*out, algoName, crypka.SecretSerializedKeyType, key)
+}
+
+func runPubkey(env *cliEnv, args []string) (err error) {
+	fs := env.newFlagSet("pubkey")
+	keyPath := fs.String("key", "", "secret key file")
+	out := fs.String("out", "-", "file to write public key to")
+	err = fs.Parse(args)
+	if err != nil {
+		return
+	}
+	if fs.NArg() != 0 || len(*keyPath) == 0 {
+		return errUsage
+	}
+
+	sk, key, _, err := loadKey(*keyPath)
+	if err != nil {
+		return
+	}
+	if sk.Type != crypka.SecretSerializedKeyType {
+		return fmt.Errorf("key file %s: %w", *keyPath, errKeyTypeMismatch)
+	}
+
+	pub, err := crypka.DerivePublicKey(nil, key)
+	if err != nil {
+		return
+	}
+
+	return env.writeKeyFile(*out, sk.Algo, crypka.PublicSerializedKeyType, pub)
+}
+
+// cryptFlags are flags shared by encrypt and decrypt commands.
+type cryptFlags struct {
+	key string
+	in  string
+	out string
+}
+
+func (env *cliEnv) parseCryptFlags(name string, args []string) (flags cryptFlags, err error) {
+	fs := env.newFlagSet(name)
+	fs.StringVar(&flags.key, "key", "", "key file")
+	fs.StringVar(&flags.in, "in", "-", "input file")
+	fs.StringVar(&flags.out, "out", "-", "output file")
+	err = fs.Parse(args)
+	if err != nil {
+		return
+	}
+	if fs.NArg() != 0 || len(flags.key) == 0 {
+		err = errUsage
+	}
+	return
+}
+
+func isStreamAlgorithm(algo interface{}) bool {
+	encAlgo, ok := algo.(crypka.EncAlgo)
+	return ok && encAlgo.GetInfo().EncType == crypka.EncTypeStream
+}
+
+// processInput calls handler with chunks of input.
+// Whole input is passed at once unless stream is set.
+func processInput(r io.Reader, stream bool, handler func(chunk []byte) error) (err error) {
+	if !stream {
+		var data []byte
+		data, err = io.ReadAll(r)
+		if err != nil {
+			return
+		}
+		return handler(data)
+	}
+
+	buf := make([]byte, streamChunkSize)
+	for {
+		var sz int
+		sz, err = r.Read(buf)
+		if sz > 0 {
+			innerErr := handler(buf[:sz])
+			if innerErr != nil {
+				return innerErr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return
+		}
+	}
+}
+
+func runEncrypt(env *cliEnv, args []string) (err error) {
+	flags, err := env.parseCryptFlags("encrypt", args)
+	if err != nil {
+		return
+	}
+
+	_, key, algo, err := loadKey(flags.key)
+	if err != nil {
+		return
+	}
+	ek, ok := key.(crypka.EncKey)
+	if !ok {
+		return fmt.Errorf("key file %s: %w", flags.key, errKeyTypeMismatch)
+	}
+
+	enc, err := ek.MakeEncryptor(nil)
+	if err != nil {
+		return
+	}
+	if !enc.GetEncInfo().IsRandomized {
+		return fmt.Errorf("key file %s: %w", flags.key, crypka.ErrEncNotRandomized)
+	}
+
+	r, err := env.openInput(flags.in)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	w, err := env.openOutput(flags.out, false)
+	if err != nil {
+		return
+	}
+	defer w.Close()
+
+	var buf []byte
+	err = processInput(r, isStreamAlgorithm(algo), func(chunk []byte) (err error) {
+		buf, err = enc.Encrypt(chunk, buf[:0])
+		if err != nil {
+			return
+		}
+		_, err = w.Write(buf)
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	buf, err = enc.Finalize(buf[:0])
+	if err != nil {
+		return
+	}
+	_, err = w.Write(buf)
+	if err != nil {
+		return
+	}
+	return w.Close()
+}
+
+func runDecrypt(env *cliEnv, args []string) (err error) {
+	flags, err := env.parseCryptFlags("decrypt", args)
+	if err != nil {
+		return
+	}
+
+	_, key, algo, err := loadKey(flags.key)
+	if err != nil {
+		return
+	}
+	dk, ok := key.(crypka.DecKey)
+	if !ok {
+		return fmt.Errorf("key file %s: %w", flags.key, errKeyTypeMismatch)
+	}
+
+	dec, err := dk.MakeDecryptor(nil)
+	if err != nil {
+		return
+	}
+
+	r, err := env.openInput(flags.in)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	w, err := env.openOutput(flags.out, false)
+	if err != nil {
+		return
+	}
+	defer w.Close()
+
+	// Note: in stream mode, data is written before stream is finalized, so output is not authenticated
+	// until command succeeds.
+	var buf []byte
+	err = processInput(r, isStreamAlgorithm(algo), func(chunk []byte) (err error) {
+		buf, err = dec.Decrypt(chunk, buf[:0])
+		if err != nil {
+			return
+		}
+		_, err = w.Write(buf)
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	err = dec.Finalize()
+	if err != nil {
+		return
+	}
+	return w.Close()
+}
+
+func runSign(env *cliEnv, args []string) (err error) {
+	flags, err := env.parseCryptFlags("sign", args)
+	if err != nil {
+		return
+	}
+
+	_, key, _, err := loadKey(flags.key)
+	if err != nil {
+		return
+	}
+	signingKey, ok := key.(crypka.SigningKey)
+	if !ok {
+		return fmt.Errorf("key file %s: %w", flags.key, errKeyTypeMismatch)
+	}
+
+	signer, err := signingKey.MakeSigner(nil)
+	if err != nil {
+		return
+	}
+
+	r, err := env.openInput(flags.in)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	_, err = io.Copy(signer, r)
+	if err != nil {
+		return
+	}
+
+	sign, err := signer.Finalize(nil)
+	if err != nil {
+		return
+	}
+
+	w, err := env.openOutput(flags.out, false)
+	if err != nil {
+		return
+	}
+	defer w.Close()
+
+	_, err = fmt.Fprintln(w, base64.RawURLEncoding.EncodeToString(sign))
+	if err != nil {
+		return
+	}
+	return w.Close()
+}
+
+func runVerify(env *cliEnv, args []string) (err error) {
+	fs := env.newFlagSet("verify")
+	keyPath := fs.String("key", "", "public key file or secret key file")
+	signPath := fs.String("sig", "", "file with signature created by sign command")
+	in := fs.String("in", "-", "input file")
+	err = fs.Parse(args)
+	if err != nil {
+		return
+	}
+	if fs.NArg() != 0 || len(*keyPath) == 0 || len(*signPath) == 0 {
+		return errUsage
+	}
+
+	_, key, _, err := loadKey(*keyPath)
+	if err != nil {
+		return
+	}
+
+	vk, ok := key.(crypka.VerifyingKey)
+	if !ok {
+		signingKey, isSigningKey := key.(crypka.SigningKey)
+		if !isSigningKey {
+			return fmt.Errorf("key file %s: %w", *keyPath, errKeyTypeMismatch)
+		}
+
+		vk, err = crypka.DeriveVerifyingKey(nil, signingKey)
+		if err != nil {
+			return
+		}
+	}
+
+	signFile, err := env.openInput(*signPath)
+	if err != nil {
+		return
+	}
+	encodedSign, err := io.ReadAll(signFile)
+	signFile.Close()
+	if err != nil {
+		return
+	}
+
+	sign, err := base64.RawURLEncoding.DecodeString(string(bytes.TrimSpace(encodedSign)))
+	if err != nil {
+		return fmt.Errorf("signature file %s: %w", *signPath, err)
+	}
+
+	verifier, err := vk.MakeVerifier(nil)
+	if err != nil {
+		return
+	}
+
+	r, err := env.openInput(*in)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	_, err = io.Copy(verifier, r)
+	if err != nil {
+		return
+	}
+
+	err = verifier.Verify(sign)
+	if err != nil {
+		return
+	}
+
+	_, err = fmt.Fprintln(env.stdout, "OK")
+	return
+}
+
+// readPassword reads first line of stdin.
+func (env *cliEnv) readPassword() (password []byte, err error) {
+	line, err := bufio.NewReader(env.stdin).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return
+	}
+	err = nil
+
+	password = bytes.TrimRight(line, "\r\n")
+	return
+}
+
+func runHashPassword(env *cliEnv, args []string) (err error) {
+	fs := env.newFlagSet("hash-password")
+	algoName := fs.String("algo", "argon2id", "password hashing algorithm")
+	err = fs.Parse(args)
+	if err != nil {
+		return
+	}
+	if fs.NArg() != 0 {
+		return errUsage
+	}
+
+	hasher, err := crypka.GetTyped[crypka.PHasher](nil, *algoName)
+	if err != nil {
+		return fmt.Errorf("algorithm %s: %w", *algoName, err)
+	}
+
+	password, err := env.readPassword()
+	if err != nil {
+		return
+	}
+
+	hash, err := hasher.HashPassword(nil, password, nil)
+	if err != nil {
+		return
+	}
+
+	_, err = fmt.Fprintln(env.stdout, string(hash))
+	return
+}
+
+func runCheckPassword(env *cliEnv, args []string) (err error) {
+	fs := env.newFlagSet("check-password")
+	hash := fs.String("hash", "", "hash in PHC string format")
+	err = fs.Parse(args)
+	if err != nil {
+		return
+	}
+	if fs.NArg() != 0 || len(*hash) == 0 {
+		return errUsage
+	}
+
+	var parsed crypka.Argon2PasswordHash
+	err = parsed.Load(bytes.NewReader([]byte(*hash)))
+	if err != nil {
+		return
+	}
+
+	// Parameters are taken from hash, so hashes created with any of them can be checked.
+	hasher := &crypka.Argon2PasswordHasher{
+		AlgoName:   parsed.Name,
+		SaltLength: uint32(len(parsed.Salt)),
+		KeyLength:  uint32(len(parsed.Hash)),
+		Memory:     parsed.Memory,
+		Time:       parsed.Time,
+		Threads:    parsed.Threads,
+		KeyID:      parsed.KeyID,
+		Data:       parsed.Data,
+	}
+
+	password, err := env.readPassword()
+	if err != nil {
+		return
+	}
+
+	err = hasher.CheckPassword(nil, password, []byte(*hash))
+	if err != nil {
+		return
+	}
+
+	_, err = fmt.Fprintln(env.stdout, "OK")
+	return
+}
+
+func runListAlgos(env *cliEnv, args []string) (err error) {
+	fs := env.newFlagSet("list-algos")
+	typeName := fs.String("type", "", "list only algorithms of given type, like symm-enc or asym-sign")
+	err = fs.Parse(args)
+	if err != nil {
+		return
+	}
+	if fs.NArg() != 0 {
+		return errUsage
+	}
+
+	tw := tabwriter.NewWriter(env.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tSECURE\tDEPRECATED")
+	for _, name := range crypka.GlobalRegistry.ListAlgorithms() {
+		info, infoErr := crypka.GlobalRegistry.AlgorithmInfo(name)
+		if infoErr != nil {
+			continue
+		}
+		if len(*typeName) > 0 && info.Type.String() != *typeName {
+			continue
+		}
+
+		deprecated := "no"
+		if info.Deprecated {
+			deprecated = "yes"
+			if len(info.Successor) > 0 {
+				deprecated += ", use " + info.Successor
+			}
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%t\t%s\n", name, info.Type, info.IsSecure, deprecated)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/teawithsand/crypka"
+)
+
+// resolveAlgorithm returns algorithm with given name or specification and its canonical name.
+func resolveAlgorithm(name string) (algo interface{}, canonicalName string, err error) {
+	spec, err := crypka.ParseAlgorithmSpec(name)
+	if err != nil {
+		return
+	}
+
+	algo, err = crypka.BuildAlgorithmSpec(nil, spec)
+	if err != nil {
+		err = fmt.Errorf("algorithm %s: %w", name, err)
+		return
+	}
+
+	canonicalName = spec.String()
+	return
+}
+
+// openInput opens file with given path for reading or returns stdin if path is "-".
+func (env *cliEnv) openInput(path string) (r io.ReadCloser, err error) {
+	if path == "-" {
+		return io.NopCloser(env.stdin), nil
+	}
+	return os.Open(path)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// openOutput opens file with given path for writing or returns stdout if path is "-".
+// Secret outputs are created with permissions, which allow only owner to read them.
+func (env *cliEnv) openOutput(path string, secret bool) (w io.WriteCloser, err error) {
+	if path == "-" {
+		return nopWriteCloser{env.stdout}, nil
+	}
+
+	var perm os.FileMode = 0644
+	if secret {
+		perm = 0600
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+}
+
+func readKeyFile(path string) (sk crypka.SerializedKey, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		err = json.Unmarshal(data, &sk)
+	} else {
+		err = sk.UnmarshalText(data)
+	}
+	if err != nil {
+		err = fmt.Errorf("key file %s: %w", path, err)
+	}
+	return
+}
+
+func (env *cliEnv) writeKeyFile(path string, algoName string, ty crypka.SerializedKeyType, key interface{}) (err error) {
+	data, err := crypka.MarshalKeyToSlice(key)
+	if err != nil {
+		return
+	}
+
+	encoded, err := json.Marshal(crypka.SerializedKey{
+		Algo: algoName,
+		Type: ty,
+		Data: data,
+	})
+	if err != nil {
+		return
+	}
+
+	w, err := env.openOutput(path, ty == crypka.SecretSerializedKeyType)
+	if err != nil {
+		return
+	}
+	defer w.Close()
+
+	_, err = w.Write(append(encoded, '\n'))
+	if err != nil {
+		return
+	}
+	return w.Close()
+}
+
+// loadKey reads key file and parses key stored in it using algorithm it was tagged with.
+func loadKey(path string) (sk crypka.SerializedKey, key interface{}, algo interface{}, err error) {
+	sk, err = readKeyFile(path)
+	if err != nil {
+		return
+	}
+
+	algo, canonicalName, err := resolveAlgorithm(sk.Algo)
+	if err != nil {
+		return
+	}
+
+	// Algorithms given by specification are not registered, so child registry is used in order to parse key.
+	sk.Algo = canonicalName
+	reg := crypka.NewChildRegistry(nil)
+	if reg.GetAlgo(sk.Algo) == nil {
+		reg.RegisterAlgo(sk.Algo, algo)
+	}
+
+	key, err = crypka.ParseSerializedKey(nil, reg, sk)
+	if err != nil {
+		err = fmt.Errorf("key file %s: %w", path, err)
+	}
+	return
+}
+
+var errKeyTypeMismatch = errors.New("key can't be used for this operation")
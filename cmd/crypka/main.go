@@ -0,0 +1,116 @@
+// Command crypka generates keys, encrypts, signs and hashes passwords using algorithms from crypka's global registry.
+//
+// Algorithms are given either by registered name, like "ed25519-sha-512", or by algorithm specification,
+// like "enc-kx(x25519,cpk-stream(aes-256-gcm-counter))".
+//
+// Keys are stored in self-describing JSON files, which contain algorithm name, key type and key itself:
+//
+//	{"algo":"cpk-stream(aes-256-gcm-rng)","type":"secret","key":"..."}
+//
+// Secret keys are stored in plain form, so files containing them must be protected.
+//
+// Each encrypt run creates new encryptor, so symmetric encryption algorithms must use random nonces.
+// Ones with nonce counters, like "aes-256-gcm-counter", are rejected, since all files would be encrypted with same nonces.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// errUsage is returned when command was invoked with invalid arguments.
+var errUsage = errors.New("invalid usage")
+
+type command struct {
+	usage string
+	run   func(env *cliEnv, args []string) error
+}
+
+var commands map[string]command
+
+// commands are set in init, since they refer to it in their usage messages.
+func init() {
+	commands = map[string]command{
+		"keygen":         {"keygen [-out file] <algo>", runKeygen},
+		"pubkey":         {"pubkey -key file [-out file]", runPubkey},
+		"encrypt":        {"encrypt -key file [-in file] [-out file]", runEncrypt},
+		"decrypt":        {"decrypt -key file [-in file] [-out file]", runDecrypt},
+		"sign":           {"sign -key file [-in file] [-out file]", runSign},
+		"verify":         {"verify -key file -sig file [-in file]", runVerify},
+		"hash-password":  {"hash-password [-algo name] < password", runHashPassword},
+		"check-password": {"check-password -hash hash < password", runCheckPassword},
+		"list-algos":     {"list-algos [-type type]", runListAlgos},
+	}
+}
+
+// cliEnv contains streams command operates on, so it can be run in tests.
+type cliEnv struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (env *cliEnv) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(env.stderr, "usage: crypka", commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func (env *cliEnv) printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(env.stderr, "usage: crypka <command> [arguments]")
+	fmt.Fprintln(env.stderr, "commands:")
+	for _, name := range names {
+		fmt.Fprintln(env.stderr, "  crypka", commands[name].usage)
+	}
+}
+
+// run executes command given in args and returns exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	env := &cliEnv{
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	if len(args) == 0 {
+		env.printUsage()
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintln(stderr, "crypka: unknown command", args[0])
+		env.printUsage()
+		return 2
+	}
+
+	err := cmd.run(env, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	} else if errors.Is(err, errUsage) {
+		fmt.Fprintln(stderr, "usage: crypka", cmd.usage)
+		return 2
+	} else if err != nil {
+		fmt.Fprintln(stderr, "crypka:", err)
+		return 1
+	}
+	return 0
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+func runCLI(t *testing.T, stdin string, args ...string) (stdout string, code int) {
+	var outBuf, errBuf bytes.Buffer
+	code = run(args, strings.NewReader(stdin), &outBuf, &errBuf)
+	if code != 0 {
+		t.Log("stderr:", errBuf.String())
+	}
+	return outBuf.String(), code
+}
+
+func TestCLI_EncryptDecrypt(t *testing.T) {
+	for _, algo := range []string{
+		"cpk-stream(aes-256-gcm-rng)",
+		"aes-128-gcm-rng",
+		"x25519-chacha20-poly1305-counter",
+		"enc-kx(x25519,cpk-stream(aes-256-gcm-counter,chunk=4k))",
+	} {
+		dir := t.TempDir()
+		secretPath := filepath.Join(dir, "secret.json")
+		publicPath := filepath.Join(dir, "public.json")
+		plainPath := filepath.Join(dir, "plain")
+		encPath := filepath.Join(dir, "enc")
+
+		if _, code := runCLI(t, "", "keygen", "-out", secretPath, algo); code != 0 {
+			t.Error("keygen failed for", algo)
+			continue
+		}
+
+		encKeyPath := secretPath
+		if strings.HasPrefix(algo, "x25519") || strings.HasPrefix(algo, "enc-kx") {
+			if _, code := runCLI(t, "", "pubkey", "-key", secretPath, "-out", publicPath); code != 0 {
+				t.Error("pubkey failed for", algo)
+				continue
+			}
+			encKeyPath = publicPath
+		}
+
+		plaintext := strings.Repeat("some secret data ", 10000)
+		if _, code := runCLI(t, plaintext, "encrypt", "-key", encKeyPath, "-out", encPath); code != 0 {
+			t.Error("encrypt failed for", algo)
+			continue
+		}
+
+		if _, code := runCLI(t, "", "decrypt", "-key", secretPath, "-in", encPath, "-out", plainPath); code != 0 {
+			t.Error("decrypt failed for", algo)
+			continue
+		}
+
+		out, code := runCLI(t, "", "decrypt", "-key", secretPath, "-in", encPath)
+		if code != 0 || out != plaintext {
+			t.Error("invalid plaintext for", algo)
+			continue
+		}
+
+		first, _ := runCLI(t, plaintext, "encrypt", "-key", encKeyPath)
+		second, code := runCLI(t, plaintext, "encrypt", "-key", encKeyPath)
+		if code != 0 || first == second {
+			t.Error("expected encryptions of same input to differ for", algo)
+			continue
+		}
+
+		if _, code := runCLI(t, "", "decrypt", "-key", secretPath, "-in", plainPath); code == 0 {
+			t.Error("expected decryption of invalid data to fail for", algo)
+		}
+	}
+}
+
+func TestCLI_RejectsCounterNonce(t *testing.T) {
+	for _, algo := range []string{
+		"cpk-stream-aes-256-gcm-counter",
+		"aes-128-gcm-counter",
+		"padded(chacha20-poly1305-counter,pad-padme)",
+	} {
+		if _, code := runCLI(t, "", "keygen", algo); code == 0 {
+			t.Error("expected keygen to fail for", algo)
+		}
+	}
+
+	// keys created by other tools are rejected as well
+	algo, err := crypka.GetTyped[crypka.EncSymmAlgo](nil, "aes-128-gcm-counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := algo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := crypka.MarshalKeyToSlice(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(crypka.SerializedKey{
+		Algo: "aes-128-gcm-counter",
+		Type: crypka.SecretSerializedKeyType,
+		Data: data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyPath := filepath.Join(t.TempDir(), "secret.json")
+	err = os.WriteFile(keyPath, encoded, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, code := runCLI(t, "data", "encrypt", "-key", keyPath); code == 0 {
+		t.Error("expected encrypt to fail for counter nonce key")
+	}
+}
+
+func TestCLI_SignVerify(t *testing.T) {
+	for _, algo := range []string{"ed25519-sha-512", "hmac-sha-256"} {
+		dir := t.TempDir()
+		secretPath := filepath.Join(dir, "secret.json")
+		signPath := filepath.Join(dir, "sign")
+
+		if _, code := runCLI(t, "", "keygen", "-out", secretPath, algo); code != 0 {
+			t.Error("keygen failed for", algo)
+			continue
+		}
+
+		verifyKeyPath := secretPath
+		if algo == "ed25519-sha-512" {
+			verifyKeyPath = filepath.Join(dir, "public.json")
+			if _, code := runCLI(t, "", "pubkey", "-key", secretPath, "-out", verifyKeyPath); code != 0 {
+				t.Error("pubkey failed for", algo)
+				continue
+			}
+		}
+
+		if _, code := runCLI(t, "data", "sign", "-key", secretPath, "-out", signPath); code != 0 {
+			t.Error("sign failed for", algo)
+			continue
+		}
+
+		if out, code := runCLI(t, "data", "verify", "-key", verifyKeyPath, "-sig", signPath); code != 0 || out != "OK\n" {
+			t.Error("verify failed for", algo)
+			continue
+		}
+
+		if _, code := runCLI(t, "other data", "verify", "-key", verifyKeyPath, "-sig", signPath); code != 1 {
+			t.Error("expected verification of other data to fail for", algo)
+		}
+	}
+}
+
+func TestCLI_Password(t *testing.T) {
+	hash, code := runCLI(t, "password\n", "hash-password")
+	if code != 0 || !strings.HasPrefix(hash, "$argon2id$") {
+		t.Error("hash-password failed", hash)
+		return
+	}
+	hash = strings.TrimSpace(hash)
+
+	if out, code := runCLI(t, "password\n", "check-password", "-hash", hash); code != 0 || out != "OK\n" {
+		t.Error("check-password failed")
+		return
+	}
+	if _, code := runCLI(t, "other\n", "check-password", "-hash", hash); code != 1 {
+		t.Error("expected check-password to fail for other password")
+		return
+	}
+}
+
+func TestCLI_ListAlgos(t *testing.T) {
+	out, code := runCLI(t, "", "list-algos", "-type", "kx")
+	if code != 0 {
+		t.Error("list-algos failed")
+		return
+	}
+	if !strings.Contains(out, "x25519") || strings.Contains(out, "ed25519") {
+		t.Error("invalid list-algos output", out)
+		return
+	}
+}
+
+func TestCLI_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"unknown"},
+		{"keygen"},
+		{"encrypt"},
+		{"verify", "-key", "x"},
+	} {
+		if _, code := runCLI(t, "", args...); code != 2 {
+			t.Error("expected usage error for", args)
+		}
+	}
+
+	if _, code := runCLI(t, "", "keygen", "sha-256-missing"); code != 1 {
+		t.Error("expected error for unknown algorithm")
+	}
+}
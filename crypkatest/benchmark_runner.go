@@ -0,0 +1,465 @@
+package crypkatest
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/teawithsand/crypka"
+)
+
+var DefaultBenchmarkPayloadSizes = []int{64, 1024, 16 * 1024, 1024 * 1024}
+
+const defaultBenchmarkMinDuration = time.Millisecond * 200
+
+// Operations measured by BenchmarkRunner.
+const (
+	BenchmarkOpKeygen   = "keygen"
+	BenchmarkOpEncrypt  = "encrypt"
+	BenchmarkOpDecrypt  = "decrypt"
+	BenchmarkOpSign     = "sign"
+	BenchmarkOpVerify   = "verify"
+	BenchmarkOpExchange = "exchange"
+	BenchmarkOpRead     = "read"
+	BenchmarkOpHash     = "hash"
+)
+
+// BenchmarkRunner benchmarks all algorithms from registry, so they can be compared with each other.
+//
+// Encryption, signing and RNG algorithms are benchmarked for each payload size, key exchange and password hashing ones
+// are benchmarked once, since they do not process any payload.
+// Proof of work algorithms are skipped, since time of solving challenge is random.
+type BenchmarkRunner struct {
+	// Registry to take algorithms from. GlobalRegistry is used if nil.
+	Registry crypka.Registry
+
+	// Types of algorithms to benchmark. All types are benchmarked if empty.
+	Types []crypka.AlgorithmType
+
+	// Filter, which tells if algorithm with given name should be benchmarked. All algorithms are benchmarked if nil.
+	Filter func(name string) bool
+
+	// Defaults to DefaultBenchmarkPayloadSizes.
+	PayloadSizes []int
+
+	// Min time spent on measuring single operation in Run. Defaults to 200ms.
+	MinDuration time.Duration
+
+	// RNG used to generate keys and payloads. crypto/rand is used if nil.
+	RNG crypka.RNG
+}
+
+// BenchmarkResult is result of measuring single operation of single algorithm.
+type BenchmarkResult struct {
+	Algorithm   string `json:"algorithm"`
+	Type        string `json:"type"`
+	Operation   string `json:"operation"`
+	PayloadSize int    `json:"payloadSize"`
+
+	Iterations     int     `json:"iterations"`
+	NsPerOp        int64   `json:"nsPerOp"`
+	BytesPerSecond float64 `json:"bytesPerSecond"`
+
+	// Set if operation is not supported by algorithm for given payload size, for instance because of its max input length.
+	Error string `json:"error,omitempty"`
+}
+
+type BenchmarkResults []BenchmarkResult
+
+// WriteCSV writes results as CSV with header.
+func (results BenchmarkResults) WriteCSV(w io.Writer) (err error) {
+	cw := csv.NewWriter(w)
+	err = cw.Write([]string{
+		"algorithm", "type", "operation", "payload_size",
+		"iterations", "ns_per_op", "bytes_per_second", "error",
+	})
+	if err != nil {
+		return
+	}
+
+	for _, res := range results {
+		err = cw.Write([]string{
+			res.Algorithm,
+			res.Type,
+			res.Operation,
+			strconv.Itoa(res.PayloadSize),
+			strconv.Itoa(res.Iterations),
+			strconv.FormatInt(res.NsPerOp, 10),
+			strconv.FormatFloat(res.BytesPerSecond, 'f', 0, 64),
+			res.Error,
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes results as JSON array.
+func (results BenchmarkResults) WriteJSON(w io.Writer) (err error) {
+	if results == nil {
+		results = BenchmarkResults{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(results)
+}
+
+type benchmarkCase struct {
+	algorithm   string
+	algoType    crypka.AlgorithmType
+	operation   string
+	payloadSize int
+
+	// either err or op is set
+	err error
+	op  func() error
+}
+
+func (bc *benchmarkCase) name() string {
+	name := bc.algorithm + "/" + bc.operation
+	if bc.payloadSize > 0 {
+		name += "_" + strconv.Itoa(bc.payloadSize)
+	}
+	return name
+}
+
+func (runner *BenchmarkRunner) getRegistry() crypka.Registry {
+	if runner.Registry == nil {
+		return crypka.GlobalRegistry
+	}
+	return runner.Registry
+}
+
+func (runner *BenchmarkRunner) getRNG() crypka.RNG {
+	if runner.RNG == nil {
+		return rand.Reader
+	}
+	return runner.RNG
+}
+
+func (runner *BenchmarkRunner) getPayloadSizes() []int {
+	if len(runner.PayloadSizes) == 0 {
+		return DefaultBenchmarkPayloadSizes
+	}
+	return runner.PayloadSizes
+}
+
+func (runner *BenchmarkRunner) isTypeSelected(algoType crypka.AlgorithmType) bool {
+	if len(runner.Types) == 0 {
+		return true
+	}
+	for _, t := range runner.Types {
+		if t == algoType {
+			return true
+		}
+	}
+	return false
+}
+
+func (runner *BenchmarkRunner) makeCases() (cases []benchmarkCase) {
+	reg := runner.getRegistry()
+	for _, name := range reg.ListAlgorithms() {
+		if runner.Filter != nil && !runner.Filter(name) {
+			continue
+		}
+
+		info, infoErr := reg.AlgorithmInfo(name)
+		if infoErr != nil || !runner.isTypeSelected(info.Type) {
+			continue
+		}
+
+		cases = append(cases, runner.makeAlgorithmCases(name, info.Type, reg.GetAlgo(name))...)
+	}
+	return
+}
+
+func (runner *BenchmarkRunner) makeAlgorithmCases(
+	name string,
+	algoType crypka.AlgorithmType,
+	algo interface{},
+) (cases []benchmarkCase) {
+	rng := runner.getRNG()
+
+	addCase := func(operation string, payloadSize int, op func() error, opErr error) {
+		cases = append(cases, benchmarkCase{
+			algorithm:   name,
+			algoType:    algoType,
+			operation:   operation,
+			payloadSize: payloadSize,
+			op:          op,
+			err:         opErr,
+		})
+	}
+
+	addEncCases := func(keygen func() (crypka.EncKey, crypka.DecKey, error)) {
+		addCase(BenchmarkOpKeygen, 0, func() (err error) {
+			_, _, err = keygen()
+			return
+		}, nil)
+
+		ek, dk, keyErr := keygen()
+		for _, sz := range runner.getPayloadSizes() {
+			if keyErr != nil {
+				addCase(BenchmarkOpEncrypt, sz, nil, keyErr)
+				addCase(BenchmarkOpDecrypt, sz, nil, keyErr)
+				continue
+			}
+
+			payload := RNGReadBuffer(rng, sz)
+			var buf []byte
+			encrypt := func() (err error) {
+				enc, err := ek.MakeEncryptor(nil)
+				if err != nil {
+					return
+				}
+				buf, err = enc.Encrypt(payload, buf[:0])
+				if err != nil {
+					return
+				}
+				buf, err = enc.Finalize(buf)
+				return
+			}
+
+			encErr := encrypt()
+			if encErr != nil {
+				addCase(BenchmarkOpEncrypt, sz, nil, encErr)
+				addCase(BenchmarkOpDecrypt, sz, nil, encErr)
+				continue
+			}
+			addCase(BenchmarkOpEncrypt, sz, encrypt, nil)
+
+			ciphertext := append([]byte{}, buf...)
+			var plaintext []byte
+			addCase(BenchmarkOpDecrypt, sz, func() (err error) {
+				dec, err := dk.MakeDecryptor(nil)
+				if err != nil {
+					return
+				}
+				plaintext, err = dec.Decrypt(ciphertext, plaintext[:0])
+				if err != nil {
+					return
+				}
+				return dec.Finalize()
+			}, nil)
+		}
+	}
+
+	addSignCases := func(keygen func() (crypka.SigningKey, crypka.VerifyingKey, error)) {
+		addCase(BenchmarkOpKeygen, 0, func() (err error) {
+			_, _, err = keygen()
+			return
+		}, nil)
+
+		sk, vk, keyErr := keygen()
+		for _, sz := range runner.getPayloadSizes() {
+			if keyErr != nil {
+				addCase(BenchmarkOpSign, sz, nil, keyErr)
+				addCase(BenchmarkOpVerify, sz, nil, keyErr)
+				continue
+			}
+
+			payload := RNGReadBuffer(rng, sz)
+			var sign []byte
+			signPayload := func() (err error) {
+				signer, err := sk.MakeSigner(nil)
+				if err != nil {
+					return
+				}
+				_, err = signer.Write(payload)
+				if err != nil {
+					return
+				}
+				sign, err = signer.Finalize(sign[:0])
+				return
+			}
+
+			signErr := signPayload()
+			if signErr != nil {
+				addCase(BenchmarkOpSign, sz, nil, signErr)
+				addCase(BenchmarkOpVerify, sz, nil, signErr)
+				continue
+			}
+			addCase(BenchmarkOpSign, sz, signPayload, nil)
+
+			validSign := append([]byte{}, sign...)
+			addCase(BenchmarkOpVerify, sz, func() (err error) {
+				verifier, err := vk.MakeVerifier(nil)
+				if err != nil {
+					return
+				}
+				_, err = verifier.Write(payload)
+				if err != nil {
+					return
+				}
+				return verifier.Verify(validSign)
+			}, nil)
+		}
+	}
+
+	switch typedAlgo := algo.(type) {
+	case crypka.EncSymmAlgo:
+		addEncCases(func() (ek crypka.EncKey, dk crypka.DecKey, err error) {
+			key, err := typedAlgo.GenerateKey(nil, rng)
+			return key, key, err
+		})
+	case crypka.EncAsymAlgo:
+		addEncCases(func() (crypka.EncKey, crypka.DecKey, error) {
+			return typedAlgo.GenerateKeyPair(nil, rng)
+		})
+	case crypka.SignSymmAlgo:
+		addSignCases(func() (sk crypka.SigningKey, vk crypka.VerifyingKey, err error) {
+			key, err := typedAlgo.GenerateKey(nil, rng)
+			return key, key, err
+		})
+	case crypka.SignAsymAlgo:
+		addSignCases(func() (crypka.SigningKey, crypka.VerifyingKey, error) {
+			return typedAlgo.GenerateKeyPair(nil, rng)
+		})
+	case crypka.KXAlgo:
+		addCase(BenchmarkOpKeygen, 0, func() (err error) {
+			_, _, err = typedAlgo.GenerateKXPair(nil, rng)
+			return
+		}, nil)
+
+		public, _, keyErr := typedAlgo.GenerateKXPair(nil, rng)
+		if keyErr != nil {
+			addCase(BenchmarkOpExchange, 0, nil, keyErr)
+			break
+		}
+		_, secret, keyErr := typedAlgo.GenerateKXPair(nil, rng)
+		if keyErr != nil {
+			addCase(BenchmarkOpExchange, 0, nil, keyErr)
+			break
+		}
+
+		resLen := typedAlgo.GetInfo().MaxResLen
+		if resLen == 0 {
+			resLen = 32
+		}
+		res := make([]byte, resLen)
+		addCase(BenchmarkOpExchange, 0, func() error {
+			return typedAlgo.PerformExchange(nil, public, secret, res)
+		}, nil)
+	case crypka.RNGAlgo:
+		for _, sz := range runner.getPayloadSizes() {
+			seed, seedErr := crypka.GenerateReasonableRNGSeed(rng, typedAlgo.GetInfo())
+			if seedErr != nil {
+				addCase(BenchmarkOpRead, sz, nil, seedErr)
+				continue
+			}
+
+			algoRNG, rngErr := typedAlgo.MakeRng(nil, seed)
+			if rngErr != nil {
+				addCase(BenchmarkOpRead, sz, nil, rngErr)
+				continue
+			}
+
+			buf := make([]byte, sz)
+			addCase(BenchmarkOpRead, sz, func() (err error) {
+				_, err = io.ReadFull(algoRNG, buf)
+				return
+			}, nil)
+		}
+	case crypka.PHasher:
+		password := []byte("benchmark password")
+		var hash []byte
+		addCase(BenchmarkOpHash, 0, func() (err error) {
+			hash, err = typedAlgo.HashPassword(nil, password, hash[:0])
+			return
+		}, nil)
+	}
+	return
+}
+
+func (runner *BenchmarkRunner) measure(op func() error) (iterations int, elapsed time.Duration, err error) {
+	minDuration := runner.MinDuration
+	if minDuration <= 0 {
+		minDuration = defaultBenchmarkMinDuration
+	}
+
+	n := 1
+	for elapsed < minDuration {
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			err = op()
+			if err != nil {
+				return
+			}
+		}
+		elapsed += time.Since(start)
+		iterations += n
+
+		n *= 2
+	}
+	return
+}
+
+// Run measures all operations of all algorithms selected and returns results.
+// Errors returned by operations are stored in results.
+func (runner *BenchmarkRunner) Run() (results BenchmarkResults) {
+	for _, bc := range runner.makeCases() {
+		res := BenchmarkResult{
+			Algorithm:   bc.algorithm,
+			Type:        bc.algoType.String(),
+			Operation:   bc.operation,
+			PayloadSize: bc.payloadSize,
+		}
+
+		opErr := bc.err
+		if opErr == nil {
+			var elapsed time.Duration
+			res.Iterations, elapsed, opErr = runner.measure(bc.op)
+			if opErr == nil {
+				res.NsPerOp = elapsed.Nanoseconds() / int64(res.Iterations)
+				if bc.payloadSize > 0 {
+					res.BytesPerSecond = float64(bc.payloadSize) * float64(res.Iterations) / elapsed.Seconds()
+				}
+			}
+		}
+		if opErr != nil {
+			res.Iterations = 0
+			res.Error = opErr.Error()
+		}
+
+		results = append(results, res)
+	}
+	return
+}
+
+// Benchmark runs all operations of all algorithms selected as sub-benchmarks named "<algorithm>/<operation>_<payload size>".
+// Operations, which are not supported, are skipped.
+func (runner *BenchmarkRunner) Benchmark(b *testing.B) {
+	cases := runner.makeCases()
+	if len(cases) == 0 {
+		b.Skip("no algorithms to benchmark")
+		return
+	}
+
+	for _, bc := range cases {
+		bc := bc
+		b.Run(bc.name(), func(b *testing.B) {
+			if bc.err != nil {
+				b.Skip(bc.err)
+				return
+			}
+
+			b.SetBytes(int64(bc.payloadSize))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				err := bc.op()
+				if err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	}
+}
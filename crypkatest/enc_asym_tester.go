@@ -21,12 +21,19 @@ type EncAsymTester struct {
 
 	NotMarshalable bool
 	IsBlank        bool
+
+	// Sizes of chunks encrypted before ciphertext is attacked.
+	// Defaults to DefaultEncAttackChunkSizes.
+	AttackChunkSizes []int
 }
 
 func (tester *EncAsymTester) init() {
 	if tester.TestScopeUtil.ChunkRunnerConfig.IsEmpty() {
 		tester.TestScopeUtil.ChunkRunnerConfig = DefaultEncChunkRunnerConfig
 	}
+	if len(tester.AttackChunkSizes) == 0 {
+		tester.AttackChunkSizes = DefaultEncAttackChunkSizes
+	}
 }
 
 func (tester *EncAsymTester) encryptAndDecryptStreamData(
@@ -51,11 +58,12 @@ func (tester *EncAsymTester) encryptAndDecryptChainData(chunks [][]byte, bag Enc
 
 func (tester *EncAsymTester) Test(t *testing.T) {
 	tester.init()
+	tester.TestScopeUtil.LogSeed(t)
 
 	if tester.Algo.GetInfo().EncType == crypka.EncTypeStream {
 		t.Run("enc_stream", func(t *testing.T) {
 			t.Run("valid_encryption", func(t *testing.T) {
-				scope := tester.TestScopeUtil.GetTestScope()
+				scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 				err := scope.GetChunkRunner().RunWithSameChunks(func(chunks [][]byte) (err error) {
 					ek, dk, err := tester.Algo.GenerateKeyPair(nil, scope.GetRNG())
@@ -76,7 +84,7 @@ func (tester *EncAsymTester) Test(t *testing.T) {
 
 			if !tester.IsBlank {
 				t.Run("invalid_when_key_mistmatch", func(t *testing.T) {
-					scope := tester.TestScopeUtil.GetTestScope()
+					scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 					err := scope.GetChunkRunner().RunWithSameChunks(func(chunks [][]byte) (err error) {
 						err = tester.encryptAndDecryptStreamData(chunks, nil, EncKeyBag{
@@ -102,7 +110,7 @@ func (tester *EncAsymTester) Test(t *testing.T) {
 	if tester.Algo.GetInfo().EncType == crypka.EncTypeChain {
 		t.Run("enc_chain", func(t *testing.T) {
 			t.Run("valid_encryption", func(t *testing.T) {
-				scope := tester.TestScopeUtil.GetTestScope()
+				scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 				err := scope.GetChunkRunner().RunWithSameChunks(func(chunks [][]byte) (err error) {
 					ek, dk, err := tester.Algo.GenerateKeyPair(nil, scope.GetRNG())
@@ -122,7 +130,7 @@ func (tester *EncAsymTester) Test(t *testing.T) {
 			})
 
 			t.Run("invalid_when_key_mistmatch", func(t *testing.T) {
-				scope := tester.TestScopeUtil.GetTestScope()
+				scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 				err := scope.GetChunkRunner().RunWithSameChunks(func(chunks [][]byte) (err error) {
 					err = tester.encryptAndDecryptChainData(chunks, EncKeyBag{
@@ -144,10 +152,35 @@ func (tester *EncAsymTester) Test(t *testing.T) {
 		})
 	}
 
+	// Attacks are run according to auth mode algorithm claims, so they are no-op for not authenticated ones.
+	info := tester.Algo.GetInfo()
+	if !info.AuthMode.IsZero() {
+		t.Run("attacks", func(t *testing.T) {
+			scope := tester.TestScopeUtil.GetTestScopeFor(t)
+
+			ek, dk, err := tester.Algo.GenerateKeyPair(nil, scope.GetRNG())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			bag := EncKeyBag{
+				EncKey: ek,
+				DecKey: dk,
+			}
+
+			runner, err := newEncAttackRunner(info, bag, RNGReadBuffers(scope.GetRNG(), tester.AttackChunkSizes...))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			runner.Test(t)
+		})
+	}
+
 	if !tester.NotMarshalable {
 		if tester.Algo.GetInfo().EncType == crypka.EncTypeChain || tester.Algo.GetInfo().EncType == crypka.EncTypeBlock {
 			t.Run("can_marshal_symm_signing_key__chain_test", func(t *testing.T) {
-				scope := tester.TestScopeUtil.GetTestScope()
+				scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 				originalEk, originalDk, err := tester.Algo.GenerateKeyPair(nil, scope.GetRNG())
 				if err != nil {
@@ -0,0 +1,213 @@
+package crypkatest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+// Sizes of chunks encrypted in order to attack ciphertext.
+// Sizes are multiples of 8 and at least 16, so that key wrapping is able to encrypt them.
+var DefaultEncAttackChunkSizes = []int{24, 16, 600}
+
+// encAttackRunner checks if decryptor detects tampering with ciphertext, according to EncAuthMode algorithm claims.
+//
+// Ciphertext is kept as pieces yielded by subsequent Encrypt calls followed by result of Finalize, if it's not empty.
+// Tampered ciphertext is decrypted with new decryptor, which gets each piece in separate Decrypt call.
+type encAttackRunner struct {
+	info   crypka.EncAlgoInfo
+	decKey crypka.DecKey
+
+	plaintext []byte
+	pieces    [][]byte
+	hasFinal  bool
+}
+
+func newEncAttackRunner(info crypka.EncAlgoInfo, bag EncKeyBag, chunks [][]byte) (runner *encAttackRunner, err error) {
+	runner = &encAttackRunner{
+		info:   info,
+		decKey: bag.DecKey,
+	}
+
+	enc, err := bag.EncKey.MakeEncryptor(nil)
+	if err != nil {
+		return
+	}
+
+	for _, chunk := range chunks {
+		var piece []byte
+		piece, err = enc.Encrypt(chunk, nil)
+		if err != nil {
+			return
+		}
+		runner.plaintext = append(runner.plaintext, chunk...)
+		runner.pieces = append(runner.pieces, piece)
+	}
+
+	final, err := enc.Finalize(nil)
+	if err != nil {
+		return
+	}
+	if len(final) > 0 {
+		runner.pieces = append(runner.pieces, final)
+		runner.hasFinal = true
+	}
+	return
+}
+
+func joinPieces(pieces [][]byte) (res []byte) {
+	for _, p := range pieces {
+		res = append(res, p...)
+	}
+	return
+}
+
+// isUnchanged returns true if tampered pieces yield same ciphertext, so decryptor may accept them.
+// For stream encryption piece boundaries don't matter.
+func (runner *encAttackRunner) isUnchanged(pieces [][]byte) bool {
+	if runner.info.EncType == crypka.EncTypeStream {
+		return bytes.Equal(joinPieces(runner.pieces), joinPieces(pieces))
+	}
+	return BuffersEqual(runner.pieces, pieces)
+}
+
+// decrypt decrypts tampered pieces.
+// Leaked is set when some Decrypt call yielded data, which is not prefix of original plaintext.
+// Accepted is set when all Decrypt calls and Finalize succeeded.
+func (runner *encAttackRunner) decrypt(pieces [][]byte) (leaked, accepted bool, err error) {
+	dec, err := runner.decKey.MakeDecryptor(nil)
+	if err != nil {
+		return
+	}
+
+	var res []byte
+	for _, piece := range pieces {
+		var decErr error
+		res, decErr = dec.Decrypt(piece, res)
+		if len(res) > len(runner.plaintext) || !bytes.Equal(res, runner.plaintext[:len(res)]) {
+			leaked = true
+		}
+		if decErr != nil {
+			return
+		}
+	}
+
+	accepted = dec.Finalize() == nil
+	return
+}
+
+// check runs tampered ciphertext and reports attack, which went through.
+// If isTruncation is set, then accepted ciphertext is violation of trunc authentication, otherwise of finalize authentication.
+//
+// Returns false if attack went through, so that suite stops after first one.
+func (runner *encAttackRunner) check(t *testing.T, pieces [][]byte, isTruncation bool, description ...interface{}) (ok bool) {
+	if runner.isUnchanged(pieces) {
+		return true
+	}
+
+	leaked, accepted, err := runner.decrypt(pieces)
+	if err != nil {
+		t.Error(append(description, err)...)
+		return
+	}
+
+	if leaked && runner.info.AuthMode.IsEagerAuthenticated() {
+		t.Error(append([]interface{}{"algorithm claims to be eager authenticated, but yielded modified data:"}, description...)...)
+		return
+	}
+	if accepted {
+		if isTruncation && runner.info.AuthMode.IsTruncAuthenticated() {
+			t.Error(append([]interface{}{"algorithm claims to be trunc authenticated, but accepted truncated ciphertext:"}, description...)...)
+			return
+		} else if !isTruncation && runner.info.AuthMode.IsFinalizeAuthetnicated() {
+			t.Error(append([]interface{}{"algorithm claims to be finalize authenticated, but accepted modified ciphertext:"}, description...)...)
+			return
+		}
+	}
+	return true
+}
+
+func copyPieces(pieces [][]byte) (res [][]byte) {
+	res = make([][]byte, len(pieces))
+	for i, p := range pieces {
+		res[i] = append([]byte{}, p...)
+	}
+	return
+}
+
+func (runner *encAttackRunner) Test(t *testing.T) {
+	t.Run("valid_ciphertext", func(t *testing.T) {
+		leaked, accepted, err := runner.decrypt(runner.pieces)
+		if err != nil {
+			t.Error(err)
+		} else if leaked || !accepted {
+			t.Error("untampered ciphertext was not decrypted properly")
+		}
+	})
+
+	t.Run("bit_flips", func(t *testing.T) {
+		for i, piece := range runner.pieces {
+			for j := range piece {
+				pieces := copyPieces(runner.pieces)
+				pieces[i][j] ^= 1 << (j % 8)
+				if !runner.check(t, pieces, false, "bit flip in piece", i, "byte", j) {
+					return
+				}
+			}
+		}
+	})
+
+	// blocks are independent of each other by design, so there is nothing more to check
+	if runner.info.EncType == crypka.EncTypeBlock {
+		return
+	}
+
+	t.Run("chunk_reorder", func(t *testing.T) {
+		for i := 0; i+1 < len(runner.pieces); i++ {
+			pieces := copyPieces(runner.pieces)
+			pieces[i], pieces[i+1] = pieces[i+1], pieces[i]
+			if !runner.check(t, pieces, false, "swapped pieces", i, i+1) {
+				return
+			}
+		}
+
+		pieces := copyPieces(runner.pieces)
+		pieces[0], pieces[len(pieces)-1] = pieces[len(pieces)-1], pieces[0]
+		runner.check(t, pieces, false, "swapped first and last piece")
+	})
+
+	t.Run("chunk_duplicate", func(t *testing.T) {
+		for i := range runner.pieces {
+			var pieces [][]byte
+			pieces = append(pieces, runner.pieces[:i+1]...)
+			pieces = append(pieces, runner.pieces[i:]...)
+			if !runner.check(t, copyPieces(pieces), false, "duplicated piece", i) {
+				return
+			}
+		}
+	})
+
+	t.Run("truncation", func(t *testing.T) {
+		if runner.info.EncType == crypka.EncTypeStream {
+			ciphertext := joinPieces(runner.pieces)
+			for i := 0; i < len(ciphertext); i++ {
+				if !runner.check(t, [][]byte{ciphertext[:i]}, true, "truncated at byte", i) {
+					return
+				}
+			}
+		} else {
+			for i := 0; i < len(runner.pieces); i++ {
+				if !runner.check(t, copyPieces(runner.pieces[:i]), true, "truncated at piece", i) {
+					return
+				}
+			}
+		}
+	})
+
+	if runner.hasFinal {
+		t.Run("dropped_finalization", func(t *testing.T) {
+			runner.check(t, copyPieces(runner.pieces[:len(runner.pieces)-1]), true, "dropped finalization piece")
+		})
+	}
+}
@@ -21,6 +21,10 @@ type EncSymmTester struct {
 
 	NotMarshalable bool
 	IsBlank        bool
+
+	// Sizes of chunks encrypted before ciphertext is attacked.
+	// Defaults to DefaultEncAttackChunkSizes.
+	AttackChunkSizes []int
 }
 
 /*
@@ -42,6 +46,9 @@ func (tester *EncSymmTester) init() {
 	if tester.TestScopeUtil.ChunkRunnerConfig.IsEmpty() {
 		tester.TestScopeUtil.ChunkRunnerConfig = DefaultEncChunkRunnerConfig
 	}
+	if len(tester.AttackChunkSizes) == 0 {
+		tester.AttackChunkSizes = DefaultEncAttackChunkSizes
+	}
 }
 
 func (tester *EncSymmTester) encryptAndDecryptStreamData(
@@ -66,11 +73,12 @@ func (tester *EncSymmTester) encryptAndDecryptChainData(chunks [][]byte, bag Enc
 
 func (tester *EncSymmTester) Test(t *testing.T) {
 	tester.init()
+	tester.TestScopeUtil.LogSeed(t)
 
 	if tester.Algo.GetInfo().EncType == crypka.EncTypeStream {
 		t.Run("enc_stream", func(t *testing.T) {
 			t.Run("valid_encryption", func(t *testing.T) {
-				scope := tester.TestScopeUtil.GetTestScope()
+				scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 				err := scope.GetChunkRunner().RunWithSameChunks(func(chunks [][]byte) (err error) {
 					ek, err := tester.Algo.GenerateKey(nil, scope.GetRNG())
@@ -91,7 +99,7 @@ func (tester *EncSymmTester) Test(t *testing.T) {
 
 			if !tester.IsBlank {
 				t.Run("invalid_when_key_mistmatch", func(t *testing.T) {
-					scope := tester.TestScopeUtil.GetTestScope()
+					scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 					err := scope.GetChunkRunner().RunWithSameChunks(func(chunks [][]byte) (err error) {
 						err = tester.encryptAndDecryptStreamData(chunks, nil, EncKeyBag{
@@ -117,7 +125,7 @@ func (tester *EncSymmTester) Test(t *testing.T) {
 	if tester.Algo.GetInfo().EncType == crypka.EncTypeChain {
 		t.Run("enc_chain", func(t *testing.T) {
 			t.Run("valid_encryption", func(t *testing.T) {
-				scope := tester.TestScopeUtil.GetTestScope()
+				scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 				err := scope.GetChunkRunner().RunWithSameChunks(func(chunks [][]byte) (err error) {
 					ek, err := tester.Algo.GenerateKey(nil, scope.GetRNG())
@@ -137,7 +145,7 @@ func (tester *EncSymmTester) Test(t *testing.T) {
 			})
 
 			t.Run("invalid_when_key_mistmatch", func(t *testing.T) {
-				scope := tester.TestScopeUtil.GetTestScope()
+				scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 				err := scope.GetChunkRunner().RunWithSameChunks(func(chunks [][]byte) (err error) {
 					err = tester.encryptAndDecryptChainData(chunks, EncKeyBag{
@@ -159,10 +167,35 @@ func (tester *EncSymmTester) Test(t *testing.T) {
 		})
 	}
 
+	// Attacks are run according to auth mode algorithm claims, so they are no-op for not authenticated ones.
+	info := tester.Algo.GetInfo()
+	if !info.AuthMode.IsZero() {
+		t.Run("attacks", func(t *testing.T) {
+			scope := tester.TestScopeUtil.GetTestScopeFor(t)
+
+			key, err := tester.Algo.GenerateKey(nil, scope.GetRNG())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			bag := EncKeyBag{
+				EncKey: key,
+				DecKey: key,
+			}
+
+			runner, err := newEncAttackRunner(info, bag, RNGReadBuffers(scope.GetRNG(), tester.AttackChunkSizes...))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			runner.Test(t)
+		})
+	}
+
 	if !tester.NotMarshalable {
 		if tester.Algo.GetInfo().EncType == crypka.EncTypeChain || tester.Algo.GetInfo().EncType == crypka.EncTypeBlock {
 			t.Run("can_marshal_symm_signing_key__chain_test", func(t *testing.T) {
-				scope := tester.TestScopeUtil.GetTestScope()
+				scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 				originalKey, err := tester.Algo.GenerateKey(nil, scope.GetRNG())
 				if err != nil {
@@ -205,7 +238,7 @@ func (tester *EncSymmTester) Test(t *testing.T) {
 			})
 		} else {
 			t.Run("can_marshal_symm_signing_key__stream_test", func(t *testing.T) {
-				scope := tester.TestScopeUtil.GetTestScope()
+				scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 				originalKey, err := tester.Algo.GenerateKey(nil, scope.GetRNG())
 				if err != nil {
@@ -0,0 +1,619 @@
+package crypkatest
+
+import (
+	"bytes"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+var ErrTestingKATMismatch = errors.New("crypka/crypkatest: result differs from known answer")
+
+// errKATUnsupported is returned, when vector uses parameters, which algorithm does not support, like AAD or other key size.
+var errKATUnsupported = errors.New("crypka/crypkatest: vector is not supported by algorithm")
+
+//go:embed vectors/*.json
+var katVectors embed.FS
+
+// LoadKATVectors returns contents of embedded vector file with given name, like "aes_gcm_test.json".
+func LoadKATVectors(name string) (data []byte, err error) {
+	return katVectors.ReadFile("vectors/" + name)
+}
+
+// katHex is byte slice encoded as hex string in JSON.
+type katHex []byte
+
+func (h *katHex) UnmarshalJSON(data []byte) (err error) {
+	var text string
+	err = json.Unmarshal(data, &text)
+	if err != nil {
+		return
+	}
+	*h, err = hex.DecodeString(text)
+	return
+}
+
+type katFile struct {
+	Algorithm  string     `json:"algorithm"`
+	TestGroups []katGroup `json:"testGroups"`
+}
+
+type katGroup struct {
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+	Key     struct {
+		PK katHex `json:"pk"`
+		SK katHex `json:"sk"`
+	} `json:"key"`
+	Tests []katTest `json:"tests"`
+}
+
+// katTest contains union of fields of all supported test types.
+type katTest struct {
+	TcID    int    `json:"tcId"`
+	Comment string `json:"comment"`
+	Result  string `json:"result"`
+
+	Key    katHex `json:"key"`
+	IV     katHex `json:"iv"`
+	AAD    katHex `json:"aad"`
+	Msg    katHex `json:"msg"`
+	CT     katHex `json:"ct"`
+	Tag    katHex `json:"tag"`
+	Sig    katHex `json:"sig"`
+	Digest katHex `json:"digest"`
+
+	Public  katHex `json:"public"`
+	Private katHex `json:"private"`
+	Shared  katHex `json:"shared"`
+
+	Seed   katHex `json:"seed"`
+	Output katHex `json:"output"`
+
+	Password katHex `json:"password"`
+	Hash     string `json:"hash"`
+
+	Variant     string `json:"variant"`
+	Salt        katHex `json:"salt"`
+	Secret      katHex `json:"secret"`
+	AD          katHex `json:"ad"`
+	Iterations  uint32 `json:"iterations"`
+	Memory      uint32 `json:"memory"`
+	Parallelism uint8  `json:"parallelism"`
+
+	IKM  katHex `json:"ikm"`
+	Info katHex `json:"info"`
+	Size int    `json:"size"`
+	OKM  katHex `json:"okm"`
+
+	Padded katHex `json:"padded"`
+}
+
+// KATTester runs known answer tests from Wycheproof style JSON file against algorithm.
+//
+// Supported test group types are:
+//   - AeadTest and KeywrapTest for symmetric encryption algorithms
+//   - DecryptTest for symmetric or asymmetric encryption algorithm, where key is marshaled decryption key
+//   - MacTest and HashTest for symmetric signing algorithms
+//   - EddsaVerify for Ed25519 like asymmetric signing algorithms
+//   - XdhComp for key exchange algorithms
+//   - RngTest for seedable RNG algorithms
+//   - PasswordHashTest for password hashers, where hash is PHC string
+//   - Argon2Test, which uses crypka.Argon2PasswordHash directly and ignores Algo
+//   - HkdfTest, which uses HKDF function and ignores Algo
+//   - PaddingTest for paddings, where randomized ones are checked with unpadding only
+//
+// Vectors, which use parameters not supported by algorithm, like AAD or other key size, are skipped.
+// At least one vector has to be run, otherwise test fails.
+type KATTester struct {
+	Algo    interface{}
+	Vectors []byte
+
+	// Required for HkdfTest groups only.
+	HKDF func(ikm, salt, info []byte, size int) (okm []byte, err error)
+}
+
+func (tester KATTester) Test(t *testing.T) {
+	var file katFile
+	err := json.Unmarshal(tester.Vectors, &file)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ranCount := 0
+	for _, group := range file.TestGroups {
+		run, err := tester.groupRunner(group)
+		if err != nil {
+			t.Error(file.Algorithm, group.Type, err)
+			return
+		}
+
+		for _, test := range group.Tests {
+			err := run(test)
+			if errors.Is(err, errKATUnsupported) {
+				continue
+			}
+			ranCount++
+
+			switch test.Result {
+			case "valid":
+				if err != nil {
+					t.Errorf("%s tcId %d (%s): %v", file.Algorithm, test.TcID, test.Comment, err)
+				}
+			case "invalid":
+				if err == nil {
+					t.Errorf("%s tcId %d (%s): invalid vector was accepted", file.Algorithm, test.TcID, test.Comment)
+				}
+			}
+		}
+	}
+
+	if ranCount == 0 {
+		t.Error(file.Algorithm, "no vectors were run")
+	}
+}
+
+func (tester KATTester) groupRunner(group katGroup) (run func(test katTest) error, err error) {
+	wrongAlgo := fmt.Errorf("crypka/crypkatest: %T can't run %s vectors", tester.Algo, group.Type)
+
+	switch group.Type {
+	case "AeadTest", "KeywrapTest":
+		algo, ok := tester.Algo.(crypka.EncSymmAlgo)
+		if !ok {
+			err = wrongAlgo
+			return
+		}
+		if group.Type == "AeadTest" {
+			run = func(test katTest) error { return runKATAead(algo, test) }
+		} else {
+			run = func(test katTest) error { return runKATKeywrap(algo, test) }
+		}
+	case "DecryptTest":
+		switch algo := tester.Algo.(type) {
+		case crypka.EncSymmAlgo:
+			run = func(test katTest) (err error) {
+				key, err := algo.ParseSymmEncKey(nil, test.Key)
+				if err != nil {
+					return
+				}
+				return checkKATDecrypt(key, test.CT, test.Msg)
+			}
+		case crypka.EncAsymAlgo:
+			run = func(test katTest) (err error) {
+				key, err := algo.ParseDecKey(nil, test.Key)
+				if err != nil {
+					return
+				}
+				return checkKATDecrypt(key, test.CT, test.Msg)
+			}
+		default:
+			err = wrongAlgo
+		}
+	case "MacTest", "HashTest":
+		algo, ok := tester.Algo.(crypka.SignSymmAlgo)
+		if !ok {
+			err = wrongAlgo
+			return
+		}
+		run = func(test katTest) error { return runKATSymmSign(algo, test) }
+	case "EddsaVerify":
+		algo, ok := tester.Algo.(crypka.SignAsymAlgo)
+		if !ok {
+			err = wrongAlgo
+			return
+		}
+		run = func(test katTest) error { return runKATEddsa(algo, group, test) }
+	case "XdhComp":
+		algo, ok := tester.Algo.(crypka.KXAlgo)
+		if !ok {
+			err = wrongAlgo
+			return
+		}
+		run = func(test katTest) error { return runKATXdh(algo, test) }
+	case "RngTest":
+		algo, ok := tester.Algo.(crypka.RNGAlgo)
+		if !ok {
+			err = wrongAlgo
+			return
+		}
+		run = func(test katTest) error { return runKATRng(algo, test) }
+	case "PasswordHashTest":
+		algo, ok := tester.Algo.(crypka.PHasher)
+		if !ok {
+			err = wrongAlgo
+			return
+		}
+		run = func(test katTest) error {
+			return algo.CheckPassword(nil, test.Password, []byte(test.Hash))
+		}
+	case "Argon2Test":
+		run = func(test katTest) error { return runKATArgon2(group, test) }
+	case "HkdfTest":
+		if tester.HKDF == nil {
+			err = errors.New("crypka/crypkatest: HKDF function is required for HkdfTest vectors")
+			return
+		}
+		run = func(test katTest) (err error) {
+			okm, err := tester.HKDF(test.IKM, test.Salt, test.Info, test.Size)
+			if err != nil {
+				return
+			}
+			return compareKAT(okm, test.OKM)
+		}
+	case "PaddingTest":
+		algo, ok := tester.Algo.(crypka.PaddingAlgo)
+		if !ok {
+			err = wrongAlgo
+			return
+		}
+		run = func(test katTest) error { return runKATPadding(algo, test) }
+	default:
+		err = fmt.Errorf("crypka/crypkatest: unsupported test group type %s", group.Type)
+	}
+	return
+}
+
+func compareKAT(res, expected []byte) (err error) {
+	if !bytes.Equal(res, expected) {
+		err = ErrTestingKATMismatch
+	}
+	return
+}
+
+func encryptKAT(key crypka.EncKey, ctx crypka.KeyContext, msg []byte) (res []byte, err error) {
+	enc, err := key.MakeEncryptor(ctx)
+	if err != nil {
+		return
+	}
+	res, err = enc.Encrypt(msg, nil)
+	if err != nil {
+		return
+	}
+	return enc.Finalize(res)
+}
+
+func checkKATDecrypt(key crypka.DecKey, ct, msg []byte) (err error) {
+	dec, err := key.MakeDecryptor(nil)
+	if err != nil {
+		return
+	}
+	res, err := dec.Decrypt(ct, nil)
+	if err != nil {
+		return
+	}
+	err = dec.Finalize()
+	if err != nil {
+		return
+	}
+	return compareKAT(res, msg)
+}
+
+// runKATAead runs AEAD vector against algorithm using crypka's AEAD framing.
+// Chain encryption uses counter nonce starting at zero, so only vectors with zero IV are supported.
+// Block encryption appends nonce taken from RNG to ciphertext, so RNG yielding IV is used to encrypt.
+func runKATAead(algo crypka.EncSymmAlgo, test katTest) (err error) {
+	if len(test.AAD) > 0 {
+		return errKATUnsupported
+	}
+
+	key, err := algo.ParseSymmEncKey(nil, test.Key)
+	if err != nil {
+		return errKATUnsupported
+	}
+
+	ct := append(append([]byte{}, test.CT...), test.Tag...)
+	var ctx *crypka.Context
+
+	switch algo.GetInfo().EncType {
+	case crypka.EncTypeChain:
+		if !bytes.Equal(test.IV, make([]byte, len(test.IV))) {
+			return errKATUnsupported
+		}
+	case crypka.EncTypeBlock:
+		ct = append(ct, test.IV...)
+		// nonce is read once on initialization and once after each chunk
+		ctx = &crypka.Context{
+			RNG: bytes.NewReader(bytes.Repeat(test.IV, 2)),
+		}
+	default:
+		return errKATUnsupported
+	}
+
+	if test.Result == "valid" {
+		var res []byte
+		res, err = encryptKAT(key, ctx, test.Msg)
+		if err != nil {
+			return
+		}
+		err = compareKAT(res, ct)
+		if err != nil {
+			return
+		}
+	}
+
+	return checkKATDecrypt(key, ct, test.Msg)
+}
+
+func runKATKeywrap(algo crypka.EncSymmAlgo, test katTest) (err error) {
+	key, err := algo.ParseSymmEncKey(nil, test.Key)
+	if err != nil {
+		return errKATUnsupported
+	}
+
+	if test.Result == "valid" {
+		var res []byte
+		res, err = encryptKAT(key, nil, test.Msg)
+		if err != nil {
+			return
+		}
+		err = compareKAT(res, test.CT)
+		if err != nil {
+			return
+		}
+	}
+
+	return checkKATDecrypt(key, test.CT, test.Msg)
+}
+
+func runKATSymmSign(algo crypka.SignSymmAlgo, test katTest) (err error) {
+	key, err := algo.ParseSymmSignKey(nil, test.Key)
+	if err != nil {
+		return errKATUnsupported
+	}
+
+	expected := test.Tag
+	if expected == nil {
+		expected = test.Digest
+	}
+
+	if test.Result == "valid" {
+		var signer crypka.Signer
+		signer, err = key.MakeSigner(nil)
+		if err != nil {
+			return
+		}
+		_, err = signer.Write(test.Msg)
+		if err != nil {
+			return
+		}
+
+		var res []byte
+		res, err = signer.Finalize(nil)
+		if err != nil {
+			return
+		}
+		err = compareKAT(res, expected)
+		if err != nil {
+			return
+		}
+	}
+
+	verifier, err := key.MakeVerifier(nil)
+	if err != nil {
+		return
+	}
+	_, err = verifier.Write(test.Msg)
+	if err != nil {
+		return
+	}
+	return verifier.Verify(expected)
+}
+
+// runKATEddsa parses signing key in form of seed followed by public key, like crypka's Ed25519 does.
+func runKATEddsa(algo crypka.SignAsymAlgo, group katGroup, test katTest) (err error) {
+	vk, err := algo.ParseVerifyingKey(nil, group.Key.PK)
+	if err != nil {
+		return errKATUnsupported
+	}
+
+	if test.Result == "valid" {
+		var sk crypka.SigningKey
+		sk, err = algo.ParseSigningKey(nil, append(append([]byte{}, group.Key.SK...), group.Key.PK...))
+		if err != nil {
+			return errKATUnsupported
+		}
+
+		var signer crypka.Signer
+		signer, err = sk.MakeSigner(nil)
+		if err != nil {
+			return
+		}
+		_, err = signer.Write(test.Msg)
+		if err != nil {
+			return
+		}
+
+		var res []byte
+		res, err = signer.Finalize(nil)
+		if err != nil {
+			return
+		}
+		err = compareKAT(res, test.Sig)
+		if err != nil {
+			return
+		}
+	}
+
+	verifier, err := vk.MakeVerifier(nil)
+	if err != nil {
+		return
+	}
+	_, err = verifier.Write(test.Msg)
+	if err != nil {
+		return
+	}
+	return verifier.Verify(test.Sig)
+}
+
+func runKATXdh(algo crypka.KXAlgo, test katTest) (err error) {
+	secret, err := algo.ParseKXSecret(nil, test.Private)
+	if err != nil {
+		return errKATUnsupported
+	}
+	public, err := algo.ParseKXPublic(nil, test.Public)
+	if err != nil {
+		return
+	}
+
+	res := make([]byte, len(test.Shared))
+	err = algo.PerformExchange(nil, public, secret, res)
+	if err != nil {
+		return
+	}
+	return compareKAT(res, test.Shared)
+}
+
+// runKATRng reads output in two parts, so RNG has to keep its state between reads.
+func runKATRng(algo crypka.RNGAlgo, test katTest) (err error) {
+	rng, err := algo.MakeRng(nil, test.Seed)
+	if err != nil {
+		return errKATUnsupported
+	}
+
+	res := make([]byte, len(test.Output))
+	split := len(res) / 3
+	_, err = io.ReadFull(rng, res[:split])
+	if err != nil {
+		return
+	}
+	_, err = io.ReadFull(rng, res[split:])
+	if err != nil {
+		return
+	}
+	return compareKAT(res, test.Output)
+}
+
+func runKATArgon2(group katGroup, test katTest) (err error) {
+	h := crypka.Argon2PasswordHash{
+		Name:    test.Variant,
+		Version: group.Version,
+		Salt:    test.Salt,
+		Data:    test.AD,
+		Time:    test.Iterations,
+		Memory:  test.Memory,
+		Threads: test.Parallelism,
+		Hash:    make([]byte, len(test.Tag)),
+	}
+
+	res, err := h.ComputeHash(test.Password, test.Secret)
+	if err != nil {
+		return
+	}
+	return compareKAT(res, test.Tag)
+}
+
+func runKATPadding(algo crypka.PaddingAlgo, test katTest) (err error) {
+	msg, err := algo.UnpadMessage(test.Padded)
+	if err != nil {
+		return
+	}
+	err = compareKAT(msg, test.Msg)
+	if err != nil {
+		return
+	}
+
+	if algo.GetInfo().IsRandomized {
+		return
+	}
+
+	padded, err := algo.PadMessage(nil, test.Msg, nil)
+	if err != nil {
+		return
+	}
+	return compareKAT(padded, test.Padded)
+}
+
+// BuiltinKATVectors maps names of algorithms registered by crypka.RegisterDefaults to embedded vector files, which cover them.
+var BuiltinKATVectors = map[string]string{
+	"sha-256":  "sha256_test.json",
+	"sha-512":  "sha512_test.json",
+	"sha3-256": "sha3_256_test.json",
+	"sha3-512": "sha3_512_test.json",
+
+	"hmac-sha-256":  "hmac_sha256_test.json",
+	"hmac-sha-512":  "hmac_sha512_test.json",
+	"hmac-sha3-256": "hmac_sha3_256_test.json",
+	"hmac-sha3-512": "hmac_sha3_512_test.json",
+
+	"ed25519-sha-256":  "ed25519_sha256_test.json",
+	"ed25519-sha-512":  "ed25519_sha512_test.json",
+	"ed25519-sha3-256": "ed25519_sha3_256_test.json",
+	"ed25519-sha3-512": "ed25519_sha3_512_test.json",
+
+	"aes-128-gcm-counter":       "aes_gcm_test.json",
+	"aes-128-gcm-rng":           "aes_gcm_test.json",
+	"aes-256-gcm-counter":       "aes_gcm_test.json",
+	"aes-256-gcm-rng":           "aes_gcm_test.json",
+	"chacha20-poly1305-counter": "chacha20_poly1305_test.json",
+	"chacha20-poly1305-rng":     "chacha20_poly1305_test.json",
+
+	"aes-128-kw":  "aes_kw_test.json",
+	"aes-256-kw":  "aes_kw_test.json",
+	"aes-128-kwp": "aes_kwp_test.json",
+	"aes-256-kwp": "aes_kwp_test.json",
+
+	"cpk-stream-aes-128-gcm-counter":       "cpk_stream_aes_128_gcm_counter_test.json",
+	"cpk-stream-aes-256-gcm-counter":       "cpk_stream_aes_256_gcm_counter_test.json",
+	"cpk-stream-chacha20-poly1305-counter": "cpk_stream_chacha20_poly1305_counter_test.json",
+
+	"x25519":                           "x25519_test.json",
+	"x25519-aes-128-gcm-counter":       "x25519_aes_128_gcm_counter_test.json",
+	"x25519-aes-256-gcm-counter":       "x25519_aes_256_gcm_counter_test.json",
+	"x25519-chacha20-poly1305-counter": "x25519_chacha20_poly1305_counter_test.json",
+
+	"chacha20-rng":    "chacha20_rng_test.json",
+	"aes-256-ctr-rng": "aes_256_ctr_rng_test.json",
+
+	"argon2id": "argon2id_phc_test.json",
+
+	"pad-iec78164":      "pad_iec78164_test.json",
+	"pad-pkcs7":         "pad_pkcs7_test.json",
+	"pad-padme":         "pad_padme_test.json",
+	"pad-random-bucket": "pad_random_bucket_test.json",
+}
+
+// BuiltinKATExempt contains names of algorithms registered by crypka.RegisterDefaults,
+// which are not deterministic, so they have no known answers, along with the reason.
+var BuiltinKATExempt = map[string]string{
+	"crypto-rng":  "reads from crypto/rand",
+	"pow-sha-256": "challenges depend on random secret and current time",
+}
+
+// BuiltinKATTester runs known answer tests of all algorithms from BuiltinKATVectors, which are present in registry.
+//
+// Registry defaults to global one.
+type BuiltinKATTester struct {
+	Registry crypka.Registry
+}
+
+func (tester BuiltinKATTester) Test(t *testing.T) {
+	reg := tester.Registry
+	if reg == nil {
+		reg = crypka.GlobalRegistry
+	}
+
+	for name, file := range BuiltinKATVectors {
+		algo := reg.GetAlgo(name)
+		if algo == nil {
+			continue
+		}
+
+		vectors, err := LoadKATVectors(file)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+
+		t.Run(name, KATTester{
+			Algo:    algo,
+			Vectors: vectors,
+		}.Test)
+	}
+}
@@ -25,9 +25,10 @@ func (tester *KXTester) init() {
 
 func (tester KXTester) Test(t *testing.T) {
 	tester.init()
+	tester.TestScopeUtil.LogSeed(t)
 
 	t.Run("kx_key_match", func(t *testing.T) {
-		scope := tester.GetTestScope()
+		scope := tester.GetTestScopeFor(t)
 
 		p1, s1, err := tester.Algo.GenerateKXPair(nil, scope.GetRNG())
 		if err != nil {
@@ -70,7 +71,7 @@ func (tester KXTester) Test(t *testing.T) {
 	})
 
 	t.Run("kx_key_differ", func(t *testing.T) {
-		scope := tester.GetTestScope()
+		scope := tester.GetTestScopeFor(t)
 
 		var res1 []byte
 		{
@@ -132,7 +133,7 @@ func (tester KXTester) Test(t *testing.T) {
 
 	if !tester.NotMarshalable {
 		t.Run("can_marshal_kx_public_secret_pair", func(t *testing.T) {
-			scope := tester.GetTestScope()
+			scope := tester.GetTestScopeFor(t)
 
 			p1, _, err := tester.Algo.GenerateKXPair(nil, scope.GetRNG())
 			if err != nil {
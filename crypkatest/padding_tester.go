@@ -0,0 +1,140 @@
+package crypkatest
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+var ErrTestingPaddingMismatch = errors.New("crypka/crypkatest: unpadded message is not equal to padded one")
+
+// Sizes of messages, which are padded in tests.
+// They include sizes around common block and bucket sizes.
+var DefaultPaddingMessageSizes = []int{0, 1, 2, 7, 8, 15, 16, 17, 31, 32, 33, 100, 255, 256, 257, 1000, 1024, 4097}
+
+type PaddingTester struct {
+	Algo crypka.PaddingAlgo
+	TestScopeUtil
+
+	// Defaults to DefaultPaddingMessageSizes.
+	MessageSizes []int
+}
+
+func (tester *PaddingTester) init() {
+	if len(tester.MessageSizes) == 0 {
+		tester.MessageSizes = DefaultPaddingMessageSizes
+	}
+}
+
+func (tester *PaddingTester) Test(t *testing.T) {
+	tester.init()
+	tester.TestScopeUtil.LogSeed(t)
+
+	t.Run("pad_unpad", func(t *testing.T) {
+		scope := tester.GetTestScope()
+
+		for _, sz := range tester.MessageSizes {
+			msg := RNGReadBuffer(scope.GetRNG(), sz)
+			padded, err := tester.Algo.PadMessage(scope.GetRNG(), msg, nil)
+			if err != nil {
+				t.Error(sz, err)
+				return
+			}
+			if len(padded) < len(msg) {
+				t.Error(sz, "padded message is shorter than message")
+				return
+			}
+
+			unpadded, err := tester.Algo.UnpadMessage(padded)
+			if err != nil {
+				t.Error(sz, err)
+				return
+			}
+			if !bytes.Equal(msg, unpadded) {
+				t.Error(sz, ErrTestingPaddingMismatch)
+				return
+			}
+		}
+	})
+
+	t.Run("pad_appends", func(t *testing.T) {
+		scope := tester.GetTestScope()
+
+		prefix := RNGReadBuffer(scope.GetRNG(), 13)
+		msg := RNGReadBuffer(scope.GetRNG(), 42)
+
+		padded, err := tester.Algo.PadMessage(scope.GetRNG(), msg, append([]byte{}, prefix...))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !bytes.Equal(padded[:len(prefix)], prefix) {
+			t.Error("buffer, which padded message was appended to, was modified")
+			return
+		}
+
+		unpadded, err := tester.Algo.UnpadMessage(padded[len(prefix):])
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !bytes.Equal(msg, unpadded) {
+			t.Error(ErrTestingPaddingMismatch)
+		}
+	})
+
+	if !tester.Algo.GetInfo().IsRandomized {
+		t.Run("pad_is_deterministic", func(t *testing.T) {
+			scope := tester.GetTestScope()
+
+			for _, sz := range tester.MessageSizes {
+				msg := RNGReadBuffer(scope.GetRNG(), sz)
+				lhs, err := tester.Algo.PadMessage(scope.GetRNG(), msg, nil)
+				if err != nil {
+					t.Error(sz, err)
+					return
+				}
+				rhs, err := tester.Algo.PadMessage(scope.GetRNG(), msg, nil)
+				if err != nil {
+					t.Error(sz, err)
+					return
+				}
+				if !bytes.Equal(lhs, rhs) {
+					t.Error(sz, "padding claims to be deterministic, but it's not")
+					return
+				}
+			}
+		})
+	}
+
+	// it's fine for modified padded message to be valid, as long as unpadding does not panic
+	t.Run("unpad_modified", func(t *testing.T) {
+		scope := tester.GetTestScope()
+
+		padded, err := tester.Algo.PadMessage(scope.GetRNG(), RNGReadBuffer(scope.GetRNG(), 33), nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		for i := 0; i <= len(padded); i++ {
+			unpadded, err := tester.Algo.UnpadMessage(padded[:i])
+			if err == nil && len(unpadded) > i {
+				t.Error("unpadded message is longer than padded one")
+				return
+			}
+		}
+
+		for i := range padded {
+			modified := append([]byte{}, padded...)
+			modified[i] ^= 0x80
+			unpadded, err := tester.Algo.UnpadMessage(modified)
+			if err == nil && len(unpadded) > len(modified) {
+				t.Error("unpadded message is longer than padded one")
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package crypkatest
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+type PHashFormatFuzzMethod int
+
+const (
+	PHashFormatFuzzTextRoundTrip   PHashFormatFuzzMethod = 1
+	PHashFormatFuzzBinaryRoundTrip PHashFormatFuzzMethod = 2
+)
+
+var ErrTestingPHashFormatMismatch = errors.New("crypka/crypkatest: password hash changed during conversion between text and binary format")
+
+// PHashFormatTester checks if conversion between PHC strings and BMCF is lossless.
+type PHashFormatTester struct {
+	Converter *crypka.PHashFormatConverter
+
+	// Valid hashes in PHC string format.
+	// They are used as test cases and as fuzzing seeds.
+	Samples [][]byte
+}
+
+func (tester *PHashFormatTester) init() {
+	if tester.Converter == nil {
+		tester.Converter = crypka.NewPHashFormatConverter()
+	}
+}
+
+// checkText converts valid text hash to binary and back.
+// Result must be canonical encoding of original hash and binary forms must be equal.
+func (tester *PHashFormatTester) checkText(text []byte) (err error) {
+	h, err := tester.Converter.LoadText(text)
+	if err != nil {
+		return
+	}
+
+	canonical, err := h.Raw()
+	if err != nil {
+		return
+	}
+
+	bin, err := tester.Converter.TextToBinary(text, nil)
+	if err != nil {
+		return
+	}
+
+	convertedText, err := tester.Converter.BinaryToText(bin, nil)
+	if err != nil {
+		return
+	}
+
+	if !bytes.Equal(canonical, convertedText) {
+		err = ErrTestingPHashFormatMismatch
+		return
+	}
+
+	convertedBin, err := tester.Converter.TextToBinary(convertedText, nil)
+	if err != nil {
+		return
+	}
+
+	if !bytes.Equal(bin, convertedBin) {
+		err = ErrTestingPHashFormatMismatch
+		return
+	}
+
+	return
+}
+
+// checkBinary converts valid binary hash to text and back.
+// Result must be exactly the same as input.
+func (tester *PHashFormatTester) checkBinary(bin []byte) (err error) {
+	text, err := tester.Converter.BinaryToText(bin, nil)
+	if err != nil {
+		return
+	}
+
+	convertedBin, err := tester.Converter.TextToBinary(text, nil)
+	if err != nil {
+		return
+	}
+
+	if !bytes.Equal(bin, convertedBin) {
+		err = ErrTestingPHashFormatMismatch
+		return
+	}
+
+	return
+}
+
+func (tester *PHashFormatTester) Test(t *testing.T) {
+	tester.init()
+
+	if len(tester.Samples) == 0 {
+		t.Error("no samples provided for testing password hash format")
+		return
+	}
+
+	t.Run("text_binary_text", func(t *testing.T) {
+		for _, sample := range tester.Samples {
+			err := tester.checkText(sample)
+			if err != nil {
+				t.Error(string(sample), err)
+			}
+		}
+	})
+
+	t.Run("binary_text_binary", func(t *testing.T) {
+		for _, sample := range tester.Samples {
+			bin, err := tester.Converter.TextToBinary(sample, nil)
+			if err != nil {
+				t.Error(string(sample), err)
+				continue
+			}
+
+			err = tester.checkBinary(bin)
+			if err != nil {
+				t.Error(string(sample), err)
+			}
+		}
+	})
+
+	t.Run("binary_is_not_larger", func(t *testing.T) {
+		for _, sample := range tester.Samples {
+			bin, err := tester.Converter.TextToBinary(sample, nil)
+			if err != nil {
+				t.Error(string(sample), err)
+				continue
+			}
+
+			if len(bin) > len(sample) {
+				t.Error("binary form is larger than text one", string(sample))
+			}
+		}
+	})
+}
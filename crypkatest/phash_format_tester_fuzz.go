@@ -0,0 +1,51 @@
+package crypkatest
+
+import (
+	"fmt"
+	"testing"
+)
+
+func (tester *PHashFormatTester) Fuzz(f *testing.F, method PHashFormatFuzzMethod) {
+	tester.init()
+
+	if method == PHashFormatFuzzTextRoundTrip {
+		for _, sample := range tester.Samples {
+			f.Add(sample)
+		}
+
+		f.Fuzz(func(t *testing.T, data []byte) {
+			_, err := tester.Converter.LoadText(data)
+			if err != nil {
+				return
+			}
+
+			err = tester.checkText(data)
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	} else if method == PHashFormatFuzzBinaryRoundTrip {
+		for _, sample := range tester.Samples {
+			bin, err := tester.Converter.TextToBinary(sample, nil)
+			if err != nil {
+				f.Error(err)
+				return
+			}
+			f.Add(bin)
+		}
+
+		f.Fuzz(func(t *testing.T, data []byte) {
+			_, err := tester.Converter.LoadBinary(data)
+			if err != nil {
+				return
+			}
+
+			err = tester.checkBinary(data)
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	} else {
+		panic(fmt.Errorf("invalid password hash format fuzz method: %d", method))
+	}
+}
@@ -0,0 +1,131 @@
+package crypkatest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/teawithsand/crypka"
+)
+
+type PoWTester struct {
+	Algo crypka.PoWAlgo
+
+	// Difficulty used in tests. Defaults to 8.
+	// It should be low, so tests are fast.
+	Difficulty uint8
+}
+
+func (tester *PoWTester) init() {
+	if tester.Difficulty == 0 {
+		tester.Difficulty = 8
+	}
+}
+
+func (tester *PoWTester) Test(t *testing.T) {
+	tester.init()
+
+	t.Run("valid_solution", func(t *testing.T) {
+		challenge, err := tester.Algo.IssueChallenge(nil, tester.Difficulty, time.Hour)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		solution, err := tester.Algo.SolveChallenge(nil, challenge)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		err = tester.Algo.VerifySolution(nil, challenge, solution)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("invalid_solution", func(t *testing.T) {
+		challenge, err := tester.Algo.IssueChallenge(nil, tester.Difficulty, time.Hour)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		solution, err := tester.Algo.SolveChallenge(nil, challenge)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		// some other solution may be valid as well, so look for first invalid one
+		for i := uint64(1); ; i++ {
+			err = tester.Algo.VerifySolution(nil, challenge, solution+i)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, crypka.ErrPoWSolutionInvalid) {
+				t.Error(err)
+			}
+			return
+		}
+	})
+
+	t.Run("expired_challenge", func(t *testing.T) {
+		challenge, err := tester.Algo.IssueChallenge(nil, tester.Difficulty, -time.Hour)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		solution, err := tester.Algo.SolveChallenge(nil, challenge)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		err = tester.Algo.VerifySolution(nil, challenge, solution)
+		if !errors.Is(err, crypka.ErrPoWChallengeExpired) {
+			t.Error("expected expired error, got", err)
+			return
+		}
+	})
+
+	t.Run("can_marshal_challenge", func(t *testing.T) {
+		challenge, err := tester.Algo.IssueChallenge(nil, tester.Difficulty, time.Hour)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		data, err := crypka.MarshalPoWChallenge(challenge)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		parsed, err := crypka.ParsePoWChallenge(data)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if !reflect.DeepEqual(challenge, parsed) {
+			t.Error("parsed challenge differs from marshaled one")
+			return
+		}
+
+		solution, err := tester.Algo.SolveChallenge(nil, parsed)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		err = tester.Algo.VerifySolution(nil, challenge, solution)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+}
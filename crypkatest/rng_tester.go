@@ -13,7 +13,7 @@ import (
 type zeroRNG struct{}
 
 func (r *zeroRNG) Read(buf []byte) (sz int, err error) {
-	for _, i := range buf {
+	for i := range buf {
 		buf[i] = 0
 	}
 	sz = len(buf)
@@ -52,9 +52,10 @@ func (tester *RNGTester) init() {
 
 func (tester RNGTester) Test(t *testing.T) {
 	tester.init()
+	tester.TestScopeUtil.LogSeed(t)
 
 	t.Run("rng_works_simple", func(t *testing.T) {
-		scope := tester.GetTestScope()
+		scope := tester.GetTestScopeFor(t)
 
 		seed, err := crypka.GenerateReasonableRNGSeed(scope.GetRNG(), tester.Algo.GetInfo())
 		if err != nil {
@@ -95,7 +96,7 @@ func (tester RNGTester) Test(t *testing.T) {
 
 	if tester.Algo.GetInfo().RNGType == crypka.SeedRNGType {
 		t.Run("same_seed_gives_same_data", func(t *testing.T) {
-			scope := tester.GetTestScope()
+			scope := tester.GetTestScopeFor(t)
 
 			seed, err := crypka.GenerateReasonableRNGSeed(scope.GetRNG(), tester.Algo.GetInfo())
 			if err != nil {
@@ -142,7 +143,7 @@ func (tester RNGTester) Test(t *testing.T) {
 
 		// TODO(teawithsand): make this test into fuzzer
 		t.Run("same_seed_different_read_sizes_give_same_data", func(t *testing.T) {
-			scope := tester.GetTestScope()
+			scope := tester.GetTestScopeFor(t)
 
 			seed, err := crypka.GenerateReasonableRNGSeed(scope.GetRNG(), tester.Algo.GetInfo())
 			if err != nil {
@@ -246,7 +247,7 @@ func (tester *RNGTester) Benchmark(b *testing.B) {
 	rngReadTest := func(sz int) func(b *testing.B) {
 		return func(b *testing.B) {
 
-			scope := tester.GetTestScope()
+			scope := tester.GetTestScopeFor(b)
 			buf := make([]byte, sz)
 			_, err := io.ReadFull(scope.GetRNG(), buf)
 			if err != nil {
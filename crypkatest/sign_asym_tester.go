@@ -30,11 +30,12 @@ func (tester *SignAsymTester) signAndVerifyData(signerChunks [][]byte, verifierC
 
 func (tester *SignAsymTester) Test(t *testing.T) {
 	tester.init()
+	tester.TestScopeUtil.LogSeed(t)
 
 	// TODO(teawithsand): implement more tests here
 
 	t.Run("valid_sign", func(t *testing.T) {
-		scope := tester.TestScopeUtil.GetTestScope()
+		scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 		err := scope.GetChunkRunner().RunWithSameChunks(func(chunks [][]byte) (err error) {
 			sk, vk, err := tester.Algo.GenerateKeyPair(nil, scope.GetRNG())
@@ -55,7 +56,7 @@ func (tester *SignAsymTester) Test(t *testing.T) {
 
 	t.Run("invalid_sign", func(t *testing.T) {
 		t.Run("when_data_mismatch", func(t *testing.T) {
-			scope := tester.TestScopeUtil.GetTestScope()
+			scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 			err := scope.GetChunkRunner().runWithDifferentChunks(func(lhs, rhs [][]byte) (err error) {
 				sk, vk, err := tester.Algo.GenerateKeyPair(nil, scope.GetRNG())
@@ -82,7 +83,7 @@ func (tester *SignAsymTester) Test(t *testing.T) {
 		})
 
 		t.Run("when_key_mismatch", func(t *testing.T) {
-			scope := tester.TestScopeUtil.GetTestScope()
+			scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 			err := scope.GetChunkRunner().runWithDifferentChunks(func(lhs, rhs [][]byte) (err error) {
 				err = tester.signAndVerifyData(lhs, rhs, SignKeyBag{BaseBag: scope.GetBaseBag()})
@@ -103,7 +104,7 @@ func (tester *SignAsymTester) Test(t *testing.T) {
 
 	if !tester.NotMarshalable {
 		t.Run("can_marshal_signing_key", func(t *testing.T) {
-			scope := tester.TestScopeUtil.GetTestScope()
+			scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 			sk, vk, err := tester.Algo.GenerateKeyPair(nil, scope.GetRNG())
 			if err != nil {
@@ -136,7 +137,7 @@ func (tester *SignAsymTester) Test(t *testing.T) {
 		})
 
 		t.Run("can_marshal_verifying_key", func(t *testing.T) {
-			scope := tester.TestScopeUtil.GetTestScope()
+			scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 			sk, vk, err := tester.Algo.GenerateKeyPair(nil, scope.GetRNG())
 			if err != nil {
@@ -31,11 +31,12 @@ func (tester *SignSymmTester) signAndVerifyData(signerChunks [][]byte, verifierC
 
 func (tester *SignSymmTester) Test(t *testing.T) {
 	tester.init()
+	tester.TestScopeUtil.LogSeed(t)
 
 	// TODO(teawithsand): implement more tests here
 
 	t.Run("valid_sign", func(t *testing.T) {
-		scope := tester.TestScopeUtil.GetTestScope()
+		scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 		err := scope.GetChunkRunner().RunWithSameChunks(func(chunks [][]byte) (err error) {
 			key, err := tester.Algo.GenerateKey(nil, scope.GetRNG())
@@ -57,7 +58,7 @@ func (tester *SignSymmTester) Test(t *testing.T) {
 
 	t.Run("invalid_sign", func(t *testing.T) {
 		t.Run("when_data_mismatch", func(t *testing.T) {
-			scope := tester.TestScopeUtil.GetTestScope()
+			scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 			err := scope.GetChunkRunner().runWithDifferentChunks(func(lhs, rhs [][]byte) (err error) {
 				key, err := tester.Algo.GenerateKey(nil, scope.GetRNG())
@@ -85,7 +86,7 @@ func (tester *SignSymmTester) Test(t *testing.T) {
 
 		if tester.Algo.GetInfo().Type != crypka.HashAlgorithmType {
 			t.Run("when_key_mistmatch", func(t *testing.T) {
-				scope := tester.TestScopeUtil.GetTestScope()
+				scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 				scope.GetChunkRunner().RunWithSameChunks(func(chunks [][]byte) (err error) {
 					err = tester.signAndVerifyData(chunks, chunks, SignKeyBag{
@@ -105,7 +106,7 @@ func (tester *SignSymmTester) Test(t *testing.T) {
 
 	if !tester.NotMarshalable {
 		t.Run("can_marshal_symm_signing_key", func(t *testing.T) {
-			scope := tester.TestScopeUtil.GetTestScope()
+			scope := tester.TestScopeUtil.GetTestScopeFor(t)
 
 			key, err := tester.Algo.GenerateKey(nil, scope.GetRNG())
 			if err != nil {
@@ -0,0 +1,220 @@
+package crypkatest
+
+import (
+	"errors"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/teawithsand/crypka"
+)
+
+var ErrTestingTimingLeak = errors.New("crypka/crypkatest: execution time depends on class of input")
+
+// Value of Welch's t statistic, above which dudect considers code to be definitely not constant time.
+const DefaultTimingThreshold = 10
+
+// Name of environment variable, which has to be set to 1 in order to run timing tests.
+const TimingEnvVar = "CRYPKA_TIMING"
+
+// SkipUnlessTimingEnabled skips test if timing tests are not enabled with TimingEnvVar or if tests run in short mode.
+func SkipUnlessTimingEnabled(t testing.TB) {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("timing test is skipped in short mode")
+	}
+	if os.Getenv(TimingEnvVar) != "1" {
+		t.Skipf("timing test is skipped, set %s=1 to run it", TimingEnvVar)
+	}
+}
+
+// TimingTester checks if execution time of function depends on its input, like dudect does.
+//
+// Inputs are divided into two classes, for instance signatures differing from valid one in last byte and random ones.
+// Both classes are measured in random order and Welch's t-test is run on measurements.
+// If it shows that there is difference between classes, then function leaks information via timing.
+//
+// Classes should differ only in secret data. For instance valid and invalid signatures may be verified in different time,
+// since result of verification is not secret anyway.
+//
+// Results depend on machine and its load, so it's run only if TimingEnvVar is set to 1.
+type TimingTester struct {
+	// Prepare returns input of given class, which is either 0 or 1.
+	// Inputs are prepared before measurements, so Prepare is not measured.
+	Prepare func(class int, rng crypka.RNG) (input []byte, err error)
+
+	// Run is measured function.
+	Run func(input []byte)
+
+	// Amount of measurements taken. Defaults to 100000.
+	Measurements int
+
+	// Amount of calls to Run in single measurement.
+	// Making it greater helps with functions, which are faster than timer resolution. Defaults to 1.
+	Batch int
+
+	// Max absolute value of t statistic, which is considered to be fine. Defaults to DefaultTimingThreshold.
+	Threshold float64
+
+	TestScopeUtil
+}
+
+func (tester *TimingTester) init() {
+	if tester.Measurements == 0 {
+		tester.Measurements = 100000
+	}
+	if tester.Batch == 0 {
+		tester.Batch = 1
+	}
+	if tester.Threshold == 0 {
+		tester.Threshold = DefaultTimingThreshold
+	}
+}
+
+// welchAccumulator computes mean and variance of measurements online, using Welford's method.
+type welchAccumulator struct {
+	n    [2]float64
+	mean [2]float64
+	m2   [2]float64
+}
+
+func (acc *welchAccumulator) push(class int, x float64) {
+	acc.n[class]++
+	delta := x - acc.mean[class]
+	acc.mean[class] += delta / acc.n[class]
+	acc.m2[class] += delta * (x - acc.mean[class])
+}
+
+func (acc *welchAccumulator) t() float64 {
+	if acc.n[0] < 2 || acc.n[1] < 2 {
+		return 0
+	}
+
+	v0 := acc.m2[0] / (acc.n[0] - 1)
+	v1 := acc.m2[1] / (acc.n[1] - 1)
+	den := math.Sqrt(v0/acc.n[0] + v1/acc.n[1])
+	if den == 0 {
+		return 0
+	}
+	return (acc.mean[0] - acc.mean[1]) / den
+}
+
+// TimingResult is result of timing test.
+type TimingResult struct {
+	// Greatest absolute value of t statistic among all tests.
+	T float64
+
+	// Amount of measurements, which were used for each class.
+	Measurements [2]int
+
+	// Mean time of single Run call for each class.
+	Mean [2]time.Duration
+}
+
+// Measure takes measurements and computes t statistic.
+//
+// Like in dudect t-test is run on all measurements and on measurements cropped at few percentiles,
+// since long measurements are mostly noise caused by scheduler, GC or interrupts.
+// Greatest absolute value of t is reported.
+func (tester *TimingTester) Measure() (res TimingResult, err error) {
+	tester.init()
+
+	rng := tester.GetTestScope().GetRNG()
+
+	classes := RNGReadBuffer(rng, tester.Measurements)
+	inputs := make([][]byte, tester.Measurements)
+	for i := range inputs {
+		classes[i] &= 1
+		inputs[i], err = tester.Prepare(int(classes[i]), rng)
+		if err != nil {
+			return
+		}
+	}
+
+	// inputs are copied to single buffer with same stride, so their placement in memory does not depend on class,
+	// which otherwise would show up as difference in cache misses
+	stride := 0
+	for _, input := range inputs {
+		if len(input) > stride {
+			stride = len(input)
+		}
+	}
+	arena := make([]byte, stride*len(inputs))
+	for i, input := range inputs {
+		n := copy(arena[i*stride:], input)
+		inputs[i] = arena[i*stride : i*stride+n : (i+1)*stride]
+	}
+
+	// warm up caches and branch predictors, so first measurements are not off
+	for i := 0; i < len(inputs) && i < 1000; i++ {
+		tester.Run(inputs[i])
+	}
+
+	// GC pauses are noise, which is not related to measured function
+	runtime.GC()
+
+	durations := make([]float64, tester.Measurements)
+	for i, input := range inputs {
+		start := time.Now()
+		for j := 0; j < tester.Batch; j++ {
+			tester.Run(input)
+		}
+		durations[i] = float64(time.Since(start))
+	}
+
+	sorted := append([]float64{}, durations...)
+	sort.Float64s(sorted)
+
+	var thresholds []float64
+	thresholds = append(thresholds, math.Inf(1))
+	for i := 0; i < 10; i++ {
+		p := 1 - math.Pow(0.5, float64(i+1))
+		thresholds = append(thresholds, sorted[int(p*float64(len(sorted)-1))])
+	}
+
+	for i, threshold := range thresholds {
+		var acc welchAccumulator
+		for j, d := range durations {
+			if d <= threshold {
+				acc.push(int(classes[j]), d)
+			}
+		}
+
+		if i == 0 {
+			for class := 0; class < 2; class++ {
+				res.Measurements[class] = int(acc.n[class])
+				res.Mean[class] = time.Duration(acc.mean[class] / float64(tester.Batch))
+			}
+		}
+
+		if t := math.Abs(acc.t()); t > res.T {
+			res.T = t
+		}
+	}
+	return
+}
+
+func (tester *TimingTester) Test(t *testing.T) {
+	tester.init()
+	tester.TestScopeUtil.LogSeed(t)
+
+	SkipUnlessTimingEnabled(t)
+
+	res, err := tester.Measure()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Logf(
+		"t = %.2f, class 0: %d measurements with mean %s, class 1: %d measurements with mean %s",
+		res.T, res.Measurements[0], res.Mean[0], res.Measurements[1], res.Mean[1],
+	)
+	if res.T > tester.Threshold {
+		t.Error(ErrTestingTimingLeak, "t =", res.T, "threshold =", tester.Threshold)
+	}
+}
@@ -0,0 +1,24 @@
+package crypkatest
+
+import (
+	"runtime"
+	"testing"
+)
+
+// CheckAllocBound runs f and fails test if it allocated more than limit bytes in total.
+//
+// Allocations made by other goroutines in the meantime are counted as well, so limit should leave some slack.
+// It's meant for fuzz targets, which should never allocate amount of memory, which is not bounded by size of their input.
+func CheckAllocBound(t testing.TB, limit uint64, f func()) {
+	t.Helper()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+
+	allocated := after.TotalAlloc - before.TotalAlloc
+	if allocated > limit {
+		t.Errorf("allocated %d bytes, which is more than limit of %d bytes", allocated, limit)
+	}
+}
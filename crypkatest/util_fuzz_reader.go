@@ -19,7 +19,7 @@ func FuzzingChunks(input []byte, receiver func(data []byte) (err error)) (err er
 		}
 		sz := int(rawSz % maxChunkSize)
 
-		input = input[:rd.Len()]
+		input = input[len(input)-rd.Len():]
 
 		if sz > len(input) {
 			sz = len(input)
@@ -36,6 +36,17 @@ func FuzzingChunks(input []byte, receiver func(data []byte) (err error)) (err er
 	return nil
 }
 
+// FuzzingChunksSeed encodes chunks in format, which is read by FuzzingChunks, so they can be used as fuzzing seed.
+func FuzzingChunksSeed(chunks ...[]byte) (res []byte) {
+	var buf [binary.MaxVarintLen64]byte
+	for _, chunk := range chunks {
+		sz := binary.PutUvarint(buf[:], uint64(len(chunk)))
+		res = append(res, buf[:sz]...)
+		res = append(res, chunk...)
+	}
+	return
+}
+
 // This function always yields n variable-sized chunks.
 // It's valid for those chunks to be zero-sized.
 // All chunks are parts of input. No copying is done.
@@ -64,7 +75,7 @@ func FuzzingNChunks(input []byte, n int, receiver func(data []byte) (err error))
 		}
 		sz := int(rawSz % maxChunkSize)
 
-		input = input[:rd.Len()]
+		input = input[len(input)-rd.Len():]
 
 		if sz > len(input) {
 			sz = len(input)
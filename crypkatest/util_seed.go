@@ -0,0 +1,122 @@
+package crypkatest
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+	"golang.org/x/crypto/chacha20"
+)
+
+// Name of flag and environment variable, which set seed of default test RNG, so failed run can be replayed.
+// Flag takes precedence over environment variable.
+const (
+	SeedFlagName = "crypka.seed"
+	SeedEnvVar   = "CRYPKA_SEED"
+)
+
+var ErrTestingInvalidSeed = errors.New("crypka/crypkatest: invalid test seed, it must be unsigned 64 bit integer")
+
+var seedFlag = flag.String(SeedFlagName, "", "seed of crypkatest test RNG, random one is used if empty")
+
+var testSeed struct {
+	once sync.Once
+	seed uint64
+	err  error
+}
+
+// GetTestSeed returns seed of default test RNG.
+// It's taken from -crypka.seed flag or CRYPKA_SEED environment variable. If none is set, random one is used.
+//
+// Seed is chosen once per process, so all testers share it.
+func GetTestSeed() (seed uint64, err error) {
+	testSeed.once.Do(func() {
+		raw := *seedFlag
+		if raw == "" {
+			raw = os.Getenv(SeedEnvVar)
+		}
+
+		if raw == "" {
+			var buf [8]byte
+			_, testSeed.err = io.ReadFull(rand.Reader, buf[:])
+			testSeed.seed = binary.LittleEndian.Uint64(buf[:])
+			return
+		}
+
+		testSeed.seed, testSeed.err = strconv.ParseUint(raw, 0, 64)
+		if testSeed.err != nil {
+			testSeed.err = ErrTestingInvalidSeed
+		}
+	})
+
+	seed = testSeed.seed
+	err = testSeed.err
+	return
+}
+
+var seededRNGAlgo = &crypka.EncStreamRNGAlgo{
+	CipherFactory: func(key []byte) (res cipher.Stream, err error) {
+		nonce := make([]byte, chacha20.NonceSize)
+		return chacha20.NewUnauthenticatedCipher(key, nonce)
+	},
+	KeyLength:               chacha20.KeySize,
+	ResedKeyLength:          chacha20.KeySize,
+	CipherMaxGeneratedBytes: 1 << 32,
+}
+
+// NewSeededRNG returns deterministic RNG, which yields same data for same seed.
+// It's ChaCha20 based EncStreamRNGAlgo, so it's fine for tests, but seed is way too short for anything else.
+func NewSeededRNG(seed uint64) crypka.RNG {
+	key := make([]byte, chacha20.KeySize)
+	binary.LittleEndian.PutUint64(key, seed)
+
+	return newSeededRNGWithKey(key)
+}
+
+// NewSeededTestRNG is like NewSeededRNG, but stream it returns is derived from both seed and test name.
+// This way each test gets its own stream, which does not depend on which other tests were run.
+func NewSeededTestRNG(seed uint64, name string) crypka.RNG {
+	h := sha256.New()
+
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], seed)
+	_, _ = h.Write(buf[:])
+	_, _ = h.Write([]byte(name))
+
+	return newSeededRNGWithKey(h.Sum(nil))
+}
+
+func newSeededRNGWithKey(key []byte) crypka.RNG {
+	rng, err := seededRNGAlgo.MakeRng(nil, key)
+	if err != nil {
+		panic(err)
+	}
+	return rng
+}
+
+// LogSeed logs seed of default test RNG along with a way of replaying test with it.
+// It fails test if seed set by user is invalid.
+//
+// Nothing is logged if TestRNGFactory is set, since seed is not used then.
+func (tsu *TestScopeUtil) LogSeed(t testing.TB) {
+	t.Helper()
+
+	if tsu != nil && tsu.TestRNGFactory != nil {
+		return
+	}
+
+	seed, err := GetTestSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("crypkatest seed is %d, replay with -%s=%d or %s=%d", seed, SeedFlagName, seed, SeedEnvVar, seed)
+}
@@ -1,7 +1,9 @@
 package crypkatest
 
 import (
-	"crypto/rand"
+	"strconv"
+	"sync/atomic"
+	"testing"
 
 	"github.com/teawithsand/crypka"
 )
@@ -15,36 +17,66 @@ var defaultTestScopeUtil = &TestScopeUtil{}
 
 type TestScopeUtil struct {
 	ChunkRunnerConfig ChunkRunnerConfig
-	TestRNGFactory    func() crypka.RNG
+
+	// Factory of RNG used in tests.
+	// If nil, then RNG seeded with GetTestSeed is used, so tests are reproducible.
+	TestRNGFactory func() crypka.RNG
+}
+
+// counter of scopes created without test, so each of them gets its own stream
+var untitledScopeCounter uint64
+
+func (tsu *TestScopeUtil) makeRNG(name string) crypka.RNG {
+	if tsu.TestRNGFactory != nil {
+		return tsu.TestRNGFactory()
+	}
+
+	seed, err := GetTestSeed()
+	if err != nil {
+		panic(err)
+	}
+	return NewSeededTestRNG(seed, name)
+}
+
+func (tsu *TestScopeUtil) makeUntitledRNG() crypka.RNG {
+	n := atomic.AddUint64(&untitledScopeCounter, 1)
+	return tsu.makeRNG("#" + strconv.FormatUint(n, 10))
 }
 
 // Note: use test scope instead in any newer implementation.
 // Consider this function deprecated at the moment of it's creation.
+//
+// Each call yields different stream. It's reproducible only if calls are made in same order.
 func (tsu *TestScopeUtil) GetTestRNG() crypka.RNG {
 	if tsu == nil {
 		tsu = defaultTestScopeUtil
 	}
 
-	if tsu.TestRNGFactory == nil {
-		return rand.Reader
-	}
-	return tsu.TestRNGFactory()
+	return tsu.makeUntitledRNG()
 }
 
+// GetTestScope returns test scope with RNG, which yields different stream on each call.
+// It's reproducible only if calls are made in same order, so GetTestScopeFor should be preferred.
 func (tsu *TestScopeUtil) GetTestScope() *TestScope {
 	if tsu == nil {
 		tsu = defaultTestScopeUtil
 	}
 
-	var rng crypka.RNG
-	if tsu.TestRNGFactory == nil {
-		rng = rand.Reader
-	} else {
-		rng = tsu.TestRNGFactory()
+	return &TestScope{
+		InnerRNG:          tsu.makeUntitledRNG(),
+		ChunkRunnerConfig: tsu.ChunkRunnerConfig,
+	}
+}
+
+// GetTestScopeFor returns test scope with RNG derived from test seed and name of given test.
+// Same test gets same stream regardless of which other tests were run, so it can be replayed with -run.
+func (tsu *TestScopeUtil) GetTestScopeFor(t testing.TB) *TestScope {
+	if tsu == nil {
+		tsu = defaultTestScopeUtil
 	}
 
 	return &TestScope{
-		InnerRNG:          rng,
+		InnerRNG:          tsu.makeRNG(t.Name()),
 		ChunkRunnerConfig: tsu.ChunkRunnerConfig,
 	}
 }
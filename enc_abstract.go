@@ -41,6 +41,13 @@ const (
 type EncInfo struct {
 	RequiresFinalization bool
 	EncType              EncType
+
+	// If true, each encryptor uses random nonce or key, so many encryptors may be created from single key
+	// and encrypting same data twice yields different ciphertexts.
+	//
+	// Otherwise, encryptors made from single key yield same keystream(for instance they use nonce counter starting at zero),
+	// so such key must not be used to create more than one encryptor.
+	IsRandomized bool
 }
 
 // Note: I've omitted decryption finalization, which requires last chunk(yielded by finalize)
@@ -12,10 +12,23 @@ type AEADSymmEncAlgo struct {
 	NonceConfig NonceConfig
 
 	AEADFactory func(key []byte) (aead cipher.AEAD, err error)
+
+	// Name of algorithm, which keys are tagged with, when they are marshaled to text or JSON.
+	// Registry sets it to name algorithm is registered with.
+	AlgoName string
+}
+
+func (algo *AEADSymmEncAlgo) getAlgoName() string {
+	return algo.AlgoName
+}
+
+func (algo *AEADSymmEncAlgo) setAlgoName(name string) {
+	algo.AlgoName = name
 }
 
 func (algo *AEADSymmEncAlgo) makeKey(ctx AnyContext, data []byte) (key *aeadSymmEncKey, err error) {
 	key = &aeadSymmEncKey{
+		algo:            algo,
 		key:             data,
 		aeadFactory:     algo.AEADFactory,
 		nonceConfig:     algo.NonceConfig,
@@ -25,10 +38,16 @@ func (algo *AEADSymmEncAlgo) makeKey(ctx AnyContext, data []byte) (key *aeadSymm
 }
 
 func (algo *AEADSymmEncAlgo) GenerateKey(ctx KeyGenerationContext, rng RNG) (key EncSymmKey, err error) {
-	data := make([]byte, algo.KeyLength)
+	err = contextCheckPolicy(ctx, KeyGenerationPolicyOperation, algo, algo.KeyLength)
+	if err != nil {
+		return
+	}
+
+	data := allocKeyMaterial(ctx, algo.KeyLength)
 	rng = FallbackContextGetRNG(ctx, rng)
 	_, err = io.ReadFull(rng, data)
 	if err != nil {
+		destroyKeyMaterial(data)
 		return
 	}
 
@@ -60,6 +79,7 @@ func (algo *AEADSymmEncAlgo) GetInfo() EncAlgoInfo {
 		EncInfo: EncInfo{
 			RequiresFinalization: false,
 			EncType:              encType,
+			IsRandomized:         algo.NonceConfig.NonceType == RNGNonce,
 		},
 		AuthMode: eam,
 	}
@@ -71,25 +91,48 @@ func (algo *AEADSymmEncAlgo) ParseSymmEncKey(ctx KeyParseContext, data []byte) (
 		return
 	}
 
-	keyCopy := make([]byte, len(data))
-	copy(keyCopy, data)
+	err = contextCheckPolicy(ctx, KeyParsePolicyOperation, algo, len(data))
+	if err != nil {
+		return
+	}
+
+	keyCopy := copyKeyMaterial(ctx, data)
 
 	key, err = algo.makeKey(ctx, keyCopy)
 	return
 }
 
 type aeadSymmEncKey struct {
+	secretKeyMarshalGuard
+
+	algo            *AEADSymmEncAlgo
 	key             []byte
 	nonceConfig     NonceConfig
 	algoNonceLength int
 	aeadFactory     func(key []byte) (aead cipher.AEAD, err error)
+	destroyed       bool
 }
 
 func (key *aeadSymmEncKey) MarshalToWriter(w io.Writer) (err error) {
+	if key.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
 	_, err = w.Write(key.key)
 	return
 }
 
+func (key *aeadSymmEncKey) toSerializedKey() (SerializedKey, error) {
+	return makeSerializedKey(key.algo.AlgoName, SecretSerializedKeyType, key)
+}
+
+func (key *aeadSymmEncKey) Destroy() {
+	destroyKeyMaterial(key.key)
+	key.key = nil
+	key.destroyed = true
+}
+
 func (key *aeadSymmEncKey) makeNonceManager(ctx KeyContext, aeadLength int) (nonceManager NonceManager, embedNonce bool, err error) {
 	embedNonce = key.nonceConfig.NonceType == RNGNonce
 	nonceManager, err = key.nonceConfig.MakeNonceManager(ctx, aeadLength)
@@ -105,6 +148,16 @@ func (key *aeadSymmEncKey) makeNonceManager(ctx KeyContext, aeadLength int) (non
 }
 
 func (key *aeadSymmEncKey) MakeEncryptor(ctx KeyContext) (enc Encryptor, err error) {
+	if key.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	err = contextCheckPolicy(ctx, MakeEncryptorPolicyOperation, key.algo, len(key.key))
+	if err != nil {
+		return
+	}
+
 	aead, err := key.aeadFactory(key.key)
 	if err != nil {
 		return
@@ -133,6 +186,16 @@ type aeadEncryptor struct {
 }
 
 func (key *aeadSymmEncKey) MakeDecryptor(ctx KeyContext) (dec Decryptor, err error) {
+	if key.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	err = contextCheckPolicy(ctx, MakeDecryptorPolicyOperation, key.algo, len(key.key))
+	if err != nil {
+		return
+	}
+
 	aead, err := key.aeadFactory(key.key)
 	if err != nil {
 		return
@@ -160,13 +223,14 @@ func (key *aeadSymmEncKey) MakeDecryptor(ctx KeyContext) (dec Decryptor, err err
 func (enc *aeadEncryptor) GetEncInfo() EncInfo {
 	var ty EncType
 	if enc.embedNonce {
-		ty = EncTypeChain
-	} else {
 		ty = EncTypeBlock
+	} else {
+		ty = EncTypeChain
 	}
 	return EncInfo{
 		RequiresFinalization: false,
 		EncType:              ty,
+		IsRandomized:         enc.embedNonce,
 	}
 }
 
@@ -205,13 +269,14 @@ type aeadDecryptor struct {
 func (enc *aeadDecryptor) GetEncInfo() EncInfo {
 	var ty EncType
 	if enc.nonceManager == nil {
-		ty = EncTypeChain
-	} else {
 		ty = EncTypeBlock
+	} else {
+		ty = EncTypeChain
 	}
 	return EncInfo{
 		RequiresFinalization: false,
 		EncType:              ty,
+		IsRandomized:         enc.nonceManager == nil,
 	}
 }
 
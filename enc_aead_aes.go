@@ -21,7 +21,11 @@ func aesAeadFactory(key []byte) (aead cipher.AEAD, err error) {
 
 // Registers AES128GCM ciphers with nonce coutner and rng
 func RegisterAES128GCM(reg Registry) {
-	reg.RegisterAlgo("aes-128-gcm-counter", &AEADSymmEncAlgo{
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	registerDefault(reg, "aes-128-gcm-counter", &AEADSymmEncAlgo{
 		KeyLength:   128 / 8,
 		NonceLength: 12,
 		NonceConfig: NonceConfig{
@@ -29,7 +33,7 @@ func RegisterAES128GCM(reg Registry) {
 		},
 		AEADFactory: aesAeadFactory,
 	})
-	reg.RegisterAlgo("aes-128-gcm-rng", &AEADSymmEncAlgo{
+	registerDefault(reg, "aes-128-gcm-rng", &AEADSymmEncAlgo{
 		KeyLength:   128 / 8,
 		NonceLength: 12,
 		NonceConfig: NonceConfig{
@@ -38,3 +42,27 @@ func RegisterAES128GCM(reg Registry) {
 		AEADFactory: aesAeadFactory,
 	})
 }
+
+// Registers AES256GCM ciphers with nonce coutner and rng
+func RegisterAES256GCM(reg Registry) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	registerDefault(reg, "aes-256-gcm-counter", &AEADSymmEncAlgo{
+		KeyLength:   256 / 8,
+		NonceLength: 12,
+		NonceConfig: NonceConfig{
+			NonceType: CounterNonce,
+		},
+		AEADFactory: aesAeadFactory,
+	})
+	registerDefault(reg, "aes-256-gcm-rng", &AEADSymmEncAlgo{
+		KeyLength:   256 / 8,
+		NonceLength: 12,
+		NonceConfig: NonceConfig{
+			NonceType: RNGNonce,
+		},
+		AEADFactory: aesAeadFactory,
+	})
+}
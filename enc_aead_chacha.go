@@ -0,0 +1,35 @@
+package crypka
+
+import (
+	"crypto/cipher"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+func chacha20Poly1305AeadFactory(key []byte) (aead cipher.AEAD, err error) {
+	return chacha20poly1305.New(key)
+}
+
+// Registers ChaCha20-Poly1305 ciphers with nonce coutner and rng
+func RegisterChaCha20Poly1305(reg Registry) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	registerDefault(reg, "chacha20-poly1305-counter", &AEADSymmEncAlgo{
+		KeyLength:   chacha20poly1305.KeySize,
+		NonceLength: chacha20poly1305.NonceSize,
+		NonceConfig: NonceConfig{
+			NonceType: CounterNonce,
+		},
+		AEADFactory: chacha20Poly1305AeadFactory,
+	})
+	registerDefault(reg, "chacha20-poly1305-rng", &AEADSymmEncAlgo{
+		KeyLength:   chacha20poly1305.KeySize,
+		NonceLength: chacha20poly1305.NonceSize,
+		NonceConfig: NonceConfig{
+			NonceType: RNGNonce,
+		},
+		AEADFactory: chacha20Poly1305AeadFactory,
+	})
+}
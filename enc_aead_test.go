@@ -0,0 +1,54 @@
+package crypka_test
+
+import (
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+// Encryptors with counter nonces depend on previous chunks, while ones, which embed random nonces, do not.
+func TestEnc_AEAD_EncType(t *testing.T) {
+	reg := crypka.NewRegistry()
+	crypka.RegisterAES128GCM(reg)
+
+	for _, tc := range []struct {
+		name    string
+		encType crypka.EncType
+	}{
+		{"aes-128-gcm-counter", crypka.EncTypeChain},
+		{"aes-128-gcm-rng", crypka.EncTypeBlock},
+	} {
+		var algo crypka.EncSymmAlgo
+		err := reg.GetAlgorithmTyped(tc.name, &algo)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		key, err := algo.GenerateKey(nil, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		enc, err := key.MakeEncryptor(nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		dec, err := key.MakeDecryptor(nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if algo.GetInfo().EncType != tc.encType {
+			t.Error(tc.name, "invalid algorithm enc type", algo.GetInfo().EncType)
+		}
+		if enc.GetEncInfo().EncType != tc.encType {
+			t.Error(tc.name, "invalid encryptor enc type", enc.GetEncInfo().EncType)
+		}
+		if dec.GetEncInfo().EncType != tc.encType {
+			t.Error(tc.name, "invalid decryptor enc type", dec.GetEncInfo().EncType)
+		}
+	}
+}
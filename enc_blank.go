@@ -9,7 +9,14 @@ type BlankEncSymmAlgo struct {
 }
 
 func (algo *BlankEncSymmAlgo) GenerateKey(ctx KeyGenerationContext, rng RNG) (sk EncSymmKey, err error) {
-	sk = &blankEncSymmKey{}
+	err = contextCheckPolicy(ctx, KeyGenerationPolicyOperation, algo, 0)
+	if err != nil {
+		return
+	}
+
+	sk = &blankEncSymmKey{
+		algo: algo,
+	}
 
 	return
 }
@@ -33,11 +40,19 @@ func (algo *BlankEncSymmAlgo) ParseSymmEncKey(ctx KeyParseContext, data []byte)
 		err = ErrKeyParseField
 		return
 	}
-	ek = &blankEncSymmKey{}
+	err = contextCheckPolicy(ctx, KeyParsePolicyOperation, algo, 0)
+	if err != nil {
+		return
+	}
+
+	ek = &blankEncSymmKey{
+		algo: algo,
+	}
 	return
 }
 
 type blankEncSymmKey struct {
+	algo *BlankEncSymmAlgo
 }
 
 func (k *blankEncSymmKey) MarshalToWriter(w io.Writer) (err error) {
@@ -45,10 +60,20 @@ func (k *blankEncSymmKey) MarshalToWriter(w io.Writer) (err error) {
 }
 
 func (k *blankEncSymmKey) MakeEncryptor(ctx KeyContext) (enc Encryptor, err error) {
+	err = contextCheckPolicy(ctx, MakeEncryptorPolicyOperation, k.algo, 0)
+	if err != nil {
+		return
+	}
+
 	enc = &blankSymmEncryptor{}
 	return
 }
 func (k *blankEncSymmKey) MakeDecryptor(ctx KeyContext) (dec Decryptor, err error) {
+	err = contextCheckPolicy(ctx, MakeDecryptorPolicyOperation, k.algo, 0)
+	if err != nil {
+		return
+	}
+
 	dec = &blankSymmDecryptor{}
 	return
 }
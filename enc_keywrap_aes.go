@@ -0,0 +1,394 @@
+package crypka
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"io"
+)
+
+const aesKWBlockSize = 8
+
+var aesKWDefaultIV = [aesKWBlockSize]byte{0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6}
+var aesKWPAIVPrefix = [4]byte{0xA6, 0x59, 0x59, 0xA6}
+
+// AESKWSymmEncAlgo implements AES key wrap as described in RFC 3394 or,
+// if Padded is set, AES key wrap with padding as described in RFC 5649.
+//
+// It's block encryption, where each chunk is wrapped separately.
+// Wrapping is deterministic, so it should be used only for encrypting high entropy data like other keys.
+// See WrapKey.
+//
+// Note: unpadded variant is able to encrypt only chunks, which length is multiple of 8 and at least 16 bytes.
+// Padded one is able to encrypt any non-empty chunk.
+type AESKWSymmEncAlgo struct {
+	KeyLength int
+	Padded    bool
+
+	// Name of algorithm, which keys are tagged with, when they are marshaled to text or JSON.
+	// Registry sets it to name algorithm is registered with.
+	AlgoName string
+}
+
+func (algo *AESKWSymmEncAlgo) getAlgoName() string {
+	return algo.AlgoName
+}
+
+func (algo *AESKWSymmEncAlgo) setAlgoName(name string) {
+	algo.AlgoName = name
+}
+
+func (algo *AESKWSymmEncAlgo) GetInfo() EncAlgoInfo {
+	eam := NotAuthenticatedEncAuthMode
+
+	eam.SetEagerAuthenticated(true)
+	eam.SetFinalizeAuthetnicated(true)
+	eam.SetTruncAuthenticated(false)
+
+	return EncAlgoInfo{
+		BaseAlgorithmInfo: BaseAlgorithmInfo{
+			Type:     SymmEncAlgorithmType,
+			IsSecure: true,
+		},
+		EncInfo: EncInfo{
+			RequiresFinalization: false,
+			EncType:              EncTypeBlock,
+		},
+		AuthMode: eam,
+	}
+}
+
+func (algo *AESKWSymmEncAlgo) makeKey(data []byte) (key *aesKWKey, err error) {
+	block, err := aes.NewCipher(data)
+	if err != nil {
+		destroyKeyMaterial(data)
+		err = ErrKeyParseField
+		return
+	}
+
+	key = &aesKWKey{
+		algo:   algo,
+		key:    data,
+		block:  block,
+		padded: algo.Padded,
+	}
+	return
+}
+
+func (algo *AESKWSymmEncAlgo) GenerateKey(ctx KeyGenerationContext, rng RNG) (key EncSymmKey, err error) {
+	err = contextCheckPolicy(ctx, KeyGenerationPolicyOperation, algo, algo.KeyLength)
+	if err != nil {
+		return
+	}
+
+	data := allocKeyMaterial(ctx, algo.KeyLength)
+	rng = FallbackContextGetRNG(ctx, rng)
+	_, err = io.ReadFull(rng, data)
+	if err != nil {
+		destroyKeyMaterial(data)
+		return
+	}
+
+	key, err = algo.makeKey(data)
+	return
+}
+
+func (algo *AESKWSymmEncAlgo) ParseSymmEncKey(ctx KeyParseContext, data []byte) (key EncSymmKey, err error) {
+	if len(data) != algo.KeyLength {
+		err = ErrKeyParseField
+		return
+	}
+
+	err = contextCheckPolicy(ctx, KeyParsePolicyOperation, algo, len(data))
+	if err != nil {
+		return
+	}
+
+	keyCopy := copyKeyMaterial(ctx, data)
+
+	key, err = algo.makeKey(keyCopy)
+	return
+}
+
+type aesKWKey struct {
+	secretKeyMarshalGuard
+
+	algo      *AESKWSymmEncAlgo
+	key       []byte
+	block     cipher.Block
+	padded    bool
+	destroyed bool
+}
+
+func (key *aesKWKey) MarshalToWriter(w io.Writer) (err error) {
+	if key.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	_, err = w.Write(key.key)
+	return
+}
+
+func (key *aesKWKey) toSerializedKey() (SerializedKey, error) {
+	return makeSerializedKey(key.algo.AlgoName, SecretSerializedKeyType, key)
+}
+
+// Destroy wipes raw key.
+// Note: AES key schedule is managed by go's STL and can't be wiped, it's only dropped.
+func (key *aesKWKey) Destroy() {
+	destroyKeyMaterial(key.key)
+	key.key = nil
+	key.block = nil
+	key.destroyed = true
+}
+
+func (key *aesKWKey) MakeEncryptor(ctx KeyContext) (enc Encryptor, err error) {
+	if key.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	err = contextCheckPolicy(ctx, MakeEncryptorPolicyOperation, key.algo, len(key.key))
+	if err != nil {
+		return
+	}
+
+	enc = &aesKWEncryptor{
+		block:  key.block,
+		padded: key.padded,
+	}
+	return
+}
+
+func (key *aesKWKey) MakeDecryptor(ctx KeyContext) (dec Decryptor, err error) {
+	if key.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	err = contextCheckPolicy(ctx, MakeDecryptorPolicyOperation, key.algo, len(key.key))
+	if err != nil {
+		return
+	}
+
+	dec = &aesKWDecryptor{
+		block:  key.block,
+		padded: key.padded,
+	}
+	return
+}
+
+// aesKWWrap implements W function from RFC 3394.
+// Wrapped data is appended to res.
+func aesKWWrap(block cipher.Block, iv [aesKWBlockSize]byte, in, res []byte) []byte {
+	n := len(in) / aesKWBlockSize
+
+	offset := len(res)
+	res = append(res, iv[:]...)
+	res = append(res, in...)
+
+	a := res[offset : offset+aesKWBlockSize]
+	r := res[offset+aesKWBlockSize:]
+
+	var b [aes.BlockSize]byte
+	for j := 0; j <= 5; j++ {
+		for i := 0; i < n; i++ {
+			copy(b[:aesKWBlockSize], a)
+			copy(b[aesKWBlockSize:], r[i*aesKWBlockSize:(i+1)*aesKWBlockSize])
+			block.Encrypt(b[:], b[:])
+
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(b[:aesKWBlockSize])^t)
+			copy(r[i*aesKWBlockSize:], b[aesKWBlockSize:])
+		}
+	}
+
+	return res
+}
+
+// aesKWUnwrap implements W^-1 function from RFC 3394.
+// It returns integrity check value, which has to be verified by caller.
+// Unwrapped data is appended to res.
+func aesKWUnwrap(block cipher.Block, in, res []byte) (iv [aesKWBlockSize]byte, _ []byte) {
+	n := len(in)/aesKWBlockSize - 1
+
+	offset := len(res)
+	res = append(res, in[aesKWBlockSize:]...)
+	r := res[offset:]
+
+	a := binary.BigEndian.Uint64(in[:aesKWBlockSize])
+
+	var b [aes.BlockSize]byte
+	for j := 5; j >= 0; j-- {
+		for i := n - 1; i >= 0; i-- {
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(b[:aesKWBlockSize], a^t)
+			copy(b[aesKWBlockSize:], r[i*aesKWBlockSize:(i+1)*aesKWBlockSize])
+			block.Decrypt(b[:], b[:])
+
+			a = binary.BigEndian.Uint64(b[:aesKWBlockSize])
+			copy(r[i*aesKWBlockSize:], b[aesKWBlockSize:])
+		}
+	}
+
+	binary.BigEndian.PutUint64(iv[:], a)
+	return iv, res
+}
+
+type aesKWEncryptor struct {
+	block  cipher.Block
+	padded bool
+}
+
+func (enc *aesKWEncryptor) GetEncInfo() EncInfo {
+	return EncInfo{
+		RequiresFinalization: false,
+		EncType:              EncTypeBlock,
+	}
+}
+
+func (enc *aesKWEncryptor) Encrypt(in, appendTo []byte) (res []byte, err error) {
+	res = appendTo
+
+	if !enc.padded {
+		if len(in) < 2*aesKWBlockSize || len(in)%aesKWBlockSize != 0 {
+			err = ErrEncInvalidInputLength
+			return
+		}
+
+		res = aesKWWrap(enc.block, aesKWDefaultIV, in, res)
+		return
+	}
+
+	if len(in) == 0 || uint64(len(in)) > (1<<32)-1 {
+		err = ErrEncInvalidInputLength
+		return
+	}
+
+	var aiv [aesKWBlockSize]byte
+	copy(aiv[:4], aesKWPAIVPrefix[:])
+	binary.BigEndian.PutUint32(aiv[4:], uint32(len(in)))
+
+	paddedLength := (len(in) + aesKWBlockSize - 1) / aesKWBlockSize * aesKWBlockSize
+	padded := make([]byte, paddedLength)
+	copy(padded, in)
+
+	if paddedLength == aesKWBlockSize {
+		var b [aes.BlockSize]byte
+		copy(b[:aesKWBlockSize], aiv[:])
+		copy(b[aesKWBlockSize:], padded)
+		enc.block.Encrypt(b[:], b[:])
+
+		res = append(res, b[:]...)
+		return
+	}
+
+	res = aesKWWrap(enc.block, aiv, padded, res)
+	return
+}
+
+func (enc *aesKWEncryptor) Finalize(appendTo []byte) (res []byte, err error) {
+	res = appendTo
+	return
+}
+
+type aesKWDecryptor struct {
+	block  cipher.Block
+	padded bool
+}
+
+func (dec *aesKWDecryptor) GetEncInfo() EncInfo {
+	return EncInfo{
+		RequiresFinalization: false,
+		EncType:              EncTypeBlock,
+	}
+}
+
+func (dec *aesKWDecryptor) Decrypt(in, appendTo []byte) (res []byte, err error) {
+	res = appendTo
+
+	if !dec.padded {
+		if len(in) < 3*aesKWBlockSize || len(in)%aesKWBlockSize != 0 {
+			err = ErrEncAuthFiled
+			return
+		}
+
+		var iv [aesKWBlockSize]byte
+		iv, res = aesKWUnwrap(dec.block, in, res)
+		if subtle.ConstantTimeCompare(iv[:], aesKWDefaultIV[:]) != 1 {
+			res = appendTo
+			err = ErrEncAuthFiled
+			return
+		}
+		return
+	}
+
+	if len(in) < 2*aesKWBlockSize || len(in)%aesKWBlockSize != 0 {
+		err = ErrEncAuthFiled
+		return
+	}
+
+	var aiv [aesKWBlockSize]byte
+	if len(in) == 2*aesKWBlockSize {
+		var b [aes.BlockSize]byte
+		dec.block.Decrypt(b[:], in)
+
+		copy(aiv[:], b[:aesKWBlockSize])
+		res = append(res, b[aesKWBlockSize:]...)
+	} else {
+		aiv, res = aesKWUnwrap(dec.block, in, res)
+	}
+
+	padded := res[len(appendTo):]
+	length := int(binary.BigEndian.Uint32(aiv[4:]))
+
+	ok := subtle.ConstantTimeCompare(aiv[:4], aesKWPAIVPrefix[:])
+	ok &= subtle.ConstantTimeLessOrEq(len(padded)-aesKWBlockSize+1, length)
+	ok &= subtle.ConstantTimeLessOrEq(length, len(padded))
+	if ok != 1 {
+		res = appendTo
+		err = ErrEncAuthFiled
+		return
+	}
+
+	var nonZero byte
+	for _, b := range padded[length:] {
+		nonZero |= b
+	}
+	if nonZero != 0 {
+		res = appendTo
+		err = ErrEncAuthFiled
+		return
+	}
+
+	res = res[:len(appendTo)+length]
+	return
+}
+
+func (dec *aesKWDecryptor) Finalize() (err error) {
+	return
+}
+
+// Registers AES key wrap algorithms from RFC 3394(aes-*-kw) and RFC 5649(aes-*-kwp) with 128 and 256 bit keys.
+func RegisterAESKW(reg Registry) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	registerDefault(reg, "aes-128-kw", &AESKWSymmEncAlgo{
+		KeyLength: 128 / 8,
+	})
+	registerDefault(reg, "aes-256-kw", &AESKWSymmEncAlgo{
+		KeyLength: 256 / 8,
+	})
+	registerDefault(reg, "aes-128-kwp", &AESKWSymmEncAlgo{
+		KeyLength: 128 / 8,
+		Padded:    true,
+	})
+	registerDefault(reg, "aes-256-kwp", &AESKWSymmEncAlgo{
+		KeyLength: 256 / 8,
+		Padded:    true,
+	})
+}
@@ -0,0 +1,139 @@
+package crypka_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+func TestEnc_AESKW_CanRegister(t *testing.T) {
+	reg := crypka.NewRegistry()
+
+	crypka.RegisterAESKW(reg)
+}
+
+func TestEnc_AESKW_Vectors(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		padded     bool
+		kek        string
+		plaintext  string
+		ciphertext string
+	}{
+		// RFC 3394 section 4.1
+		{
+			name:       "rfc3394_128_kek_128_key",
+			kek:        "000102030405060708090A0B0C0D0E0F",
+			plaintext:  "00112233445566778899AABBCCDDEEFF",
+			ciphertext: "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5",
+		},
+		// RFC 3394 section 4.6
+		{
+			name:       "rfc3394_256_kek_256_key",
+			kek:        "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+			plaintext:  "00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F",
+			ciphertext: "28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21",
+		},
+		// RFC 5649 section 6
+		{
+			name:       "rfc5649_20_bytes",
+			padded:     true,
+			kek:        "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8",
+			plaintext:  "c37b7e6492584340bed12207808941155068f738",
+			ciphertext: "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a",
+		},
+		{
+			name:       "rfc5649_7_bytes",
+			padded:     true,
+			kek:        "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8",
+			plaintext:  "466f7250617369",
+			ciphertext: "afbeb0f07dfbf5419200f2ccb50bb24f",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			kek, _ := hex.DecodeString(tc.kek)
+			plaintext, _ := hex.DecodeString(tc.plaintext)
+			ciphertext, _ := hex.DecodeString(tc.ciphertext)
+
+			algo := &crypka.AESKWSymmEncAlgo{
+				KeyLength: len(kek),
+				Padded:    tc.padded,
+			}
+			key, err := algo.ParseSymmEncKey(nil, kek)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			enc, err := key.MakeEncryptor(nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res, err := enc.Encrypt(plaintext, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !bytes.Equal(res, ciphertext) {
+				t.Errorf("invalid ciphertext: %x", res)
+				return
+			}
+
+			dec, err := key.MakeDecryptor(nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res, err = dec.Decrypt(ciphertext, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !bytes.Equal(res, plaintext) {
+				t.Errorf("invalid plaintext: %x", res)
+				return
+			}
+
+			for i := range ciphertext {
+				modified := append([]byte{}, ciphertext...)
+				modified[i] ^= 1
+
+				_, err = dec.Decrypt(modified, nil)
+				if !errors.Is(err, crypka.ErrEncAuthFiled) {
+					t.Error("expected auth error, got", err)
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestEnc_AESKW_InvalidLength(t *testing.T) {
+	algo := &crypka.AESKWSymmEncAlgo{
+		KeyLength: 16,
+	}
+	key, err := algo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	enc, err := key.MakeEncryptor(nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, length := range []int{0, 8, 17} {
+		_, err = enc.Encrypt(make([]byte, length), nil)
+		if !errors.Is(err, crypka.ErrEncInvalidInputLength) {
+			t.Error("expected invalid length error, got", err)
+			return
+		}
+	}
+}
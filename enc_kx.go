@@ -1,6 +1,13 @@
 package crypka
 
-import "io"
+import (
+	"crypto"
+	"fmt"
+	"io"
+
+	// Required by RegisterEncAsymKX
+	_ "crypto/sha512"
+)
 
 // TODO(teawithsand): make this algorithm stream-capable if underlying algo is stream capable as well.
 
@@ -29,6 +36,10 @@ type EncAsymKXAlgo struct {
 	// One from context used if nil.
 	EphemeralRNG RNG
 
+	// Name of algorithm, which keys are tagged with, when they are marshaled to text or JSON.
+	// Registry sets it to name algorithm is registered with.
+	AlgoName string
+
 	// For now block mode is NIY
 	//
 	// Makes encryptor behave like block one.
@@ -36,10 +47,20 @@ type EncAsymKXAlgo struct {
 	// BlockMode bool
 }
 
+func (algo *EncAsymKXAlgo) getAlgoName() string {
+	return algo.AlgoName
+}
+
+func (algo *EncAsymKXAlgo) setAlgoName(name string) {
+	algo.AlgoName = name
+}
+
 func (algo *EncAsymKXAlgo) GetInfo() EncAlgoInfo {
 	info := algo.EncSymmAlgo.GetInfo()
 	kxInfo := algo.KXAlgo.GetInfo()
 
+	info.Type = AsymEncAlgorithmType
+
 	info.IsSecure = info.IsSecure && kxInfo.IsSecure
 	if algo.RNGAlgo != nil {
 		info.IsSecure = info.IsSecure && algo.RNGAlgo.GetInfo().IsSecure
@@ -55,11 +76,19 @@ func (algo *EncAsymKXAlgo) GetInfo() EncAlgoInfo {
 		info.EncType = EncTypeChain
 	}
 
+	// each encryptor generates new ephemeral key, so symmetric key is never reused
+	info.IsRandomized = true
+
 	return info
 }
 
 func (algo *EncAsymKXAlgo) GenerateKeyPair(ctx KeyGenerationContext, rng RNG) (ek EncKey, dk DecKey, err error) {
-	public, secret, err := algo.KXAlgo.GenerateKXPair(ctx, rng)
+	err = contextCheckPolicy(ctx, KeyGenerationPolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
+	public, secret, err := algo.KXAlgo.GenerateKXPair(contextForInnerAlgorithm(ctx), rng)
 	if err != nil {
 		return
 	}
@@ -76,7 +105,12 @@ func (algo *EncAsymKXAlgo) GenerateKeyPair(ctx KeyGenerationContext, rng RNG) (e
 }
 
 func (algo *EncAsymKXAlgo) ParseEncKey(ctx KeyParseContext, data []byte) (ek EncKey, err error) {
-	kxPublic, err := algo.KXAlgo.ParseKXPublic(ctx, data)
+	err = contextCheckPolicy(ctx, KeyParsePolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
+	kxPublic, err := algo.KXAlgo.ParseKXPublic(contextForInnerAlgorithm(ctx), data)
 	if err != nil {
 		return
 	}
@@ -89,7 +123,12 @@ func (algo *EncAsymKXAlgo) ParseEncKey(ctx KeyParseContext, data []byte) (ek Enc
 }
 
 func (algo *EncAsymKXAlgo) ParseDecKey(ctx KeyParseContext, data []byte) (dk DecKey, err error) {
-	kxSecret, err := algo.KXAlgo.ParseKXSecret(ctx, data)
+	err = contextCheckPolicy(ctx, KeyParsePolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
+	kxSecret, err := algo.KXAlgo.ParseKXSecret(contextForInnerAlgorithm(ctx), data)
 	if err != nil {
 		return
 	}
@@ -110,29 +149,141 @@ func (ek *encAsymKXAlgoEncKey) MarshalToWriter(w io.Writer) (err error) {
 	return MarshalKey(ek.public, w)
 }
 
+func (ek *encAsymKXAlgoEncKey) toSerializedKey() (SerializedKey, error) {
+	return makeSerializedKey(ek.algo.AlgoName, PublicSerializedKeyType, ek)
+}
+
+func (ek *encAsymKXAlgoEncKey) MarshalText() (res []byte, err error) {
+	sk, err := ek.toSerializedKey()
+	if err != nil {
+		return
+	}
+	return sk.MarshalText()
+}
+
+func (ek *encAsymKXAlgoEncKey) MarshalJSON() (res []byte, err error) {
+	sk, err := ek.toSerializedKey()
+	if err != nil {
+		return
+	}
+	return sk.MarshalJSON()
+}
+
 func (ek *encAsymKXAlgoEncKey) MakeEncryptor(ctx KeyContext) (enc Encryptor, err error) {
+	err = contextCheckPolicy(ctx, MakeEncryptorPolicyOperation, ek.algo, -1)
+	if err != nil {
+		return
+	}
+
 	enc = &encKxEncryptor{
 		algo:   ek.algo,
 		public: ek.public,
-		ctx:    ctx,
+		ctx:    contextForInnerAlgorithm(ctx),
 	}
 	return
 }
 
 type encAsymKXAlgoDecKey struct {
-	secret KXSecret
-	algo   *EncAsymKXAlgo
+	secretKeyMarshalGuard
+
+	secret    KXSecret
+	algo      *EncAsymKXAlgo
+	destroyed bool
 }
 
 func (ek *encAsymKXAlgoDecKey) MarshalToWriter(w io.Writer) (err error) {
+	if ek.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
 	return MarshalKey(ek.secret, w)
 }
 
+func (ek *encAsymKXAlgoDecKey) toSerializedKey() (SerializedKey, error) {
+	return makeSerializedKey(ek.algo.AlgoName, SecretSerializedKeyType, ek)
+}
+
+// Destroy destroys KX secret, if it's destroyable.
+func (ek *encAsymKXAlgoDecKey) Destroy() {
+	DestroyKey(ek.secret)
+	ek.destroyed = true
+}
+
+// DerivePublicKey returns EncKey matching this key.
+// It works only if KX secret implements PublicKeyDeriver.
+func (ek *encAsymKXAlgoDecKey) DerivePublicKey(ctx KeyContext) (key interface{}, err error) {
+	if ek.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	public, err := DeriveKXPublic(ctx, ek.secret)
+	if err != nil {
+		return
+	}
+
+	key = &encAsymKXAlgoEncKey{
+		algo:   ek.algo,
+		public: public,
+	}
+	return
+}
+
 func (ek *encAsymKXAlgoDecKey) MakeDecryptor(ctx KeyContext) (dec Decryptor, err error) {
+	if ek.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	err = contextCheckPolicy(ctx, MakeDecryptorPolicyOperation, ek.algo, -1)
+	if err != nil {
+		return
+	}
+
 	dec = &encKxDecryptor{
 		algo:   ek.algo,
 		secret: ek.secret,
-		ctx:    ctx,
+		ctx:    contextForInnerAlgorithm(ctx),
 	}
 	return
 }
+
+// Registers EncAsymKXAlgo, which combines already registered KX and symmetric encryption algorithms, as "<kx name>-<enc name>".
+// Result of KX is hashed with SHA-512 and expanded with ChaCha20 RNG before it's used as symmetric key.
+// Panics if any of algorithms is not registered.
+func RegisterEncAsymKX(reg Registry, kxName, encName string) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	var kxAlgo KXAlgo
+	err := reg.GetAlgorithmTyped(kxName, &kxAlgo)
+	if err != nil {
+		panic(fmt.Errorf("crypka: can't register asymmetric encryption with kx %s: %w", kxName, err))
+	}
+
+	var encAlgo EncSymmAlgo
+	err = reg.GetAlgorithmTyped(encName, &encAlgo)
+	if err != nil {
+		panic(fmt.Errorf("crypka: can't register asymmetric encryption with enc %s: %w", encName, err))
+	}
+
+	kxResultLength := kxAlgo.GetInfo().MaxResLen
+	if kxResultLength == 0 {
+		kxResultLength = fallbackKXRNGAlgoSeedSize
+	}
+
+	registerDefault(reg, kxName+"-"+encName, &EncAsymKXAlgo{
+		KXAlgo:         kxAlgo,
+		EncSymmAlgo:    encAlgo,
+		KXResultLength: kxResultLength,
+		RNGAlgo: &HashCompressRNGAlgo{
+			Compressor: &hashKey{
+				hash: crypto.SHA512,
+			},
+			InnerAlgo:     newChaCha20RNGAlgo(),
+			MinSeedLength: kxResultLength,
+		},
+	})
+}
@@ -19,6 +19,7 @@ func (dec *encKxDecryptor) GetEncInfo() EncInfo {
 	return EncInfo{
 		RequiresFinalization: dec.algo.GetInfo().RequiresFinalization,
 		EncType:              dec.algo.GetInfo().EncType,
+		IsRandomized:         dec.algo.GetInfo().IsRandomized,
 	}
 }
 
@@ -17,6 +17,7 @@ func (enc *encKxEncryptor) GetEncInfo() EncInfo {
 	return EncInfo{
 		RequiresFinalization: enc.algo.GetInfo().RequiresFinalization,
 		EncType:              enc.algo.GetInfo().EncType,
+		IsRandomized:         enc.algo.GetInfo().IsRandomized,
 	}
 }
 
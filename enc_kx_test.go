@@ -1,6 +1,7 @@
 package crypka_test
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"testing"
@@ -41,3 +42,91 @@ func TestEnc_KX_WithEd25519_WithAES128GCM_WithXorEnc(t *testing.T) {
 
 	tester.Test(t)
 }
+
+// FuzzEnc_KX_Decrypt feeds arbitrary chunks to decryptor, which parses ephemeral public key from first one.
+func FuzzEnc_KX_Decrypt(f *testing.F) {
+	var keys []crypka.DecKey
+	var encKeys []crypka.EncKey
+	for _, algo := range []crypka.EncAsymAlgo{
+		&crypka.EncAsymKXAlgo{
+			EncSymmAlgo:    &crypka.BlankEncSymmAlgo{},
+			KXAlgo:         &crypka.X25519KXAlgo{},
+			KXResultLength: 32,
+		},
+		&crypka.EncAsymKXAlgo{
+			EncSymmAlgo: &crypka.AEADSymmEncAlgo{
+				KeyLength:   16,
+				NonceLength: 12,
+				NonceConfig: crypka.NonceConfig{
+					NonceType: crypka.CounterNonce,
+				},
+				AEADFactory: func(key []byte) (aead cipher.AEAD, err error) {
+					cph, err := aes.NewCipher(key)
+					if err != nil {
+						return
+					}
+					return cipher.NewGCM(cph)
+				},
+			},
+			KXAlgo:         &crypka.X25519KXAlgo{},
+			KXResultLength: 16,
+		},
+	} {
+		ek, dk, err := algo.GenerateKeyPair(nil, bytes.NewReader(bytes.Repeat([]byte{0x42}, 32)))
+		if err != nil {
+			f.Error(err)
+			return
+		}
+		keys = append(keys, dk)
+		encKeys = append(encKeys, ek)
+	}
+
+	for i, ek := range encKeys {
+		enc, err := ek.MakeEncryptor(&crypka.Context{
+			RNG: bytes.NewReader(bytes.Repeat([]byte{0x24}, 64)),
+		})
+		if err != nil {
+			f.Error(err)
+			return
+		}
+
+		var chunks [][]byte
+		for _, size := range []int{0, 1, 100} {
+			chunk, err := enc.Encrypt(bytes.Repeat([]byte{0x42}, size), nil)
+			if err != nil {
+				f.Error(err)
+				return
+			}
+			chunks = append(chunks, chunk)
+		}
+
+		f.Add(byte(i), crypkatest.FuzzingChunksSeed(chunks...))
+		f.Add(byte(i), crypkatest.FuzzingChunksSeed(chunks[0]))
+	}
+	f.Add(byte(0), crypkatest.FuzzingChunksSeed([]byte{0xff, 0xff, 0xff, 0xff, 0x0f}))
+	f.Add(byte(0), crypkatest.FuzzingChunksSeed([]byte{0x20}))
+	f.Add(byte(0), crypkatest.FuzzingChunksSeed(append([]byte{0x20}, make([]byte, 32)...)))
+
+	f.Fuzz(func(t *testing.T, selector byte, data []byte) {
+		key := keys[int(selector)%len(keys)]
+
+		crypkatest.CheckAllocBound(t, 64*1024+16*uint64(len(data)), func() {
+			dec, err := key.MakeDecryptor(nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			var res []byte
+			_ = crypkatest.FuzzingChunks(data, func(chunk []byte) (err error) {
+				res, err = dec.Decrypt(chunk, res)
+				return
+			})
+			_ = dec.Finalize()
+
+			if len(res) > len(data) {
+				t.Error("decryptor yielded more data than it was given")
+			}
+		})
+	})
+}
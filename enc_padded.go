@@ -0,0 +1,252 @@
+package crypka
+
+import "io"
+
+// NewPaddingEncryptor returns encryptor, which pads each chunk with given padding before it's encrypted with inner one,
+// so lengths of ciphertexts leak less about lengths of messages.
+//
+// Inner encryptor must be block or chain one, since decryptor has to get each encrypted chunk at once in order to unpad it.
+// RNG is used by randomized paddings only. If it's nil, then crypto/rand is used.
+func NewPaddingEncryptor(inner Encryptor, padding PaddingAlgo, rng RNG) (enc Encryptor, err error) {
+	if inner.GetEncInfo().EncType == EncTypeStream {
+		err = ErrPaddingUnsupportedEncType
+		return
+	}
+
+	enc = &paddingEncryptor{
+		inner:   inner,
+		padding: padding,
+		rng:     rng,
+	}
+	return
+}
+
+// NewPaddingDecryptor returns decryptor, which unpads chunks decrypted by inner one.
+// See NewPaddingEncryptor.
+func NewPaddingDecryptor(inner Decryptor, padding PaddingAlgo) (dec Decryptor, err error) {
+	if inner.GetEncInfo().EncType == EncTypeStream {
+		err = ErrPaddingUnsupportedEncType
+		return
+	}
+
+	dec = &paddingDecryptor{
+		inner:   inner,
+		padding: padding,
+	}
+	return
+}
+
+type paddingEncryptor struct {
+	inner   Encryptor
+	padding PaddingAlgo
+	rng     RNG
+
+	buffer []byte
+}
+
+func (enc *paddingEncryptor) GetEncInfo() EncInfo {
+	return enc.inner.GetEncInfo()
+}
+
+func (enc *paddingEncryptor) Encrypt(in, appendTo []byte) (res []byte, err error) {
+	enc.buffer, err = enc.padding.PadMessage(enc.rng, in, enc.buffer[:0])
+	// buffer holds plaintext, so it's not left in memory once it's encrypted
+	defer zeroBytes(enc.buffer)
+	if err != nil {
+		return
+	}
+
+	return enc.inner.Encrypt(enc.buffer, appendTo)
+}
+
+func (enc *paddingEncryptor) Finalize(appendTo []byte) (res []byte, err error) {
+	return enc.inner.Finalize(appendTo)
+}
+
+type paddingDecryptor struct {
+	inner   Decryptor
+	padding PaddingAlgo
+
+	buffer      []byte
+	cachedError error
+}
+
+func (dec *paddingDecryptor) GetEncInfo() EncInfo {
+	return dec.inner.GetEncInfo()
+}
+
+func (dec *paddingDecryptor) Decrypt(in, appendTo []byte) (res []byte, err error) {
+	if dec.cachedError != nil {
+		err = dec.cachedError
+		return
+	}
+
+	dec.buffer, err = dec.inner.Decrypt(in, dec.buffer[:0])
+	defer zeroBytes(dec.buffer)
+	if err != nil {
+		dec.cachedError = err
+		return
+	}
+
+	msg, err := dec.padding.UnpadMessage(dec.buffer)
+	if err != nil {
+		dec.cachedError = err
+		return
+	}
+
+	res = append(appendTo, msg...)
+	return
+}
+
+func (dec *paddingDecryptor) Finalize() (err error) {
+	if dec.cachedError != nil {
+		err = dec.cachedError
+		return
+	}
+	return dec.inner.Finalize()
+}
+
+// PaddedEncSymmAlgo pads each chunk before it's encrypted with inner algorithm and unpads it after decryption.
+// See NewPaddingEncryptor.
+//
+// Inner algorithm must not be stream one, otherwise ErrPaddingUnsupportedEncType is returned, when key is generated or parsed.
+// PaddedEncSymmAlgo can be wrapped with CPKStreamSymmEncAlgo though, in which case each chunk of stream is padded.
+type PaddedEncSymmAlgo struct {
+	EncSymmAlgo
+	Padding PaddingAlgo
+
+	// Name of algorithm, which keys are tagged with, when they are marshaled to text or JSON.
+	// Registry sets it to name algorithm is registered with.
+	AlgoName string
+}
+
+func (algo *PaddedEncSymmAlgo) getAlgoName() string {
+	return algo.AlgoName
+}
+
+func (algo *PaddedEncSymmAlgo) setAlgoName(name string) {
+	algo.AlgoName = name
+}
+
+func (algo *PaddedEncSymmAlgo) checkInner() (err error) {
+	if algo.EncSymmAlgo.GetInfo().EncType == EncTypeStream {
+		err = ErrPaddingUnsupportedEncType
+	}
+	return
+}
+
+func (algo *PaddedEncSymmAlgo) GenerateKey(ctx KeyGenerationContext, rng RNG) (key EncSymmKey, err error) {
+	err = contextCheckPolicy(ctx, KeyGenerationPolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
+	err = algo.checkInner()
+	if err != nil {
+		return
+	}
+
+	inner, err := algo.EncSymmAlgo.GenerateKey(contextForInnerAlgorithm(ctx), rng)
+	if err != nil {
+		return
+	}
+
+	key = &paddedEncSymmKey{
+		algo:    algo,
+		wrapped: inner,
+	}
+	return
+}
+
+func (algo *PaddedEncSymmAlgo) ParseSymmEncKey(ctx KeyParseContext, data []byte) (key EncSymmKey, err error) {
+	err = contextCheckPolicy(ctx, KeyParsePolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
+	err = algo.checkInner()
+	if err != nil {
+		return
+	}
+
+	inner, err := algo.EncSymmAlgo.ParseSymmEncKey(contextForInnerAlgorithm(ctx), data)
+	if err != nil {
+		return
+	}
+
+	key = &paddedEncSymmKey{
+		algo:    algo,
+		wrapped: inner,
+	}
+	return
+}
+
+type paddedEncSymmKey struct {
+	secretKeyMarshalGuard
+
+	algo      *PaddedEncSymmAlgo
+	wrapped   EncSymmKey
+	destroyed bool
+}
+
+func (ek *paddedEncSymmKey) toSerializedKey() (SerializedKey, error) {
+	return makeSerializedKey(ek.algo.AlgoName, SecretSerializedKeyType, ek)
+}
+
+// Destroy destroys wrapped key, if it's destroyable.
+func (ek *paddedEncSymmKey) Destroy() {
+	DestroyKey(ek.wrapped)
+	ek.destroyed = true
+}
+
+func (ek *paddedEncSymmKey) MakeEncryptor(ctx KeyContext) (enc Encryptor, err error) {
+	if ek.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	err = contextCheckPolicy(ctx, MakeEncryptorPolicyOperation, ek.algo, -1)
+	if err != nil {
+		return
+	}
+
+	inner, err := ek.wrapped.MakeEncryptor(contextForInnerAlgorithm(ctx))
+	if err != nil {
+		return
+	}
+
+	return NewPaddingEncryptor(inner, ek.algo.Padding, ContextGetRNG(ctx))
+}
+
+func (ek *paddedEncSymmKey) MakeDecryptor(ctx KeyContext) (dec Decryptor, err error) {
+	if ek.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	err = contextCheckPolicy(ctx, MakeDecryptorPolicyOperation, ek.algo, -1)
+	if err != nil {
+		return
+	}
+
+	inner, err := ek.wrapped.MakeDecryptor(contextForInnerAlgorithm(ctx))
+	if err != nil {
+		return
+	}
+
+	return NewPaddingDecryptor(inner, ek.algo.Padding)
+}
+
+func (ek *paddedEncSymmKey) MarshalToWriter(w io.Writer) (err error) {
+	if ek.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	mk, ok := ek.wrapped.(MarshalableKey)
+	if !ok {
+		err = ErrKeyNotMarshalable
+		return
+	}
+	return mk.MarshalToWriter(w)
+}
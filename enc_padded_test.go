@@ -0,0 +1,142 @@
+package crypka_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+	"github.com/teawithsand/crypka/crypkatest"
+)
+
+func getPaddingAlgo(t *testing.T, name string) (algo crypka.PaddingAlgo) {
+	algo, err := crypka.GetTyped[crypka.PaddingAlgo](nil, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func getEncSymmAlgo(t *testing.T, name string) (algo crypka.EncSymmAlgo) {
+	algo, err := crypka.GetTyped[crypka.EncSymmAlgo](nil, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestEnc_Padded_WithAESGCM(t *testing.T) {
+	for _, padding := range []string{"pad-iec78164", "pad-pkcs7", "pad-padme", "pad-random-bucket"} {
+		tester := crypkatest.EncSymmTester{
+			Algo: &crypka.PaddedEncSymmAlgo{
+				EncSymmAlgo: getEncSymmAlgo(t, "aes-256-gcm-counter"),
+				Padding:     getPaddingAlgo(t, padding),
+			},
+		}
+		t.Run(padding, tester.Test)
+	}
+}
+
+func TestEnc_Padded_WithKeyWrap(t *testing.T) {
+	tester := crypkatest.EncSymmTester{
+		Algo: &crypka.PaddedEncSymmAlgo{
+			EncSymmAlgo: getEncSymmAlgo(t, "aes-256-kwp"),
+			Padding:     getPaddingAlgo(t, "pad-pkcs7"),
+		},
+	}
+	tester.Test(t)
+}
+
+func TestEnc_Padded_InCPKStream(t *testing.T) {
+	tester := crypkatest.EncSymmTester{
+		Algo: &crypka.CPKStreamSymmEncAlgo{
+			EncSymmAlgo: &crypka.PaddedEncSymmAlgo{
+				EncSymmAlgo: getEncSymmAlgo(t, "chacha20-poly1305-counter"),
+				Padding:     getPaddingAlgo(t, "pad-padme"),
+			},
+		},
+	}
+	tester.Test(t)
+}
+
+func TestEnc_Padded_HidesLength(t *testing.T) {
+	algo := &crypka.PaddedEncSymmAlgo{
+		EncSymmAlgo: getEncSymmAlgo(t, "aes-256-gcm-counter"),
+		Padding:     &crypka.RandomBucketPaddingAlgo{BucketSize: 64},
+	}
+
+	key, err := algo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	enc, err := key.MakeEncryptor(nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	short, err := enc.Encrypt([]byte("a"), nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	long, err := enc.Encrypt(make([]byte, 63), nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(short) != len(long) {
+		t.Error("expected messages from same bucket to yield ciphertexts of same length", len(short), len(long))
+	}
+}
+
+func TestEnc_Padded_RejectsStream(t *testing.T) {
+	streamAlgo := getEncSymmAlgo(t, "cpk-stream-aes-256-gcm-counter")
+	algo := &crypka.PaddedEncSymmAlgo{
+		EncSymmAlgo: streamAlgo,
+		Padding:     getPaddingAlgo(t, "pad-pkcs7"),
+	}
+
+	_, err := algo.GenerateKey(nil, nil)
+	if !errors.Is(err, crypka.ErrPaddingUnsupportedEncType) {
+		t.Error("expected stream algorithm to be rejected, got", err)
+		return
+	}
+
+	streamKey, err := streamAlgo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	marshaled, err := crypka.MarshalKeyToSlice(streamKey)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = algo.ParseSymmEncKey(nil, marshaled)
+	if !errors.Is(err, crypka.ErrPaddingUnsupportedEncType) {
+		t.Error("expected stream algorithm to be rejected, got", err)
+		return
+	}
+
+	enc, err := streamKey.MakeEncryptor(nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = crypka.NewPaddingEncryptor(enc, algo.Padding, nil)
+	if !errors.Is(err, crypka.ErrPaddingUnsupportedEncType) {
+		t.Error("expected stream encryptor to be rejected, got", err)
+		return
+	}
+	dec, err := streamKey.MakeDecryptor(nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = crypka.NewPaddingDecryptor(dec, algo.Padding)
+	if !errors.Is(err, crypka.ErrPaddingUnsupportedEncType) {
+		t.Error("expected stream decryptor to be rejected, got", err)
+	}
+}
@@ -1,6 +1,9 @@
 package crypka
 
-import "io"
+import (
+	"fmt"
+	"io"
+)
 
 // Note: this type might change in future, when we run out of values on uint8
 type cpkControlValue uint8
@@ -23,9 +26,45 @@ const (
 	streamEndCpkControlByte cpkControlValue = 0
 )
 
+const defaultCPKStreamChunkSize = 256
+
 // Implements algorithm, which handles streamming encryption in crypka's format.
 type CPKStreamSymmEncAlgo struct {
 	EncSymmAlgo
+
+	// Size of plaintext chunks emitted by encryptor.
+	// Defaults to 256 bytes.
+	ChunkSize int
+
+	// Max size of encrypted chunk accepted by decryptor.
+	// Defaults to 4 * ChunkSize.
+	MaxChunkSize int
+
+	// Name of algorithm, which keys are tagged with, when they are marshaled to text or JSON.
+	// Registry sets it to name algorithm is registered with.
+	AlgoName string
+}
+
+func (algo *CPKStreamSymmEncAlgo) getAlgoName() string {
+	return algo.AlgoName
+}
+
+func (algo *CPKStreamSymmEncAlgo) setAlgoName(name string) {
+	algo.AlgoName = name
+}
+
+func (algo *CPKStreamSymmEncAlgo) getChunkSize() int {
+	if algo.ChunkSize > 0 {
+		return algo.ChunkSize
+	}
+	return defaultCPKStreamChunkSize
+}
+
+func (algo *CPKStreamSymmEncAlgo) getMaxChunkSize() int {
+	if algo.MaxChunkSize > 0 {
+		return algo.MaxChunkSize
+	}
+	return 4 * algo.getChunkSize()
 }
 
 func (algo *CPKStreamSymmEncAlgo) GetInfo() EncAlgoInfo {
@@ -46,12 +85,18 @@ func (algo *CPKStreamSymmEncAlgo) GetInfo() EncAlgoInfo {
 }
 
 func (algo *CPKStreamSymmEncAlgo) GenerateKey(ctx KeyGenerationContext, rng RNG) (key EncSymmKey, err error) {
-	inner, err := algo.EncSymmAlgo.GenerateKey(ctx, rng)
+	err = contextCheckPolicy(ctx, KeyGenerationPolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
+	inner, err := algo.EncSymmAlgo.GenerateKey(contextForInnerAlgorithm(ctx), rng)
 	if err != nil {
 		return
 	}
 
 	key = &cpkStreamEncSymmKey{
+		algo:    algo,
 		wrapped: inner,
 	}
 
@@ -59,12 +104,18 @@ func (algo *CPKStreamSymmEncAlgo) GenerateKey(ctx KeyGenerationContext, rng RNG)
 }
 
 func (algo *CPKStreamSymmEncAlgo) ParseSymmEncKey(ctx KeyParseContext, data []byte) (key EncSymmKey, err error) {
-	inner, err := algo.EncSymmAlgo.ParseSymmEncKey(ctx, data)
+	err = contextCheckPolicy(ctx, KeyParsePolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
+	inner, err := algo.EncSymmAlgo.ParseSymmEncKey(contextForInnerAlgorithm(ctx), data)
 	if err != nil {
 		return
 	}
 
 	key = &cpkStreamEncSymmKey{
+		algo:    algo,
 		wrapped: inner,
 	}
 
@@ -72,30 +123,69 @@ func (algo *CPKStreamSymmEncAlgo) ParseSymmEncKey(ctx KeyParseContext, data []by
 }
 
 type cpkStreamEncSymmKey struct {
-	wrapped EncSymmKey
+	secretKeyMarshalGuard
+
+	algo      *CPKStreamSymmEncAlgo
+	wrapped   EncSymmKey
+	destroyed bool
+}
+
+// Destroy destroys wrapped key, if it's destroyable.
+func (ek *cpkStreamEncSymmKey) Destroy() {
+	DestroyKey(ek.wrapped)
+	ek.destroyed = true
+}
+
+func (ek *cpkStreamEncSymmKey) toSerializedKey() (SerializedKey, error) {
+	return makeSerializedKey(ek.algo.AlgoName, SecretSerializedKeyType, ek)
 }
 
 func (ek *cpkStreamEncSymmKey) MakeEncryptor(ctx KeyContext) (enc Encryptor, err error) {
-	inner, err := ek.wrapped.MakeEncryptor(ctx)
+	if ek.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	err = contextCheckPolicy(ctx, MakeEncryptorPolicyOperation, ek.algo, -1)
 	if err != nil {
 		return
 	}
 
-	enc = newCPKStreamEncryptor(inner, 256)
+	inner, err := ek.wrapped.MakeEncryptor(contextForInnerAlgorithm(ctx))
+	if err != nil {
+		return
+	}
+
+	enc = newCPKStreamEncryptor(inner, ek.algo.getChunkSize())
 	return
 }
 
 func (ek *cpkStreamEncSymmKey) MakeDecryptor(ctx KeyContext) (dec Decryptor, err error) {
-	inner, err := ek.wrapped.MakeDecryptor(ctx)
+	if ek.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	err = contextCheckPolicy(ctx, MakeDecryptorPolicyOperation, ek.algo, -1)
+	if err != nil {
+		return
+	}
+
+	inner, err := ek.wrapped.MakeDecryptor(contextForInnerAlgorithm(ctx))
 	if err != nil {
 		return
 	}
 
-	dec = newCPKStreamDecryptor(inner, 1024)
+	dec = newCPKStreamDecryptor(inner, ek.algo.getMaxChunkSize())
 	return
 }
 
 func (ek *cpkStreamEncSymmKey) MarshalToWriter(w io.Writer) (err error) {
+	if ek.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
 	mk, ok := ek.wrapped.(MarshalableKey)
 	if !ok {
 		err = ErrKeyNotMarshalable
@@ -103,3 +193,21 @@ func (ek *cpkStreamEncSymmKey) MarshalToWriter(w io.Writer) (err error) {
 	}
 	return mk.MarshalToWriter(w)
 }
+
+// Registers CPKStreamSymmEncAlgo wrapping already registered algorithm with given name as "cpk-stream-<name>".
+// Panics if there is no such algorithm.
+func RegisterCPKStream(reg Registry, innerName string) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	var inner EncSymmAlgo
+	err := reg.GetAlgorithmTyped(innerName, &inner)
+	if err != nil {
+		panic(fmt.Errorf("crypka: can't register cpk stream for %s: %w", innerName, err))
+	}
+
+	registerDefault(reg, "cpk-stream-"+innerName, &CPKStreamSymmEncAlgo{
+		EncSymmAlgo: inner,
+	})
+}
@@ -31,6 +31,7 @@ func (dec *cpkStreamDecryptor) GetEncInfo() EncInfo {
 	return EncInfo{
 		RequiresFinalization: true,
 		EncType:              EncTypeStream,
+		IsRandomized:         dec.inner.GetEncInfo().IsRandomized,
 	}
 }
 
@@ -66,6 +67,12 @@ func (dec *cpkStreamDecryptor) Decrypt(in, appendTo []byte) (res []byte, err err
 
 			chunkSize, sz, err = dec.chunkSizeEncoding.DecodeAtStart(dec.dataBuffer)
 			if err != nil {
+				// encoding may be just incomplete, unless there is more data than any valid one has
+				if len(dec.dataBuffer) >= dec.chunkSizeEncoding.MaxSize() {
+					err = ErrEncStreamCorrupted
+					dec.cachedError = ErrEncStreamCorrupted
+					return
+				}
 				err = nil
 				continue
 			}
@@ -84,7 +91,9 @@ func (dec *cpkStreamDecryptor) Decrypt(in, appendTo []byte) (res []byte, err err
 			}
 
 			if sz != len(dec.dataBuffer) {
-				panic("assertion filed: somehow read less bytes than buffer was")
+				err = ErrEncStreamCorrupted
+				dec.cachedError = ErrEncStreamCorrupted
+				return
 			}
 
 			dec.restChunkSize = int(chunkSize)
@@ -31,6 +31,7 @@ func (enc *cpkStreamEncryptor) GetEncInfo() EncInfo {
 	return EncInfo{
 		RequiresFinalization: true,
 		EncType:              EncTypeStream,
+		IsRandomized:         enc.inner.GetEncInfo().IsRandomized,
 	}
 }
 
@@ -1,6 +1,10 @@
 package crypka_test
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
 	"testing"
 
 	"github.com/teawithsand/crypka"
@@ -50,3 +54,126 @@ func FuzzEnc_Stream_WithBlank_EncryptDecrypt(f *testing.F) {
 
 	tester.Fuzz(f, crypkatest.EncSymmFuzzEncryptDecryptChunks)
 }
+
+func TestEnc_Stream_WithAES256GCM(t *testing.T) {
+	var algo crypka.EncSymmAlgo
+	err := crypka.GlobalRegistry.GetAlgorithmTyped("cpk-stream-aes-256-gcm-counter", &algo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tester := crypkatest.EncSymmTester{
+		Algo: algo,
+	}
+
+	tester.Test(t)
+}
+
+// FuzzEnc_Stream_Decrypt feeds arbitrary chunks to stream decryptor.
+// Blank inner algorithm lets fuzzer reach chunk counters and control values, which are authenticated otherwise.
+func FuzzEnc_Stream_Decrypt(f *testing.F) {
+	var keys []crypka.EncSymmKey
+	for _, algo := range []crypka.EncSymmAlgo{
+		&crypka.CPKStreamSymmEncAlgo{
+			EncSymmAlgo: &crypka.BlankEncSymmAlgo{},
+			ChunkSize:   16,
+		},
+		&crypka.CPKStreamSymmEncAlgo{
+			EncSymmAlgo: &crypka.AEADSymmEncAlgo{
+				KeyLength:   16,
+				NonceLength: 12,
+				NonceConfig: crypka.NonceConfig{
+					NonceType: crypka.CounterNonce,
+				},
+				AEADFactory: func(key []byte) (aead cipher.AEAD, err error) {
+					cph, err := aes.NewCipher(key)
+					if err != nil {
+						return
+					}
+					return cipher.NewGCM(cph)
+				},
+			},
+			ChunkSize: 16,
+		},
+	} {
+		key, err := algo.GenerateKey(nil, bytes.NewReader(make([]byte, 16)))
+		if err != nil {
+			f.Error(err)
+			return
+		}
+		keys = append(keys, key)
+	}
+
+	for i, key := range keys {
+		for _, size := range []int{0, 1, 16, 100} {
+			enc, err := key.MakeEncryptor(nil)
+			if err != nil {
+				f.Error(err)
+				return
+			}
+			ct, err := enc.Encrypt(bytes.Repeat([]byte{0x42}, size), nil)
+			if err != nil {
+				f.Error(err)
+				return
+			}
+			ct, err = enc.Finalize(ct)
+			if err != nil {
+				f.Error(err)
+				return
+			}
+
+			f.Add(byte(i), crypkatest.FuzzingChunksSeed(ct))
+			f.Add(byte(i), crypkatest.FuzzingChunksSeed(ct[:len(ct)/2], ct[len(ct)/2:]))
+		}
+	}
+	f.Add(byte(0), crypkatest.FuzzingChunksSeed(bytes.Repeat([]byte{0xff}, 32)))
+	f.Add(byte(0), crypkatest.FuzzingChunksSeed([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}))
+	f.Add(byte(0), crypkatest.FuzzingChunksSeed([]byte{0x00, 0x01}))
+
+	f.Fuzz(func(t *testing.T, selector byte, data []byte) {
+		key := keys[int(selector)%len(keys)]
+
+		crypkatest.CheckAllocBound(t, 64*1024+16*uint64(len(data)), func() {
+			dec, err := key.MakeDecryptor(nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			var res []byte
+			_ = crypkatest.FuzzingChunks(data, func(chunk []byte) (err error) {
+				res, err = dec.Decrypt(chunk, res)
+				return
+			})
+			_ = dec.Finalize()
+
+			if len(res) > len(data) {
+				t.Error("decryptor yielded more data than it was given")
+			}
+		})
+	})
+}
+
+func TestEnc_Stream_RejectsOverlongChunkSize(t *testing.T) {
+	algo := &crypka.CPKStreamSymmEncAlgo{
+		EncSymmAlgo: &crypka.BlankEncSymmAlgo{},
+	}
+	key, err := algo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	dec, err := key.MakeDecryptor(nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// this is never valid chunk size, so decryptor must not wait for more data
+	_, err = dec.Decrypt(bytes.Repeat([]byte{0xff}, 16), nil)
+	if !errors.Is(err, crypka.ErrEncStreamCorrupted) {
+		t.Error("expected stream to be corrupted, got", err)
+	}
+}
@@ -11,16 +11,23 @@ type XorEncSymmAlgo struct {
 }
 
 func (algo *XorEncSymmAlgo) GenerateKey(ctx KeyGenerationContext, rng RNG) (sk EncSymmKey, err error) {
+	err = contextCheckPolicy(ctx, KeyGenerationPolicyOperation, algo, algo.GenerateKeyLength)
+	if err != nil {
+		return
+	}
+
 	rng = FallbackContextGetRNG(ctx, rng)
 
-	key := make([]byte, algo.GenerateKeyLength)
+	key := allocKeyMaterial(ctx, algo.GenerateKeyLength)
 	_, err = io.ReadFull(rng, key)
 	if err != nil {
+		destroyKeyMaterial(key)
 		return
 	}
 
 	sk = &xorEncSymmKey{
-		key: key,
+		algo: algo,
+		key:  key,
 	}
 
 	return
@@ -50,33 +57,75 @@ func (algo *XorEncSymmAlgo) ParseSymmEncKey(ctx KeyParseContext, data []byte) (e
 		return
 	}
 
-	keyCopy := make([]byte, len(data))
-	copy(keyCopy, data)
+	err = contextCheckPolicy(ctx, KeyParsePolicyOperation, algo, len(data))
+	if err != nil {
+		return
+	}
+
+	keyCopy := copyKeyMaterial(ctx, data)
 
 	ek = &xorEncSymmKey{
-		key: keyCopy,
+		algo: algo,
+		key:  keyCopy,
 	}
 	return
 }
 
 type xorEncSymmKey struct {
-	key []byte
+	secretKeyMarshalGuard
+
+	algo      *XorEncSymmAlgo
+	key       []byte
+	destroyed bool
 }
 
 func (k *xorEncSymmKey) MarshalToWriter(w io.Writer) (err error) {
+	if k.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
 	_, err = w.Write(k.key)
 	return
 }
 
+func (k *xorEncSymmKey) Destroy() {
+	destroyKeyMaterial(k.key)
+	k.key = nil
+	k.destroyed = true
+}
+
+// Note: key is copied, so encryptors and decryptors are not affected by Destroy.
+
 func (k *xorEncSymmKey) MakeEncryptor(ctx KeyContext) (enc Encryptor, err error) {
+	if k.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	err = contextCheckPolicy(ctx, MakeEncryptorPolicyOperation, k.algo, len(k.key))
+	if err != nil {
+		return
+	}
+
 	enc = &xorSymmEncryptor{
-		key: k.key,
+		key: append([]byte{}, k.key...),
 	}
 	return
 }
 func (k *xorEncSymmKey) MakeDecryptor(ctx KeyContext) (dec Decryptor, err error) {
+	if k.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	err = contextCheckPolicy(ctx, MakeDecryptorPolicyOperation, k.algo, len(k.key))
+	if err != nil {
+		return
+	}
+
 	dec = &xorSymmDecryptor{
-		key: k.key,
+		key: append([]byte{}, k.key...),
 	}
 	return
 }
@@ -4,6 +4,7 @@ import "errors"
 
 var ErrKeyParseField = errors.New("crypka: filed to parse key")
 var ErrKeyNotMarshalable = errors.New("crypka: Key is not marshallable")
+var ErrKeyGenerationInvalidLength = errors.New("crypka: algorithm is configured to generate keys of length, which it does not accept")
 
 var ErrSignInvalid = errors.New("crypka: sign is invalid")
 
@@ -28,3 +29,44 @@ var ErrPasswordHashMismatch = errors.New("crypka: password hash does not match p
 var ErrPasswordHashParseFiled = errors.New("crypka: filed to parse password hash")
 var ErrPasswordHashUnknownAlgo = errors.New("crypka: given password hash is encoded using unsupported algorithm")
 var ErrPasswordHashParamMismatch = errors.New("crpyka: given password hash has different parameters compared to hasher, so it can't be processed")
+
+var ErrPoWChallengeExpired = errors.New("crypka: proof of work challenge has expired")
+var ErrPoWChallengeInvalid = errors.New("crypka: proof of work challenge is not valid or was modified")
+var ErrPoWSolutionInvalid = errors.New("crypka: proof of work solution is not valid")
+var ErrPoWUnsolvable = errors.New("crypka: proof of work challenge can't be solved")
+var ErrPoWDifficultyTooLow = errors.New("crypka: proof of work challenge difficulty is lower than required")
+var ErrPoWChallengeReplayed = errors.New("crypka: proof of work challenge was solved already")
+
+var ErrPasswordHashQueueTimeout = errors.New("crypka: password hashing request waited in queue for too long")
+var ErrPasswordHashQueueFull = errors.New("crypka: password hashing queue is full")
+
+var ErrEncInvalidInputLength = errors.New("crypka: given chunk has length, which can't be encrypted by this algorithm")
+var ErrEncNotRandomized = errors.New("crypka: given key yields encryptors, which reuse nonces, so it can't be used to encrypt more than one message")
+
+var ErrKeyWrapInvalid = errors.New("crypka: wrapped key is not valid")
+var ErrKeyWrapUnsupportedKEK = errors.New("crypka: given key can't be used as key encryption key. It must yield block encryptors")
+
+var ErrKeyFormatUnsupported = errors.New("crypka: key can't be exported to or imported from given format")
+
+var ErrKeySecretNotExportable = errors.New("crypka: secret key can't be marshaled this way. Wrap it in ExportableSecret to allow that")
+
+var ErrKeyringNoPrimaryKey = errors.New("crypka: keyring has no primary key")
+var ErrKeyringKeyNotFound = errors.New("crypka: keyring does not contain key with given ID")
+var ErrKeyringKeyExists = errors.New("crypka: keyring already contains given key")
+var ErrKeyringKeyNotUsable = errors.New("crypka: given keyring key can't be primary key or primary key can't be removed")
+
+var ErrKeyDestroyed = errors.New("crypka: key was destroyed and can't be used anymore")
+
+var ErrKeyPublicNotDerivable = errors.New("crypka: public key can't be derived from given key")
+
+var ErrKeyDerivationInvalidMaster = errors.New("crypka: master secret used for key derivation is too short")
+var ErrKeyDerivationInvalidLabel = errors.New("crypka: key derivation label is not valid")
+var ErrKeyDerivationUnsupportedAlgo = errors.New("crypka: given algorithm does not support key generation from RNG")
+
+var ErrAlgorithmDisallowedByPolicy = errors.New("crypka: usage of given algorithm or key is disallowed by policy")
+
+var ErrAlgorithmSpecInvalid = errors.New("crypka: algorithm specification is not valid")
+var ErrAlgorithmSpecNotRepresentable = errors.New("crypka: given algorithm can't be represented as algorithm specification")
+
+var ErrPaddingInvalid = errors.New("crypka: padding of message is not valid")
+var ErrPaddingUnsupportedEncType = errors.New("crypka: padding can't be applied to stream encryption, since chunks passed to decryptor may be sliced")
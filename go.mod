@@ -2,7 +2,6 @@ module github.com/teawithsand/crypka
 
 go 1.18
 
-require (
-	golang.org/x/crypto v0.0.0-20220315160706-3147a52a75dd // indirect
-	golang.org/x/sys v0.0.0-20210615035016-665e8c7367d1 // indirect
-)
+require golang.org/x/crypto v0.0.0-20220315160706-3147a52a75dd
+
+require golang.org/x/sys v0.0.0-20210615035016-665e8c7367d1 // indirect
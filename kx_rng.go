@@ -61,7 +61,7 @@ func (algo *KXRngAlgo) PerformExchange(ctx KeyContext, public KXPublic, secret K
 		return
 	}
 
-	rng, err := algo.RNGAlgo.MakeRng(ctx, seed)
+	rng, err := algo.RNGAlgo.MakeRng(contextForInnerAlgorithm(ctx), seed)
 	if err != nil {
 		return
 	}
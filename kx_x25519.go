@@ -6,7 +6,21 @@ import (
 	"golang.org/x/crypto/curve25519"
 )
 
-type X25519KXAlgo struct{}
+// X25519KXAlgo implements key exchange using x25519.
+//
+// AlgoName is name of algorithm, which keys are tagged with, when they are marshaled to text or JSON.
+// Registry sets it to name algorithm is registered with. Keys of algorithm without name can't be marshaled that way.
+type X25519KXAlgo struct {
+	AlgoName string
+}
+
+func (algo *X25519KXAlgo) getAlgoName() string {
+	return algo.AlgoName
+}
+
+func (algo *X25519KXAlgo) setAlgoName(name string) {
+	algo.AlgoName = name
+}
 
 func (algo *X25519KXAlgo) GetInfo() KXAlgorithmInfo {
 	return KXAlgorithmInfo{
@@ -19,6 +33,11 @@ func (algo *X25519KXAlgo) GetInfo() KXAlgorithmInfo {
 }
 
 func (algo *X25519KXAlgo) GenerateKXPair(ctx KeyGenerationContext, rng RNG) (public KXPublic, secret KXSecret, err error) {
+	err = contextCheckPolicy(ctx, KeyGenerationPolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
 	rng = FallbackContextGetRNG(ctx, rng)
 
 	var secretBuf [curve25519.ScalarSize]byte
@@ -32,15 +51,22 @@ func (algo *X25519KXAlgo) GenerateKXPair(ctx KeyGenerationContext, rng RNG) (pub
 
 	public = &x25519KXPublic{
 		data: publicBuf,
+		algo: algo,
 	}
 
 	secret = &x25519KXSecret{
 		data: secretBuf,
+		algo: algo,
 	}
 	return
 }
 
 func (algo *X25519KXAlgo) ParseKXPublic(ctx KeyParseContext, data []byte) (pub KXPublic, err error) {
+	err = contextCheckPolicy(ctx, KeyParsePolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
 	if len(data) != curve25519.PointSize {
 		err = ErrKeyParseField
 		return
@@ -51,11 +77,17 @@ func (algo *X25519KXAlgo) ParseKXPublic(ctx KeyParseContext, data []byte) (pub K
 
 	pub = &x25519KXPublic{
 		data: buf,
+		algo: algo,
 	}
 	return
 }
 
 func (algo *X25519KXAlgo) ParseKXSecret(ctx KeyParseContext, data []byte) (sec KXSecret, err error) {
+	err = contextCheckPolicy(ctx, KeyParsePolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
 	if len(data) != curve25519.ScalarSize {
 		err = ErrKeyParseField
 		return
@@ -66,6 +98,7 @@ func (algo *X25519KXAlgo) ParseKXSecret(ctx KeyParseContext, data []byte) (sec K
 
 	sec = &x25519KXSecret{
 		data: buf,
+		algo: algo,
 	}
 
 	return
@@ -88,6 +121,10 @@ func (algo *X25519KXAlgo) PerformExchange(ctx KeyContext, public KXPublic, secre
 		err = ErrKXUnsupportedPart
 		return
 	}
+	if typedSecret.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
 
 	kxDest, err := curve25519.X25519(typedSecret.data[:], typedPublic.data[:])
 	if err != nil {
@@ -101,6 +138,7 @@ func (algo *X25519KXAlgo) PerformExchange(ctx KeyContext, public KXPublic, secre
 
 type x25519KXPublic struct {
 	data [curve25519.PointSize]byte
+	algo *X25519KXAlgo
 }
 
 func (pub *x25519KXPublic) MarshalToWriter(w io.Writer) (err error) {
@@ -108,11 +146,77 @@ func (pub *x25519KXPublic) MarshalToWriter(w io.Writer) (err error) {
 	return
 }
 
+func (pub *x25519KXPublic) toSerializedKey() (SerializedKey, error) {
+	return makeSerializedKey(pub.algo.AlgoName, PublicSerializedKeyType, pub)
+}
+
+func (pub *x25519KXPublic) MarshalText() (res []byte, err error) {
+	sk, err := pub.toSerializedKey()
+	if err != nil {
+		return
+	}
+	return sk.MarshalText()
+}
+
+func (pub *x25519KXPublic) MarshalJSON() (res []byte, err error) {
+	sk, err := pub.toSerializedKey()
+	if err != nil {
+		return
+	}
+	return sk.MarshalJSON()
+}
+
 type x25519KXSecret struct {
-	data [curve25519.PointSize]byte
+	secretKeyMarshalGuard
+
+	data      [curve25519.PointSize]byte
+	algo      *X25519KXAlgo
+	destroyed bool
+}
+
+// Destroy zeroes secret.
+// Note: secret is stored inline, so it does not support LockKeyMemory.
+func (sec *x25519KXSecret) Destroy() {
+	zeroBytes(sec.data[:])
+	sec.destroyed = true
+}
+
+// DerivePublicKey returns KXPublic matching this secret.
+func (sec *x25519KXSecret) DerivePublicKey(ctx KeyContext) (pub interface{}, err error) {
+	if sec.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	var publicBuf [curve25519.PointSize]byte
+	curve25519.ScalarBaseMult(&publicBuf, &sec.data)
+
+	pub = &x25519KXPublic{
+		data: publicBuf,
+		algo: sec.algo,
+	}
+	return
 }
 
 func (sec *x25519KXSecret) MarshalToWriter(w io.Writer) (err error) {
+	if sec.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
 	_, err = w.Write(sec.data[:])
 	return
 }
+
+func (sec *x25519KXSecret) toSerializedKey() (SerializedKey, error) {
+	return makeSerializedKey(sec.algo.AlgoName, SecretSerializedKeyType, sec)
+}
+
+// Registers x25519 key exchange as "x25519".
+func RegisterX25519(reg Registry) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	registerDefault(reg, "x25519", &X25519KXAlgo{})
+}
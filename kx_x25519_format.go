@@ -0,0 +1,82 @@
+package crypka
+
+import (
+	"bytes"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// Note: OpenSSH does not use x25519 keys directly, so OpenSSHKeyFormat is not supported for them.
+
+func (pub *x25519KXPublic) ExportKeyFormat(ctx KeyContext, format KeyFormat) (data []byte, err error) {
+	key := okpKey{
+		Public: pub.data[:],
+	}
+
+	switch format {
+	case PEMKeyFormat:
+		return okpX25519.MarshalPEM(key)
+	case JWKKeyFormat:
+		return okpX25519.MarshalJWK(key)
+	default:
+		err = ErrKeyFormatUnsupported
+		return
+	}
+}
+
+func (sec *x25519KXSecret) ExportKeyFormat(ctx KeyContext, format KeyFormat) (data []byte, err error) {
+	if sec.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	var public [curve25519.PointSize]byte
+	curve25519.ScalarBaseMult(&public, &sec.data)
+
+	key := okpKey{
+		Secret: sec.data[:],
+		Public: public[:],
+	}
+
+	switch format {
+	case PEMKeyFormat:
+		return okpX25519.MarshalPEM(key)
+	case JWKKeyFormat:
+		return okpX25519.MarshalJWK(key)
+	default:
+		err = ErrKeyFormatUnsupported
+		return
+	}
+}
+
+func (algo *X25519KXAlgo) ImportKeyFormat(ctx KeyParseContext, format KeyFormat, data []byte) (key interface{}, err error) {
+	var okp okpKey
+	switch format {
+	case PEMKeyFormat:
+		okp, err = okpX25519.ParsePEM(data, curve25519.ScalarSize)
+	case JWKKeyFormat:
+		okp, err = okpX25519.ParseJWK(data, curve25519.ScalarSize)
+	default:
+		err = ErrKeyFormatUnsupported
+	}
+	if err != nil {
+		return
+	}
+
+	if okp.Secret == nil {
+		return algo.ParseKXPublic(ctx, okp.Public)
+	}
+
+	if okp.Public != nil {
+		var secret, public [curve25519.PointSize]byte
+		copy(secret[:], okp.Secret)
+		curve25519.ScalarBaseMult(&public, &secret)
+
+		if !bytes.Equal(okp.Public, public[:]) {
+			err = ErrKeyParseField
+			return
+		}
+	}
+
+	return algo.ParseKXSecret(ctx, okp.Secret)
+}
@@ -1,5 +1,7 @@
 package crypka
 
+import "fmt"
+
 type AlgorithmType uint8
 
 const (
@@ -10,8 +12,38 @@ const (
 	AsymSignAlgorithmType AlgorithmType = 5
 	RNGAlgorithmType      AlgorithmType = 6
 	KXAlgorithmType       AlgorithmType = 7
+	PoWAlgorithmType      AlgorithmType = 8
+	PHashAlgorithmType    AlgorithmType = 9
+	PaddingAlgorithmType  AlgorithmType = 10
 )
 
+func (t AlgorithmType) String() string {
+	switch t {
+	case SymmEncAlgorithmType:
+		return "symm-enc"
+	case AsymEncAlgorithmType:
+		return "asym-enc"
+	case HashAlgorithmType:
+		return "hash"
+	case SymmSignAlgorithmType:
+		return "symm-sign"
+	case AsymSignAlgorithmType:
+		return "asym-sign"
+	case RNGAlgorithmType:
+		return "rng"
+	case KXAlgorithmType:
+		return "kx"
+	case PoWAlgorithmType:
+		return "pow"
+	case PHashAlgorithmType:
+		return "phash"
+	case PaddingAlgorithmType:
+		return "padding"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+}
+
 type BaseAlgorithmInfo struct {
 	Type AlgorithmType
 
@@ -19,3 +51,60 @@ type BaseAlgorithmInfo struct {
 	// This may change if algorithm is discovered to be insecure.
 	IsSecure bool
 }
+
+// AlgorithmInfo is normalized info of any algorithm.
+type AlgorithmInfo struct {
+	BaseAlgorithmInfo
+
+	// Name, which algorithm was registered with.
+	Name string
+
+	// Info returned by GetInfo method of algorithm, like EncAlgoInfo or KXAlgorithmInfo.
+	Details interface{}
+
+	// Set if algorithm was deprecated in registry.
+	// Successor is name of algorithm, which should be used instead, if any.
+	Deprecated bool
+	Successor  string
+}
+
+// GetAlgorithmInfo returns normalized info of given algorithm, with empty name.
+// Returns ErrInvalidAlgorithmType if it has no known GetInfo method.
+func GetAlgorithmInfo(algo interface{}) (info AlgorithmInfo, err error) {
+	switch typedAlgo := algo.(type) {
+	case EncAlgo:
+		details := typedAlgo.GetInfo()
+		info.BaseAlgorithmInfo = details.BaseAlgorithmInfo
+		info.Details = details
+	case SignAlgo:
+		details := typedAlgo.GetInfo()
+		info.BaseAlgorithmInfo = details.BaseAlgorithmInfo
+		info.Details = details
+	case KXAlgo:
+		details := typedAlgo.GetInfo()
+		info.BaseAlgorithmInfo = details.BaseAlgorithmInfo
+		info.Details = details
+	case RNGAlgo:
+		details := typedAlgo.GetInfo()
+		info.BaseAlgorithmInfo = details.BaseAlgorithmInfo
+		info.Details = details
+	case PoWAlgo:
+		details := typedAlgo.GetInfo()
+		info.BaseAlgorithmInfo = details.BaseAlgorithmInfo
+		info.Details = details
+	case PHasher:
+		details := typedAlgo.GetInfo()
+		info.BaseAlgorithmInfo = BaseAlgorithmInfo{
+			Type:     PHashAlgorithmType,
+			IsSecure: details.Secure,
+		}
+		info.Details = details
+	case PaddingAlgo:
+		details := typedAlgo.GetInfo()
+		info.BaseAlgorithmInfo = details.BaseAlgorithmInfo
+		info.Details = details
+	default:
+		err = ErrInvalidAlgorithmType
+	}
+	return
+}
@@ -10,12 +10,37 @@ type KeyParseContext = *Context
 type RNGGenerationContext = *Context
 type HashContext = *Context
 type PasswordHashContext = *Context
+type PoWContext = *Context
 
 type AnyContext = *Context
 
 type Context struct {
-	RNG              RNG
+	RNG RNG
+
+	// If set, context is marked as tainted, once insecure algorithm is used with it.
+	// See IsInsecureTainted.
 	SetInsecureTaint bool
+
+	// Optional policy, which restricts algorithms that may be used with this context.
+	Policy *Policy
+
+	// If set, secret keys generated or parsed with this context keep their material in memory locked with mlock,
+	// so it's never swapped to disk. It's best effort and it's supported on linux only.
+	//
+	// Such keys should always be destroyed(see DestroyableKey), since otherwise locked memory is never freed.
+	LockKeyMemory bool
+
+	insecureTainted int32
+
+	// Context, which shares insecure taint with this one. Set for contexts derived by contextForInnerAlgorithm.
+	taintRoot *Context
+}
+
+func (ctx *Context) getTaintRoot() *Context {
+	if ctx.taintRoot != nil {
+		return ctx.taintRoot
+	}
+	return ctx
 }
 
 func MakeDefaultContext() *Context {
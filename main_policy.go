@@ -0,0 +1,249 @@
+package crypka
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// PolicyOperation is operation, during which policy was checked.
+type PolicyOperation uint8
+
+const (
+	KeyGenerationPolicyOperation PolicyOperation = 1
+	KeyParsePolicyOperation      PolicyOperation = 2
+	MakeEncryptorPolicyOperation PolicyOperation = 3
+	MakeDecryptorPolicyOperation PolicyOperation = 4
+	MakeRNGPolicyOperation       PolicyOperation = 5
+
+	// Policy is not checked, when verifiers are created.
+	// This operation is used only when reporting DeprecatedUsage.
+	MakeVerifierPolicyOperation PolicyOperation = 6
+)
+
+// PolicyViolationReason describes why operation was rejected by policy.
+type PolicyViolationReason uint8
+
+const (
+	InsecureAlgorithmPolicyViolation   PolicyViolationReason = 1
+	AlgorithmNotAllowedPolicyViolation PolicyViolationReason = 2
+	KeyTooShortPolicyViolation         PolicyViolationReason = 3
+	DeprecatedAlgorithmPolicyViolation PolicyViolationReason = 4
+)
+
+// PolicyViolation is passed to Policy.OnViolation, each time policy rejects some operation.
+type PolicyViolation struct {
+	Operation PolicyOperation
+	Reason    PolicyViolationReason
+
+	// Algorithm, which was used.
+	Algo interface{}
+
+	// Info of algorithm. Name is set only if algorithm was found in policy's registry.
+	Info AlgorithmInfo
+
+	// Length of symmetric key, if it was checked.
+	KeyLength int
+}
+
+// Policy restricts algorithms, which may be used with Context it's attached to.
+// It's checked when keys are generated or parsed, when encryptors and decryptors are created and when RNGs are created.
+//
+// Algorithms, which wrap other algorithms like CPKStreamSymmEncAlgo or EncAsymKXAlgo, are checked against allowlist as whole,
+// so their inner algorithms do not have to be allowed separately. Other rules are checked for inner algorithms as well.
+//
+// Policy must not be modified once it's in use.
+type Policy struct {
+	// If false, algorithms, which are not secure according to their info, are rejected.
+	AllowInsecure bool
+
+	// Minimum length in bytes of symmetric keys. Zero means no limit.
+	MinSymmKeyLength int
+
+	// If not empty, only algorithms registered in Registry under one of these names are allowed.
+	AllowedAlgorithms []string
+
+	// If set, algorithms deprecated in Registry can't be used to generate keys or to encrypt data.
+	// They still can be used to parse keys and to decrypt data, so existing data can be migrated.
+	RejectDeprecated bool
+
+	// Registry used to resolve names of algorithms. GlobalRegistry is used if nil.
+	Registry Registry
+
+	// If set, violations are reported to OnViolation, but operations are not rejected.
+	AuditOnly bool
+
+	// Optional, called for each violation.
+	OnViolation func(violation PolicyViolation)
+}
+
+func (p *Policy) getRegistry() Registry {
+	if p.Registry == nil {
+		return GlobalRegistry
+	}
+	return p.Registry
+}
+
+func isSameAlgorithm(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// algorithmMatcher checks if algorithm is the one, which given name or specification refers to.
+type algorithmMatcher struct {
+	reg  Registry
+	algo interface{}
+
+	specDone bool
+	spec     string
+}
+
+func (m *algorithmMatcher) matches(name string) bool {
+	if isSameAlgorithm(m.reg.GetAlgo(name), m.algo) {
+		return true
+	}
+
+	// algorithms built from specification are not registered, so they are compared by canonical specification
+	if !m.specDone {
+		m.specDone = true
+		m.spec, _ = FormatAlgorithmSpec(m.reg, m.algo)
+	}
+	if len(m.spec) == 0 {
+		return false
+	}
+
+	canonical, ok := canonicalAlgorithmSpec(m.reg, name)
+	return ok && canonical == m.spec
+}
+
+// isAllowed returns true if algorithm is in allowlist along with name or specification it was allowed with.
+func (p *Policy) isAllowed(algo interface{}) (name string, ok bool) {
+	m := algorithmMatcher{reg: p.getRegistry(), algo: algo}
+	for _, name := range p.AllowedAlgorithms {
+		if m.matches(name) {
+			return name, true
+		}
+	}
+	return
+}
+
+// findName returns name, which algorithm was registered with in policy's registry.
+func (p *Policy) findName(algo interface{}) (name string, ok bool) {
+	reg := p.getRegistry()
+	m := algorithmMatcher{reg: reg, algo: algo}
+	for _, name := range reg.ListAlgorithms() {
+		if m.matches(name) {
+			return name, true
+		}
+	}
+	return
+}
+
+func (p *Policy) report(violation PolicyViolation) (err error) {
+	if p.OnViolation != nil {
+		p.OnViolation(violation)
+	}
+	if !p.AuditOnly {
+		err = ErrAlgorithmDisallowedByPolicy
+	}
+	return
+}
+
+// Check checks if algorithm may be used for given operation.
+// keyLength is length of symmetric key or negative value if it should not be checked.
+func (p *Policy) Check(op PolicyOperation, algo interface{}, keyLength int) (err error) {
+	info, _ := GetAlgorithmInfo(algo)
+	violation := PolicyViolation{
+		Operation: op,
+		Algo:      algo,
+		Info:      info,
+		KeyLength: keyLength,
+	}
+
+	if len(p.AllowedAlgorithms) > 0 {
+		name, ok := p.isAllowed(algo)
+		if !ok {
+			violation.Reason = AlgorithmNotAllowedPolicyViolation
+			return p.report(violation)
+		}
+		violation.Info.Name = name
+	}
+
+	if !p.AllowInsecure && !info.IsSecure {
+		violation.Reason = InsecureAlgorithmPolicyViolation
+		return p.report(violation)
+	}
+
+	if keyLength >= 0 && keyLength < p.MinSymmKeyLength {
+		violation.Reason = KeyTooShortPolicyViolation
+		return p.report(violation)
+	}
+
+	if p.RejectDeprecated && (op == KeyGenerationPolicyOperation || op == MakeEncryptorPolicyOperation) {
+		name, ok := p.findName(algo)
+		if ok {
+			registryInfo, infoErr := p.getRegistry().AlgorithmInfo(name)
+			if infoErr == nil && registryInfo.Deprecated {
+				violation.Info = registryInfo
+				violation.Reason = DeprecatedAlgorithmPolicyViolation
+				return p.report(violation)
+			}
+		}
+	}
+	return
+}
+
+// IsInsecureTainted returns true if SetInsecureTaint is set and some insecure algorithm was used with this context.
+func (ctx *Context) IsInsecureTainted() bool {
+	return ctx != nil && atomic.LoadInt32(&ctx.getTaintRoot().insecureTainted) != 0
+}
+
+// contextCheckPolicy checks context's policy and sets insecure taint if needed.
+// keyLength is length of symmetric key or negative value if there is no such key.
+func contextCheckPolicy(ctx AnyContext, op PolicyOperation, algo interface{}, keyLength int) (err error) {
+	if ctx == nil {
+		return
+	}
+
+	if ctx.Policy != nil {
+		err = ctx.Policy.Check(op, algo, keyLength)
+		if err != nil {
+			return
+		}
+	}
+
+	if ctx.SetInsecureTaint {
+		info, infoErr := GetAlgorithmInfo(algo)
+		if infoErr == nil && !info.IsSecure {
+			atomic.StoreInt32(&ctx.getTaintRoot().insecureTainted, 1)
+		}
+	}
+	return
+}
+
+// contextForInnerAlgorithm returns context, which should be passed to algorithms wrapped by other algorithm.
+// Wrapping algorithm is checked against allowlist on its own, so inner algorithms do not have to be in allowlist.
+// Other policy rules still apply to them.
+//
+// Returned context shares insecure taint with given one.
+func contextForInnerAlgorithm(ctx AnyContext) AnyContext {
+	if ctx == nil || ctx.Policy == nil || len(ctx.Policy.AllowedAlgorithms) == 0 {
+		return ctx
+	}
+
+	policy := *ctx.Policy
+	policy.AllowedAlgorithms = nil
+
+	res := Context{
+		RNG:              ctx.RNG,
+		SetInsecureTaint: ctx.SetInsecureTaint,
+		Policy:           &policy,
+		LockKeyMemory:    ctx.LockKeyMemory,
+		taintRoot:        ctx.getTaintRoot(),
+	}
+	return &res
+}
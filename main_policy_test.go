@@ -0,0 +1,315 @@
+package crypka_test
+
+import (
+	"crypto"
+	"errors"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+func TestPolicy_RejectsInsecure(t *testing.T) {
+	var violations []crypka.PolicyViolation
+	ctx := &crypka.Context{
+		Policy: &crypka.Policy{
+			OnViolation: func(violation crypka.PolicyViolation) {
+				violations = append(violations, violation)
+			},
+		},
+	}
+
+	xor := &crypka.XorEncSymmAlgo{GenerateKeyLength: 16, MinKeyLength: 1, MaxKeyLength: 64}
+
+	_, err := xor.GenerateKey(ctx, nil)
+	if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+		t.Error("expected policy error, got", err)
+		return
+	}
+
+	_, err = xor.ParseSymmEncKey(ctx, make([]byte, 16))
+	if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+		t.Error("expected policy error, got", err)
+		return
+	}
+
+	// key created without policy can't be used with context, which has one
+	key, err := xor.GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = key.MakeEncryptor(ctx)
+	if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+		t.Error("expected policy error, got", err)
+		return
+	}
+	_, err = key.MakeDecryptor(ctx)
+	if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+		t.Error("expected policy error, got", err)
+		return
+	}
+
+	_, err = (&crypka.BlankEncSymmAlgo{}).GenerateKey(ctx, nil)
+	if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+		t.Error("expected policy error, got", err)
+		return
+	}
+
+	_, err = (&crypka.MathRNGAlgo{}).MakeRng(ctx, make([]byte, 8))
+	if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+		t.Error("expected policy error, got", err)
+		return
+	}
+
+	if len(violations) != 6 {
+		t.Error("invalid violation count", len(violations))
+		return
+	}
+	for _, v := range violations {
+		if v.Reason != crypka.InsecureAlgorithmPolicyViolation {
+			t.Error("invalid violation reason", v.Reason)
+			return
+		}
+	}
+	if violations[2].Operation != crypka.MakeEncryptorPolicyOperation {
+		t.Error("invalid violation operation", violations[2].Operation)
+		return
+	}
+
+	var aesAlgo crypka.EncSymmAlgo
+	err = crypka.GlobalRegistry.GetAlgorithmTyped("aes-128-gcm-rng", &aesAlgo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = aesAlgo.GenerateKey(ctx, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestPolicy_MinSymmKeyLength(t *testing.T) {
+	ctx := &crypka.Context{
+		Policy: &crypka.Policy{
+			// HMAC with MinKeyLength less than 32 is considered insecure
+			AllowInsecure:    true,
+			MinSymmKeyLength: 32,
+		},
+	}
+
+	for _, name := range []string{"aes-128-gcm-counter", "cpk-stream-aes-128-gcm-counter", "aes-128-kw"} {
+		algo, err := crypka.GetTyped[crypka.EncSymmAlgo](nil, name)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = algo.GenerateKey(ctx, nil)
+		if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+			t.Error("expected policy error for", name, "got", err)
+			return
+		}
+	}
+
+	for _, name := range []string{"aes-256-gcm-counter", "cpk-stream-aes-256-gcm-counter", "aes-256-kw"} {
+		algo, err := crypka.GetTyped[crypka.EncSymmAlgo](nil, name)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = algo.GenerateKey(ctx, nil)
+		if err != nil {
+			t.Error(name, err)
+			return
+		}
+	}
+
+	hmacAlgo := &crypka.HMACSignAlgorithm{Hash: crypto.SHA256, GenKeyLength: 16}
+	_, err := hmacAlgo.GenerateKey(ctx, nil)
+	if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+		t.Error("expected policy error, got", err)
+		return
+	}
+	_, err = hmacAlgo.ParseSymmSignKey(ctx, make([]byte, 31))
+	if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+		t.Error("expected policy error, got", err)
+		return
+	}
+	_, err = hmacAlgo.ParseSymmSignKey(ctx, make([]byte, 32))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestHMAC_GenKeyLengthBelowMin(t *testing.T) {
+	_, err := (&crypka.HMACSignAlgorithm{Hash: crypto.SHA256, MinKeyLength: 32, GenKeyLength: 16}).GenerateKey(nil, nil)
+	if !errors.Is(err, crypka.ErrKeyGenerationInvalidLength) {
+		t.Error("expected invalid length error, got", err)
+		return
+	}
+}
+
+func TestPolicy_Allowlist(t *testing.T) {
+	reg := crypka.NewRegistry()
+	crypka.RegisterDefaults(reg)
+
+	var violations []crypka.PolicyViolation
+	ctx := &crypka.Context{
+		Policy: &crypka.Policy{
+			AllowedAlgorithms: []string{"x25519-aes-256-gcm-counter", "cpk-stream-aes-256-gcm-counter"},
+			Registry:          reg,
+			OnViolation: func(violation crypka.PolicyViolation) {
+				violations = append(violations, violation)
+			},
+		},
+	}
+
+	// wrapping algorithms are allowed, even though algorithms they wrap are not listed
+	asymAlgo, err := crypka.GetTyped[crypka.EncAsymAlgo](reg, "x25519-aes-256-gcm-counter")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ek, dk, err := asymAlgo.GenerateKeyPair(ctx, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = checkEncRoundTrip(ek, dk, []byte("some data"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	streamAlgo, err := crypka.GetTyped[crypka.EncSymmAlgo](reg, "cpk-stream-aes-256-gcm-counter")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, err := streamAlgo.GenerateKey(ctx, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = key.MakeEncryptor(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// but they can't be used directly
+	aesAlgo, err := crypka.GetTyped[crypka.EncSymmAlgo](reg, "aes-256-gcm-counter")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = aesAlgo.GenerateKey(ctx, nil)
+	if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+		t.Error("expected policy error, got", err)
+		return
+	}
+
+	// same algorithm, which is not from registry, is not allowed as well
+	_, err = (&crypka.X25519KXAlgo{}).ParseKXPublic(ctx, make([]byte, 32))
+	if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+		t.Error("expected policy error, got", err)
+		return
+	}
+
+	if len(violations) != 2 {
+		t.Error("invalid violation count", len(violations))
+		return
+	}
+	if violations[0].Reason != crypka.AlgorithmNotAllowedPolicyViolation {
+		t.Error("invalid violation reason", violations[0].Reason)
+		return
+	}
+}
+
+func TestPolicy_AuditOnlyAndTaint(t *testing.T) {
+	violationCount := 0
+	ctx := &crypka.Context{
+		SetInsecureTaint: true,
+		Policy: &crypka.Policy{
+			AuditOnly: true,
+			OnViolation: func(violation crypka.PolicyViolation) {
+				violationCount++
+			},
+		},
+	}
+
+	if ctx.IsInsecureTainted() {
+		t.Error("expected context not to be tainted")
+		return
+	}
+
+	_, err := (&crypka.XorEncSymmAlgo{GenerateKeyLength: 16}).GenerateKey(ctx, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if violationCount != 1 {
+		t.Error("expected violation to be reported")
+		return
+	}
+	if !ctx.IsInsecureTainted() {
+		t.Error("expected context to be tainted")
+		return
+	}
+}
+
+// mathSeededKXAlgo claims to be secure, but it uses insecure math RNG with context it's given.
+type mathSeededKXAlgo struct {
+	crypka.X25519KXAlgo
+}
+
+func (algo *mathSeededKXAlgo) GenerateKXPair(ctx crypka.KeyGenerationContext, rng crypka.RNG) (public crypka.KXPublic, secret crypka.KXSecret, err error) {
+	_, err = (&crypka.MathRNGAlgo{}).MakeRng(ctx, make([]byte, 8))
+	if err != nil {
+		return
+	}
+	return algo.X25519KXAlgo.GenerateKXPair(ctx, rng)
+}
+
+func TestPolicy_AllowlistTaintsInner(t *testing.T) {
+	var symmAlgo crypka.EncSymmAlgo
+	err := crypka.GlobalRegistry.GetAlgorithmTyped("aes-256-gcm-rng", &symmAlgo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	reg := crypka.NewRegistry()
+	reg.RegisterAlgo("math-seeded-kx-aes-256-gcm-rng", &crypka.EncAsymKXAlgo{
+		EncSymmAlgo: symmAlgo,
+		KXAlgo:      &mathSeededKXAlgo{},
+	})
+
+	ctx := &crypka.Context{
+		SetInsecureTaint: true,
+		Policy: &crypka.Policy{
+			AllowInsecure:     true,
+			AllowedAlgorithms: []string{"math-seeded-kx-aes-256-gcm-rng"},
+			Registry:          reg,
+		},
+	}
+
+	algo, err := crypka.GetTyped[crypka.EncAsymAlgo](reg, "math-seeded-kx-aes-256-gcm-rng")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, _, err = algo.GenerateKeyPair(ctx, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// insecure algorithm was used by inner algorithm only, but it has to taint context given by user
+	if !ctx.IsInsecureTainted() {
+		t.Error("expected context to be tainted")
+		return
+	}
+}
@@ -2,8 +2,8 @@ package crypka
 
 import "crypto/subtle"
 
-// Note: for now paddings are not handled using algo registry.
-// This may change in future
+// Note: Padding fills buffer of size given by caller, so it's not handled using algo registry.
+// PaddingAlgo is the one, which is registered, since it also chooses size of padded message.
 
 // Padder is something able to apply padding to message.
 type Padder interface {
@@ -32,6 +32,7 @@ func (f UnpadderFunc) Unpad(data []byte) (msgSize int) {
 	return f(data)
 }
 
+// Padding is Padder and Unpadder of same scheme.
 type Padding interface {
 	Padder
 	Unpadder
@@ -42,9 +43,9 @@ type compositePadding struct {
 	Unpadder
 }
 
-// TOOD(teawihtsand): debug why this is not constant time
-//  at least looks like so, when benchmark are in use
-//  write these in asm?
+// Unpad is written without branches and memory accesses, which depend on contents of padded message,
+// so its execution time should depend on length of padded message only.
+// Go compiler makes no promises about that, so it's tested by TestPad_IEC78164_UnpadTiming rather than guaranteed.
 
 var iec78164Padding Padding = &compositePadding{
 	// Padder padds fills buf[msgSize:] with padding.
@@ -87,3 +88,37 @@ var iec78164Padding Padding = &compositePadding{
 func PaddingIEC78164() Padding {
 	return iec78164Padding
 }
+
+type PaddingAlgoInfo struct {
+	BaseAlgorithmInfo
+
+	// If true, then size of padded message depends on RNG and not only on size of message.
+	IsRandomized bool
+}
+
+// PaddingAlgo pads messages, so that their lengths leak less information.
+// Unlike Padding, it chooses size of padded message on its own.
+type PaddingAlgo interface {
+	GetInfo() PaddingAlgoInfo
+
+	// PadMessage appends padded message to appendTo.
+	// RNG is used by randomized paddings only. If it's nil, then crypto/rand is used.
+	PadMessage(rng RNG, msg, appendTo []byte) (res []byte, err error)
+
+	// UnpadMessage returns message, which is slice of padded one.
+	// Returns ErrPaddingInvalid if padding is not valid.
+	UnpadMessage(padded []byte) (msg []byte, err error)
+}
+
+// Registers built-in paddings as "pad-iec78164", "pad-pkcs7", "pad-padme" and "pad-random-bucket".
+// Block paddings use 16 byte blocks. Random bucket padding uses 256 byte buckets and adds up to 4 random ones.
+func RegisterPaddings(reg Registry) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	registerDefault(reg, "pad-iec78164", &IEC78164PaddingAlgo{BlockSize: 16})
+	registerDefault(reg, "pad-pkcs7", &PKCS7PaddingAlgo{BlockSize: 16})
+	registerDefault(reg, "pad-padme", &PADMEPaddingAlgo{})
+	registerDefault(reg, "pad-random-bucket", &RandomBucketPaddingAlgo{BucketSize: 256, MaxExtraBuckets: 4})
+}
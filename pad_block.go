@@ -0,0 +1,129 @@
+package crypka
+
+import "crypto/subtle"
+
+const defaultPaddingBlockSize = 16
+
+// appendIEC78164 appends message padded with ISO/IEC 7816-4 padding to given size, which must be greater than size of message.
+func appendIEC78164(appendTo, msg []byte, size int) []byte {
+	res := append(appendTo, msg...)
+	res = append(res, make([]byte, size-len(msg))...)
+	iec78164Padding.Pad(res[len(appendTo):], len(msg))
+	return res
+}
+
+func unpadIEC78164(padded []byte) (msg []byte, err error) {
+	sz := iec78164Padding.Unpad(padded)
+	if sz < 0 {
+		err = ErrPaddingInvalid
+		return
+	}
+	msg = padded[:sz]
+	return
+}
+
+// IEC78164PaddingAlgo pads message with ISO/IEC 7816-4 padding to multiple of block size.
+// There is always at least one byte of padding.
+type IEC78164PaddingAlgo struct {
+	// Defaults to 16.
+	BlockSize int
+}
+
+func (algo *IEC78164PaddingAlgo) getBlockSize() int {
+	if algo.BlockSize > 0 {
+		return algo.BlockSize
+	}
+	return defaultPaddingBlockSize
+}
+
+func (algo *IEC78164PaddingAlgo) GetInfo() PaddingAlgoInfo {
+	return PaddingAlgoInfo{
+		BaseAlgorithmInfo: BaseAlgorithmInfo{
+			Type:     PaddingAlgorithmType,
+			IsSecure: true,
+		},
+	}
+}
+
+func (algo *IEC78164PaddingAlgo) PadMessage(rng RNG, msg, appendTo []byte) (res []byte, err error) {
+	bs := algo.getBlockSize()
+	res = appendIEC78164(appendTo, msg, (len(msg)/bs+1)*bs)
+	return
+}
+
+func (algo *IEC78164PaddingAlgo) UnpadMessage(padded []byte) (msg []byte, err error) {
+	if len(padded) == 0 || len(padded)%algo.getBlockSize() != 0 {
+		err = ErrPaddingInvalid
+		return
+	}
+	return unpadIEC78164(padded)
+}
+
+// PKCS7PaddingAlgo pads message to multiple of block size as described in RFC 5652.
+// Each byte of padding is equal to count of padding bytes, so block size must not be greater than 255.
+//
+// Padding is checked in constant time, which depends on block size only.
+type PKCS7PaddingAlgo struct {
+	// Defaults to 16.
+	BlockSize int
+}
+
+func (algo *PKCS7PaddingAlgo) getBlockSize() (bs int, err error) {
+	bs = algo.BlockSize
+	if bs == 0 {
+		bs = defaultPaddingBlockSize
+	}
+	if bs < 1 || bs > 255 {
+		err = ErrPaddingInvalid
+	}
+	return
+}
+
+func (algo *PKCS7PaddingAlgo) GetInfo() PaddingAlgoInfo {
+	return PaddingAlgoInfo{
+		BaseAlgorithmInfo: BaseAlgorithmInfo{
+			Type:     PaddingAlgorithmType,
+			IsSecure: true,
+		},
+	}
+}
+
+func (algo *PKCS7PaddingAlgo) PadMessage(rng RNG, msg, appendTo []byte) (res []byte, err error) {
+	bs, err := algo.getBlockSize()
+	if err != nil {
+		return
+	}
+
+	n := bs - len(msg)%bs
+	res = append(appendTo, msg...)
+	for i := 0; i < n; i++ {
+		res = append(res, byte(n))
+	}
+	return
+}
+
+func (algo *PKCS7PaddingAlgo) UnpadMessage(padded []byte) (msg []byte, err error) {
+	bs, err := algo.getBlockSize()
+	if err != nil {
+		return
+	}
+
+	if len(padded) == 0 || len(padded)%bs != 0 {
+		err = ErrPaddingInvalid
+		return
+	}
+
+	n := int(padded[len(padded)-1])
+	ok := subtle.ConstantTimeLessOrEq(1, n) & subtle.ConstantTimeLessOrEq(n, bs)
+	for i := 1; i <= bs; i++ {
+		isPadding := subtle.ConstantTimeLessOrEq(i, n)
+		ok &= subtle.ConstantTimeSelect(isPadding, subtle.ConstantTimeByteEq(padded[len(padded)-i], byte(n)), 1)
+	}
+
+	if ok != 1 {
+		err = ErrPaddingInvalid
+		return
+	}
+	msg = padded[:len(padded)-n]
+	return
+}
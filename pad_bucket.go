@@ -0,0 +1,65 @@
+package crypka
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+const defaultPaddingBucketSize = 256
+
+// RandomBucketPaddingAlgo pads message to multiple of bucket size and then adds random count of extra buckets,
+// so that messages of same size yield padded messages of different sizes.
+//
+// End of message is marked with ISO/IEC 7816-4 padding, so there is always at least one byte of padding.
+type RandomBucketPaddingAlgo struct {
+	// Defaults to 256.
+	BucketSize int
+
+	// Max count of extra buckets, which is chosen uniformly from [0, MaxExtraBuckets].
+	// If zero, then padding is deterministic.
+	MaxExtraBuckets int
+}
+
+func (algo *RandomBucketPaddingAlgo) getBucketSize() int {
+	if algo.BucketSize > 0 {
+		return algo.BucketSize
+	}
+	return defaultPaddingBucketSize
+}
+
+func (algo *RandomBucketPaddingAlgo) GetInfo() PaddingAlgoInfo {
+	return PaddingAlgoInfo{
+		BaseAlgorithmInfo: BaseAlgorithmInfo{
+			Type:     PaddingAlgorithmType,
+			IsSecure: true,
+		},
+		IsRandomized: algo.MaxExtraBuckets > 0,
+	}
+}
+
+func (algo *RandomBucketPaddingAlgo) PadMessage(rng RNG, msg, appendTo []byte) (res []byte, err error) {
+	bs := algo.getBucketSize()
+	buckets := len(msg)/bs + 1
+
+	if algo.MaxExtraBuckets > 0 {
+		var buf [8]byte
+		_, err = io.ReadFull(FallbackContextGetRNG(nil, rng), buf[:])
+		if err != nil {
+			return
+		}
+
+		// modulo bias is negligible, since there are only few extra buckets
+		buckets += int(binary.LittleEndian.Uint64(buf[:]) % uint64(algo.MaxExtraBuckets+1))
+	}
+
+	res = appendIEC78164(appendTo, msg, buckets*bs)
+	return
+}
+
+func (algo *RandomBucketPaddingAlgo) UnpadMessage(padded []byte) (msg []byte, err error) {
+	if len(padded) == 0 || len(padded)%algo.getBucketSize() != 0 {
+		err = ErrPaddingInvalid
+		return
+	}
+	return unpadIEC78164(padded)
+}
@@ -0,0 +1,51 @@
+package crypka
+
+import "math/bits"
+
+// padmeSize returns result of PADMÉ function for given size, which must be positive.
+func padmeSize(size int) int {
+	e := bits.Len(uint(size)) - 1
+	s := bits.Len(uint(e))
+	lastBits := e - s
+	if lastBits <= 0 {
+		return size
+	}
+
+	mask := 1<<lastBits - 1
+	return (size + mask) &^ mask
+}
+
+// PADMEPaddingAlgo pads message to size given by PADMÉ function from
+// "Reducing Metadata Leakage from Encrypted Files and Communication with PURBs".
+// Padded size leaks O(log log n) bits of size of message and overhead is at most 12%.
+//
+// End of message is marked with ISO/IEC 7816-4 padding, so PADMÉ is applied to size of message plus one.
+type PADMEPaddingAlgo struct{}
+
+func (algo *PADMEPaddingAlgo) GetInfo() PaddingAlgoInfo {
+	return PaddingAlgoInfo{
+		BaseAlgorithmInfo: BaseAlgorithmInfo{
+			Type:     PaddingAlgorithmType,
+			IsSecure: true,
+		},
+	}
+}
+
+func (algo *PADMEPaddingAlgo) PadMessage(rng RNG, msg, appendTo []byte) (res []byte, err error) {
+	res = appendIEC78164(appendTo, msg, padmeSize(len(msg)+1))
+	return
+}
+
+func (algo *PADMEPaddingAlgo) UnpadMessage(padded []byte) (msg []byte, err error) {
+	msg, err = unpadIEC78164(padded)
+	if err != nil {
+		return
+	}
+
+	if padmeSize(len(msg)+1) != len(padded) {
+		msg = nil
+		err = ErrPaddingInvalid
+		return
+	}
+	return
+}
@@ -2,10 +2,13 @@ package crypka_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/teawithsand/crypka"
+	"github.com/teawithsand/crypka/crypkatest"
 )
 
 func TestCanPadMessages(t *testing.T) {
@@ -49,3 +52,135 @@ func TestCanUnpadMessages(t *testing.T) {
 	assert([]byte{0xaa}, -1)
 	assert([]byte{0xaa, 0xaa, 0xbb}, -1)
 }
+
+func TestPad_IEC78164_UnpadTiming(t *testing.T) {
+	padding := crypka.PaddingIEC78164()
+
+	// position of padding must not leak, only length of padded message may
+	tester := crypkatest.TimingTester{
+		Prepare: func(class int, rng crypka.RNG) (input []byte, err error) {
+			input = make([]byte, 256)
+			_, err = io.ReadFull(rng, input)
+			if err != nil {
+				return
+			}
+
+			msgSize := 0
+			if class == 1 {
+				msgSize = len(input) - 1
+			}
+			input = padding.Pad(input, msgSize)
+			return
+		},
+		Run: func(input []byte) {
+			padding.Unpad(input)
+		},
+	}
+	tester.Test(t)
+}
+
+func TestPad_IEC78164Algo(t *testing.T) {
+	tester := crypkatest.PaddingTester{
+		Algo: &crypka.IEC78164PaddingAlgo{},
+	}
+	tester.Test(t)
+}
+
+func TestPad_PKCS7(t *testing.T) {
+	for _, bs := range []int{1, 8, 16, 255} {
+		tester := crypkatest.PaddingTester{
+			Algo: &crypka.PKCS7PaddingAlgo{BlockSize: bs},
+		}
+		t.Run(fmt.Sprintf("block_%d", bs), tester.Test)
+	}
+}
+
+func TestPad_PKCS7_InvalidBlockSize(t *testing.T) {
+	_, err := (&crypka.PKCS7PaddingAlgo{BlockSize: 256}).PadMessage(nil, []byte("asdf"), nil)
+	if !errors.Is(err, crypka.ErrPaddingInvalid) {
+		t.Error("expected block size to be rejected, got", err)
+	}
+}
+
+func TestPad_PKCS7_UnpadTiming(t *testing.T) {
+	padding := &crypka.PKCS7PaddingAlgo{}
+
+	// count of padding bytes must not leak
+	tester := crypkatest.TimingTester{
+		Prepare: func(class int, rng crypka.RNG) (input []byte, err error) {
+			msgSize := 31
+			if class == 1 {
+				msgSize = 16
+			}
+			msg := make([]byte, msgSize)
+			_, err = io.ReadFull(rng, msg)
+			if err != nil {
+				return
+			}
+			return padding.PadMessage(nil, msg, nil)
+		},
+		Run: func(input []byte) {
+			padding.UnpadMessage(input)
+		},
+	}
+	tester.Test(t)
+}
+
+func TestPad_PADME(t *testing.T) {
+	tester := crypkatest.PaddingTester{
+		Algo: &crypka.PADMEPaddingAlgo{},
+	}
+	tester.Test(t)
+}
+
+func TestPad_PADME_Overhead(t *testing.T) {
+	padding := &crypka.PADMEPaddingAlgo{}
+	for sz := 0; sz < 1<<16; sz += 97 {
+		padded, err := padding.PadMessage(nil, make([]byte, sz), nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		// overhead is at most 12% of size of message and marker byte
+		if float64(len(padded)) > float64(sz+1)*1.12 {
+			t.Error("too big overhead for", sz, "padded size is", len(padded))
+			return
+		}
+	}
+}
+
+func TestPad_RandomBucket(t *testing.T) {
+	tester := crypkatest.PaddingTester{
+		Algo: &crypka.RandomBucketPaddingAlgo{
+			BucketSize:      64,
+			MaxExtraBuckets: 3,
+		},
+	}
+	tester.Test(t)
+}
+
+func TestPad_RandomBucket_SizesVary(t *testing.T) {
+	padding := &crypka.RandomBucketPaddingAlgo{
+		BucketSize:      64,
+		MaxExtraBuckets: 3,
+	}
+
+	sizes := map[int]bool{}
+	for i := 0; i < 100; i++ {
+		padded, err := padding.PadMessage(nil, []byte("some message"), nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(padded)%64 != 0 || len(padded) > 4*64 {
+			t.Error("invalid padded size", len(padded))
+			return
+		}
+		sizes[len(padded)] = true
+	}
+
+	if len(sizes) != 4 {
+		t.Error("expected all bucket counts to be used, got sizes", sizes)
+	}
+}
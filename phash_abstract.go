@@ -1,8 +1,14 @@
 package crypka
 
+import "io"
+
 type PHashAlgoInfo struct {
 	Name   string
 	Secure bool
+
+	// Amount of memory in bytes, which is required by single hash operation.
+	// Zero if unknown.
+	MemoryCost uint64
 }
 
 // PHasher are special kind of hashes.
@@ -14,8 +20,23 @@ type PHasher interface {
 }
 
 type PHash interface {
-	GetAlgo() string // returns name of hashing algorithm used
-	Raw() []byte     // returns encoded form of hash, so if it was parsed again, it would yield same result
+	GetAlgo() string              // returns name of hashing algorithm used
+	Raw() (res []byte, err error) // returns encoded form of hash, so if it was parsed again, it would yield same result
+}
+
+// BinaryPHash is PHash, which can be encoded both as PHC string and in binary modular crypt format(BMCF).
+// Conversion between these forms must be lossless.
+type BinaryPHash interface {
+	PHash
+
+	// Load parses hash from PHC string.
+	Load(r io.ByteReader) (err error)
+
+	// RawBinary returns BMCF encoded form of hash, including scheme identifier byte.
+	RawBinary(appendTo []byte) (res []byte, err error)
+
+	// LoadBinary parses BMCF encoded hash, including scheme identifier byte.
+	LoadBinary(data []byte) (err error)
 }
 
 /*
@@ -3,35 +3,217 @@ package crypka
 import (
 	"bytes"
 	"crypto/hmac"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+)
+
+const (
+	argon2MinSaltLength = 8
+	argon2MinHashLength = 4
+	argon2MaxKeyID      = 8
+	argon2MaxData       = 32
+
+	// 4GiB, in KiB units used by memory parameter.
+	// It prevents parsed hashes from making CheckPassword allocate arbitrary amounts of memory.
+	argon2MaxMemory = 4 * 1024 * 1024
 
-	"golang.org/x/crypto/argon2"
+	// PHC string for argon2 has at most 5 segments: name, version, params, salt and hash
+	argon2MaxSegments = 5
 )
 
-// PHCPasswordHash encoded in PHC format
+// PHCPasswordHash encoded in PHC format.
+//
+// Name is one of argon2id, argon2i or argon2d.
 type Argon2PasswordHash struct {
 	Name string
 	Salt []byte
 	Hash []byte
 
+	// Version of argon2 used. PHC string without version corresponds to 0x10.
 	Version int
 
 	Time    uint32
 	Memory  uint32
 	Threads uint8
+
+	// Optional identifier of secret used to compute hash.
+	KeyID []byte
+
+	// Optional associated data, which was used to compute hash.
+	Data []byte
+}
+
+// parsePHCDecimal parses decimal in the form required by PHC string format:
+// no sign, no leading zeroes, no whitespaces.
+func parsePHCDecimal(value []byte, bitSize int) (res uint64, err error) {
+	if len(value) == 0 || (len(value) > 1 && value[0] == '0') {
+		err = ErrPasswordHashParseFiled
+		return
+	}
+	for _, b := range value {
+		if b < '0' || b > '9' {
+			err = ErrPasswordHashParseFiled
+			return
+		}
+	}
+
+	res, err = strconv.ParseUint(string(value), 10, bitSize)
+	if err != nil {
+		err = ErrPasswordHashParseFiled
+		return
+	}
+	return
+}
+
+func (h *Argon2PasswordHash) validate() (err error) {
+	_, ok := argon2ModeFromName(h.Name)
+	if !ok {
+		err = ErrPasswordHashUnknownAlgo
+		return
+	}
+
+	if h.Version != argon2Version10 && h.Version != argon2Version13 {
+		err = ErrPasswordHashUnknownAlgo
+		return
+	}
+
+	if h.Time < 1 ||
+		h.Threads < 1 ||
+		uint64(h.Memory) < 8*uint64(h.Threads) ||
+		h.Memory > argon2MaxMemory ||
+		len(h.Salt) < argon2MinSaltLength ||
+		len(h.Hash) < argon2MinHashLength ||
+		len(h.KeyID) > argon2MaxKeyID ||
+		len(h.Data) > argon2MaxData {
+		err = ErrPasswordHashParseFiled
+		return
+	}
+
+	return
+}
+
+func (h *Argon2PasswordHash) loadVersion(segment []byte) (err error) {
+	pp := argParser{
+		R: bytes.NewReader(segment),
+	}
+
+	err = pp.Next()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = ErrPasswordHashParseFiled
+		}
+		return
+	}
+	if string(pp.Name) != "v" {
+		err = ErrPasswordHashParseFiled
+		return
+	}
+
+	version, err := parsePHCDecimal(pp.Value, 32)
+	if err != nil {
+		return
+	}
+	h.Version = int(version)
+
+	err = pp.Next()
+	if !errors.Is(err, io.EOF) {
+		err = ErrPasswordHashParseFiled
+		return
+	}
+	err = nil
+
+	return
 }
 
+func (h *Argon2PasswordHash) loadParams(segment []byte) (err error) {
+	pp := argParser{
+		R: bytes.NewReader(segment),
+	}
+
+	// Params must appear in this order; first three are required.
+	names := []string{"m", "t", "p", "keyid", "data"}
+	const requiredCount = 3
+
+	nameIndex := 0
+	for {
+		err = pp.Next()
+		if errors.Is(err, io.EOF) {
+			err = nil
+			break
+		} else if err != nil {
+			return
+		}
+
+		// skip optional params, which are not present, so order and duplicates are checked at once
+		for nameIndex < len(names) && names[nameIndex] != string(pp.Name) {
+			if nameIndex < requiredCount {
+				err = ErrPasswordHashParseFiled
+				return
+			}
+			nameIndex++
+		}
+		if nameIndex >= len(names) {
+			// either unknown, duplicated or out-of-order parameter
+			err = ErrPasswordHashParseFiled
+			return
+		}
+
+		var value uint64
+		switch names[nameIndex] {
+		case "m":
+			value, err = parsePHCDecimal(pp.Value, 32)
+			h.Memory = uint32(value)
+		case "t":
+			value, err = parsePHCDecimal(pp.Value, 32)
+			h.Time = uint32(value)
+		case "p":
+			value, err = parsePHCDecimal(pp.Value, 8)
+			h.Threads = uint8(value)
+		case "keyid":
+			h.KeyID, err = passwordBase64Encoding.DecodeString(string(pp.Value))
+		case "data":
+			h.Data, err = passwordBase64Encoding.DecodeString(string(pp.Value))
+		}
+		if err != nil {
+			err = ErrPasswordHashParseFiled
+			return
+		}
+
+		nameIndex++
+	}
+
+	if nameIndex < requiredCount {
+		err = ErrPasswordHashParseFiled
+		return
+	}
+
+	return
+}
+
+// Load parses hash in PHC string format.
+// Parser is strict: it rejects unknown, duplicated and reordered parameters,
+// non-canonical numbers and non-canonical base64.
 func (dh *Argon2PasswordHash) Load(r io.ByteReader) (err error) {
+	return dh.load(r, passwordBase64Encoding)
+}
+
+// LoadLegacy parses hash like Load does, but salt and hash have to be encoded with alphabet used by older crypka versions.
+// It never accepts standard alphabet, so caller has to know, which hashes were created by older versions.
+// Raw always encodes loaded hash with standard alphabet, so it can be used to migrate stored hashes.
+func (dh *Argon2PasswordHash) LoadLegacy(r io.ByteReader) (err error) {
+	return dh.load(r, legacyPasswordBase64Encoding)
+}
+
+func (dh *Argon2PasswordHash) load(r io.ByteReader, encoding *base64.Encoding) (err error) {
 	p := bmcParser{
 		R: r,
 	}
 
-	h := Argon2PasswordHash{}
-
-	state := 0
+	var segments [][]byte
 	for {
 		err = p.Next()
 		if errors.Is(err, io.EOF) {
@@ -41,83 +223,65 @@ func (dh *Argon2PasswordHash) Load(r io.ByteReader) (err error) {
 			return
 		}
 
-		if state == 0 {
-			h.Name = string(p.Value)
-			state = 1
-		} else if state == 1 || state == 2 {
-			pp := argParser{
-				R: bytes.NewReader(p.Value),
-			}
-			for {
-				err = pp.Next()
-				if errors.Is(err, io.EOF) {
-					err = nil
-					break
-				} else if err != nil {
-					return
-				}
-
-				if string(pp.Name) == "v" {
-					_, err = fmt.Sscanf(string(pp.Value), "%d", &h.Version)
-					if err != nil {
-						return
-					}
-				} else if string(pp.Name) == "m" {
-					_, err = fmt.Sscanf(string(pp.Value), "%d", &h.Memory)
-					if err != nil {
-						return
-					}
-				} else if string(pp.Name) == "t" {
-					_, err = fmt.Sscanf(string(pp.Value), "%d", &h.Time)
-					if err != nil {
-						return
-					}
-				} else if string(pp.Name) == "p" {
-					_, err = fmt.Sscanf(string(pp.Value), "%d", &h.Threads)
-					if err != nil {
-						return
-					}
-				} else {
-					// ignore unknown param
-				}
-			}
+		if len(p.Value) == 0 || len(segments) >= argon2MaxSegments {
+			err = ErrPasswordHashParseFiled
+			return
+		}
+		segments = append(segments, p.Value)
+	}
 
-			// TODO(teawithsand): check against parsing unknown parametrs
-			// TODO(teawithsand): check against parameter regrouping, for now this parser is quite la
+	h := Argon2PasswordHash{
+		Version: argon2Version10,
+	}
 
-			state += 1
-		} else if state == 3 {
-			var salt []byte
-			salt, err = passwordBase64Encoding.DecodeString(string(p.Value))
-			if err != nil {
-				err = ErrPasswordHashParseFiled
-				return
-			}
+	if len(segments) == 0 {
+		err = ErrPasswordHashParseFiled
+		return
+	}
+	h.Name = string(segments[0])
+	segments = segments[1:]
 
-			h.Salt = salt
-			state = 4
-		} else if state == 4 {
-			var hash []byte
-			hash, err = passwordBase64Encoding.DecodeString(string(p.Value))
-			if err != nil {
-				err = ErrPasswordHashParseFiled
-				return
-			}
+	_, ok := argon2ModeFromName(h.Name)
+	if !ok {
+		err = ErrPasswordHashUnknownAlgo
+		return
+	}
 
-			h.Hash = hash
-			state = 5
-		} else {
-			// never called when valid encoding
-			err = ErrPasswordHashParseFiled
+	if len(segments) > 0 && bytes.HasPrefix(segments[0], []byte("v=")) {
+		err = h.loadVersion(segments[0])
+		if err != nil {
 			return
 		}
+		segments = segments[1:]
 	}
 
-	if state != 5 {
+	if len(segments) != 3 {
 		err = ErrPasswordHashParseFiled
-	} else {
-		*dh = h
+		return
+	}
+
+	err = h.loadParams(segments[0])
+	if err != nil {
+		return
+	}
+
+	h.Salt, err = encoding.DecodeString(string(segments[1]))
+	if err != nil {
+		err = ErrPasswordHashParseFiled
+		return
+	}
+	h.Hash, err = encoding.DecodeString(string(segments[2]))
+	if err != nil {
+		err = ErrPasswordHashParseFiled
+		return
 	}
+
+	err = h.validate()
+	if err != nil {
+		return
+	}
+
+	*dh = h
 	return
 }
 
@@ -126,18 +290,29 @@ func (h *Argon2PasswordHash) GetAlgo() string {
 }
 
 func (h *Argon2PasswordHash) encodeParams() string {
-	return strings.Join([]string{
+	params := []string{
 		fmt.Sprintf("m=%d", h.Memory),
 		fmt.Sprintf("t=%d", h.Time),
 		fmt.Sprintf("p=%d", h.Threads),
-	}, ",")
+	}
+	if len(h.KeyID) > 0 {
+		params = append(params, "keyid="+passwordBase64Encoding.EncodeToString(h.KeyID))
+	}
+	if len(h.Data) > 0 {
+		params = append(params, "data="+passwordBase64Encoding.EncodeToString(h.Data))
+	}
+	return strings.Join(params, ",")
 }
 
 func (h *Argon2PasswordHash) Raw() (res []byte, err error) {
 	w := bytes.NewBuffer(nil)
 	wr := bmcWriter{w}
 	wr.WriteParam(h.Name)
-	wr.WriteParam(fmt.Sprintf("v=%d", h.Version))
+
+	// version 0x10 is default one, so it's omitted
+	if h.Version != argon2Version10 {
+		wr.WriteParam(fmt.Sprintf("v=%d", h.Version))
+	}
 	wr.WriteParam(h.encodeParams())
 	wr.WriteParam(passwordBase64Encoding.EncodeToString(h.Salt))
 	wr.WriteParam(passwordBase64Encoding.EncodeToString(h.Hash))
@@ -146,8 +321,143 @@ func (h *Argon2PasswordHash) Raw() (res []byte, err error) {
 	return
 }
 
+func argon2BMCFID(name string) (id byte, ok bool) {
+	switch name {
+	case "argon2d":
+		return BMCFArgon2dID, true
+	case "argon2i":
+		return BMCFArgon2iID, true
+	case "argon2id":
+		return BMCFArgon2idID, true
+	default:
+		return
+	}
+}
+
+// RawBinary encodes hash in BMCF, which has following layout:
+// scheme id byte, version byte, varint memory, varint time, threads byte,
+// varint length prefixed keyid, data and salt, hash till the end.
+func (h *Argon2PasswordHash) RawBinary(appendTo []byte) (res []byte, err error) {
+	res = appendTo
+
+	err = h.validate()
+	if err != nil {
+		return
+	}
+
+	id, _ := argon2BMCFID(h.Name)
+
+	res = append(res, id, byte(h.Version))
+	res = bmcfAppendUint(res, uint64(h.Memory))
+	res = bmcfAppendUint(res, uint64(h.Time))
+	res = append(res, h.Threads)
+	res = bmcfAppendVarBytes(res, h.KeyID)
+	res = bmcfAppendVarBytes(res, h.Data)
+	res = bmcfAppendVarBytes(res, h.Salt)
+	res = append(res, h.Hash...)
+	return
+}
+
+// LoadBinary parses hash encoded with RawBinary.
+func (dh *Argon2PasswordHash) LoadBinary(data []byte) (err error) {
+	r := bmcfReader{data: data}
+	h := Argon2PasswordHash{}
+
+	id, err := r.ReadByte()
+	if err != nil {
+		return
+	}
+	switch id {
+	case BMCFArgon2dID:
+		h.Name = "argon2d"
+	case BMCFArgon2iID:
+		h.Name = "argon2i"
+	case BMCFArgon2idID:
+		h.Name = "argon2id"
+	default:
+		err = ErrPasswordHashUnknownAlgo
+		return
+	}
+
+	version, err := r.ReadByte()
+	if err != nil {
+		return
+	}
+	h.Version = int(version)
+
+	memory, err := r.ReadUint(32)
+	if err != nil {
+		return
+	}
+	h.Memory = uint32(memory)
+
+	time, err := r.ReadUint(32)
+	if err != nil {
+		return
+	}
+	h.Time = uint32(time)
+
+	h.Threads, err = r.ReadByte()
+	if err != nil {
+		return
+	}
+
+	h.KeyID, err = r.ReadVarBytes(argon2MaxKeyID)
+	if err != nil {
+		return
+	}
+	if len(h.KeyID) == 0 {
+		h.KeyID = nil
+	}
+
+	h.Data, err = r.ReadVarBytes(argon2MaxData)
+	if err != nil {
+		return
+	}
+	if len(h.Data) == 0 {
+		h.Data = nil
+	}
+
+	h.Salt, err = r.ReadVarBytes(len(data))
+	if err != nil {
+		return
+	}
+
+	h.Hash = r.ReadRest()
+
+	err = h.validate()
+	if err != nil {
+		return
+	}
+
+	*dh = h
+	return
+}
+
+// ComputeHash computes argon2 hash of given password using parameters, salt and associated data from this hash.
+// Length of result is same as length of Hash field.
+//
+// Secret is optional secret value(pepper), which is not stored in hash.
+func (h *Argon2PasswordHash) ComputeHash(password, secret []byte) (res []byte, err error) {
+	err = h.validate()
+	if err != nil {
+		return
+	}
+
+	mode, _ := argon2ModeFromName(h.Name)
+	res = argon2DeriveKey(
+		mode,
+		uint32(h.Version),
+		password, h.Salt, secret, h.Data,
+		h.Time, h.Memory, h.Threads,
+		uint32(len(h.Hash)),
+	)
+	return
+}
+
 type Argon2PasswordHasher struct {
-	// Defaults to argon2id
+	// Defaults to argon2id.
+	// argon2i and argon2d are supported as well.
 	AlgoName string
 
 	SaltLength uint32
@@ -157,9 +467,40 @@ type Argon2PasswordHasher struct {
 	Time    uint32
 	Threads uint8
 
+	// Optional secret(pepper), which is mixed into hash, but never stored in it.
+	Secret []byte
+
+	// Optional identifier of Secret, stored in hash as keyid parameter.
+	// At most 8 bytes.
+	KeyID []byte
+
+	// Optional associated data, stored in hash as data parameter.
+	// At most 32 bytes.
+	Data []byte
+
+	// If true, CheckPassword loads hashes using LoadLegacy, so it accepts hashes created by older crypka versions only.
+	// Hashes created by HashPassword always use standard alphabet, so they are rejected by such hasher.
+	LegacyAlphabet bool
+
 	RNG RNG
 }
 
+func (h *Argon2PasswordHasher) getName() string {
+	name := "argon2id"
+	if len(h.AlgoName) > 0 {
+		name = h.AlgoName
+	}
+	return name
+}
+
+func (h *Argon2PasswordHasher) GetInfo() PHashAlgoInfo {
+	return PHashAlgoInfo{
+		Name:       h.getName(),
+		Secure:     true,
+		MemoryCost: uint64(h.Memory) * 1024,
+	}
+}
+
 func (h *Argon2PasswordHasher) HashPassword(ctx PasswordHashContext, password, appendTo []byte) (res []byte, err error) {
 	res = appendTo
 
@@ -171,20 +512,21 @@ func (h *Argon2PasswordHasher) HashPassword(ctx PasswordHashContext, password, a
 		return
 	}
 
-	name := "argon2id"
-	if len(h.AlgoName) > 0 {
-		name = h.AlgoName
-	}
-
-	rawHash := argon2.IDKey(password, salt, h.Time, h.Memory, h.Threads, h.KeyLength)
 	typedHash := Argon2PasswordHash{
-		Name:    name,
+		Name:    h.getName(),
 		Salt:    salt,
-		Hash:    rawHash,
-		Version: 0x13,
+		Hash:    make([]byte, h.KeyLength),
+		Version: argon2Version13,
 		Time:    h.Time,
 		Threads: h.Threads,
 		Memory:  h.Memory,
+		KeyID:   h.KeyID,
+		Data:    h.Data,
+	}
+
+	typedHash.Hash, err = typedHash.ComputeHash(password, h.Secret)
+	if err != nil {
+		return
 	}
 
 	raw, err := typedHash.Raw()
@@ -196,33 +538,41 @@ func (h *Argon2PasswordHasher) HashPassword(ctx PasswordHashContext, password, a
 	return
 }
 
+// CheckPassword checks password against hash.
+// Both argon2 versions, 0x10 and 0x13, are accepted, since version is stored in hash.
+// HashPassword always uses 0x13.
 func (h *Argon2PasswordHasher) CheckPassword(ctx PasswordHashContext, password, hash []byte) (err error) {
 	phash := Argon2PasswordHash{}
-	err = phash.Load(bytes.NewReader(hash))
+	if h.LegacyAlphabet {
+		err = phash.LoadLegacy(bytes.NewReader(hash))
+	} else {
+		err = phash.Load(bytes.NewReader(hash))
+	}
 	if err != nil {
 		return
 	}
 
-	name := "argon2id"
-	if len(h.AlgoName) > 0 {
-		name = h.AlgoName
-	}
-
-	if phash.Name != name || phash.Version != 0x13 {
+	if phash.Name != h.getName() {
 		err = ErrPasswordHashUnknownAlgo
 		return
 	}
 
 	if phash.Time != h.Time ||
 		phash.Memory != h.Memory ||
-		phash.Threads != phash.Threads ||
+		phash.Threads != h.Threads ||
 		len(phash.Salt) != int(h.SaltLength) ||
-		len(phash.Hash) != int(h.KeyLength) {
+		len(phash.Hash) != int(h.KeyLength) ||
+		!bytes.Equal(phash.KeyID, h.KeyID) ||
+		!bytes.Equal(phash.Data, h.Data) {
 		err = ErrPasswordHashParamMismatch
 		return
 	}
 
-	rawHash := argon2.IDKey(password, phash.Salt, h.Time, h.Memory, h.Threads, h.KeyLength)
+	rawHash, err := phash.ComputeHash(password, h.Secret)
+	if err != nil {
+		return
+	}
+
 	if !hmac.Equal(rawHash, phash.Hash) {
 		err = ErrPasswordHashMismatch
 		return
@@ -230,3 +580,106 @@ func (h *Argon2PasswordHasher) CheckPassword(ctx PasswordHashContext, password,
 
 	return
 }
+
+var argon2HashKeyDefaultSalt = []byte("crypka-argon2-hash")
+
+// Argon2HashKey is SigningKey, which computes argon2 hash of all data written to signer.
+// It's not keyed, it implements SigningKey only in order to fit hash abstraction used in crypka.
+//
+// Since argon2 is not streaming function, all data is buffered until finalization,
+// so it should be used for small inputs only, like proof of work ones.
+type Argon2HashKey struct {
+	// Defaults to argon2id.
+	AlgoName string
+
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+
+	// Defaults to 32.
+	KeyLength uint32
+
+	// Defaults to constant one. Must be at least 8 bytes.
+	Salt []byte
+}
+
+func (k *Argon2HashKey) getKeyLength() uint32 {
+	if k.KeyLength == 0 {
+		return 32
+	}
+	return k.KeyLength
+}
+
+func (k *Argon2HashKey) hashSize() int {
+	return int(k.getKeyLength())
+}
+
+func (k *Argon2HashKey) MakeSigner(ctx KeyContext) (signer Signer, err error) {
+	name := "argon2id"
+	if len(k.AlgoName) > 0 {
+		name = k.AlgoName
+	}
+
+	salt := k.Salt
+	if len(salt) == 0 {
+		salt = argon2HashKeyDefaultSalt
+	}
+
+	h := Argon2PasswordHash{
+		Name:    name,
+		Salt:    salt,
+		Hash:    make([]byte, k.getKeyLength()),
+		Version: argon2Version13,
+		Memory:  k.Memory,
+		Time:    k.Time,
+		Threads: k.Threads,
+	}
+
+	err = h.validate()
+	if err != nil {
+		return
+	}
+
+	signer = &argon2HashSigner{
+		hash: h,
+	}
+	return
+}
+
+type argon2HashSigner struct {
+	hash Argon2PasswordHash
+	data []byte
+}
+
+func (s *argon2HashSigner) Write(data []byte) (sz int, err error) {
+	s.data = append(s.data, data...)
+	sz = len(data)
+	return
+}
+
+func (s *argon2HashSigner) Finalize(appendTo []byte) (res []byte, err error) {
+	hash, err := s.hash.ComputeHash(s.data, nil)
+	if err != nil {
+		return
+	}
+
+	res = append(appendTo, hash...)
+	return
+}
+
+// Registers argon2id password hasher as "argon2id".
+// It uses second recommended option from RFC 9106: 3 passes, 64MB of memory, 4 lanes, 16 byte salt and 32 byte tag.
+func RegisterArgon2(reg Registry) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	registerDefault(reg, "argon2id", &Argon2PasswordHasher{
+		AlgoName:   "argon2id",
+		SaltLength: 16,
+		KeyLength:  32,
+		Memory:     64 * 1024,
+		Time:       3,
+		Threads:    4,
+	})
+}
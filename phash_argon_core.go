@@ -0,0 +1,353 @@
+package crypka
+
+import (
+	"encoding/binary"
+	"hash"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Argon2 core is based on golang.org/x/crypto/argon2(BSD-style license).
+// It's extended with argon2d variant, secret and associated data inputs and version 0x10 support,
+// none of which is exposed by x/crypto.
+//
+// x/crypto is still used whenever it's able to compute hash requested, since it's assembly-optimized.
+
+type argon2Mode uint32
+
+const (
+	argon2dMode  argon2Mode = 0
+	argon2iMode  argon2Mode = 1
+	argon2idMode argon2Mode = 2
+)
+
+const (
+	argon2Version10 = 0x10
+	argon2Version13 = 0x13
+)
+
+const (
+	argon2BlockLength = 128
+	argon2SyncPoints  = 4
+)
+
+type argon2Block [argon2BlockLength]uint64
+
+func argon2ModeFromName(name string) (mode argon2Mode, ok bool) {
+	switch name {
+	case "argon2d":
+		return argon2dMode, true
+	case "argon2i":
+		return argon2iMode, true
+	case "argon2id":
+		return argon2idMode, true
+	default:
+		return
+	}
+}
+
+// argon2DeriveKey computes argon2 hash.
+// Parameters must be validated before calling it.
+func argon2DeriveKey(
+	mode argon2Mode,
+	version uint32,
+	password, salt, secret, data []byte,
+	time, memory uint32,
+	threads uint8,
+	keyLen uint32,
+) []byte {
+	if version == argon2Version13 && len(secret) == 0 && len(data) == 0 {
+		if mode == argon2idMode {
+			return argon2.IDKey(password, salt, time, memory, threads, keyLen)
+		} else if mode == argon2iMode {
+			return argon2.Key(password, salt, time, memory, threads, keyLen)
+		}
+	}
+
+	h0 := argon2InitHash(mode, version, password, salt, secret, data, time, memory, uint32(threads), keyLen)
+
+	memory = memory / (argon2SyncPoints * uint32(threads)) * (argon2SyncPoints * uint32(threads))
+	if memory < 2*argon2SyncPoints*uint32(threads) {
+		memory = 2 * argon2SyncPoints * uint32(threads)
+	}
+
+	blocks := argon2InitBlocks(&h0, memory, uint32(threads))
+	argon2ProcessBlocks(blocks, mode, version, time, memory, uint32(threads))
+	return argon2ExtractKey(blocks, memory, uint32(threads), keyLen)
+}
+
+func argon2InitHash(
+	mode argon2Mode,
+	version uint32,
+	password, salt, secret, data []byte,
+	time, memory, threads, keyLen uint32,
+) (h0 [blake2b.Size + 8]byte) {
+	var params [24]byte
+
+	b2, _ := blake2b.New512(nil)
+	binary.LittleEndian.PutUint32(params[0:4], threads)
+	binary.LittleEndian.PutUint32(params[4:8], keyLen)
+	binary.LittleEndian.PutUint32(params[8:12], memory)
+	binary.LittleEndian.PutUint32(params[12:16], time)
+	binary.LittleEndian.PutUint32(params[16:20], version)
+	binary.LittleEndian.PutUint32(params[20:24], uint32(mode))
+	b2.Write(params[:])
+
+	for _, input := range [][]byte{password, salt, secret, data} {
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(len(input)))
+		b2.Write(length[:])
+		b2.Write(input)
+	}
+
+	b2.Sum(h0[:0])
+	return
+}
+
+func argon2InitBlocks(h0 *[blake2b.Size + 8]byte, memory, threads uint32) []argon2Block {
+	var rawBlock [1024]byte
+	blocks := make([]argon2Block, memory)
+	for lane := uint32(0); lane < threads; lane++ {
+		j := lane * (memory / threads)
+		binary.LittleEndian.PutUint32(h0[blake2b.Size+4:], lane)
+
+		for i := uint32(0); i < 2; i++ {
+			binary.LittleEndian.PutUint32(h0[blake2b.Size:], i)
+			argon2Blake2bHash(rawBlock[:], h0[:])
+			for k := range blocks[j+i] {
+				blocks[j+i][k] = binary.LittleEndian.Uint64(rawBlock[k*8:])
+			}
+		}
+	}
+	return blocks
+}
+
+func argon2ProcessBlocks(blocks []argon2Block, mode argon2Mode, version, time, memory, threads uint32) {
+	lanes := memory / threads
+	segments := lanes / argon2SyncPoints
+
+	processSegment := func(n, slice, lane uint32, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		dataIndependent := mode == argon2iMode || (mode == argon2idMode && n == 0 && slice < argon2SyncPoints/2)
+
+		var addresses, in, zero argon2Block
+		if dataIndependent {
+			in[0] = uint64(n)
+			in[1] = uint64(lane)
+			in[2] = uint64(slice)
+			in[3] = uint64(memory)
+			in[4] = uint64(time)
+			in[5] = uint64(mode)
+		}
+
+		index := uint32(0)
+		if n == 0 && slice == 0 {
+			// first two blocks were already generated
+			index = 2
+			if dataIndependent {
+				in[6]++
+				argon2ProcessBlock(&addresses, &in, &zero, false)
+				argon2ProcessBlock(&addresses, &addresses, &zero, false)
+			}
+		}
+
+		offset := lane*lanes + slice*segments + index
+		var random uint64
+		for index < segments {
+			prev := offset - 1
+			if index == 0 && slice == 0 {
+				// last block in lane
+				prev += lanes
+			}
+			if dataIndependent {
+				if index%argon2BlockLength == 0 {
+					in[6]++
+					argon2ProcessBlock(&addresses, &in, &zero, false)
+					argon2ProcessBlock(&addresses, &addresses, &zero, false)
+				}
+				random = addresses[index%argon2BlockLength]
+			} else {
+				random = blocks[prev][0]
+			}
+
+			newOffset := argon2IndexAlpha(random, lanes, segments, threads, n, slice, lane, index)
+
+			// version 0x10 overwrites blocks in subsequent passes, 0x13 XORs them
+			// in first pass both are equivalent, since blocks are zeroed
+			argon2ProcessBlock(&blocks[offset], &blocks[prev], &blocks[newOffset], version != argon2Version10)
+			index, offset = index+1, offset+1
+		}
+	}
+
+	for n := uint32(0); n < time; n++ {
+		for slice := uint32(0); slice < argon2SyncPoints; slice++ {
+			var wg sync.WaitGroup
+			for lane := uint32(0); lane < threads; lane++ {
+				wg.Add(1)
+				go processSegment(n, slice, lane, &wg)
+			}
+			wg.Wait()
+		}
+	}
+}
+
+func argon2ExtractKey(blocks []argon2Block, memory, threads, keyLen uint32) []byte {
+	lanes := memory / threads
+	for lane := uint32(0); lane < threads-1; lane++ {
+		for i, v := range blocks[(lane*lanes)+lanes-1] {
+			blocks[memory-1][i] ^= v
+		}
+	}
+
+	var rawBlock [1024]byte
+	for i, v := range blocks[memory-1] {
+		binary.LittleEndian.PutUint64(rawBlock[i*8:], v)
+	}
+	key := make([]byte, keyLen)
+	argon2Blake2bHash(key, rawBlock[:])
+	return key
+}
+
+func argon2IndexAlpha(rand uint64, lanes, segments, threads, n, slice, lane, index uint32) uint32 {
+	refLane := uint32(rand>>32) % threads
+	if n == 0 && slice == 0 {
+		refLane = lane
+	}
+	m, s := 3*segments, ((slice+1)%argon2SyncPoints)*segments
+	if lane == refLane {
+		m += index
+	}
+	if n == 0 {
+		m, s = slice*segments, 0
+		if slice == 0 || lane == refLane {
+			m += index
+		}
+	}
+	if index == 0 || lane == refLane {
+		m--
+	}
+
+	p := rand & 0xFFFFFFFF
+	p = (p * p) >> 32
+	p = (p * uint64(m)) >> 32
+	return refLane*lanes + uint32((uint64(s)+uint64(m)-(p+1))%uint64(lanes))
+}
+
+func argon2ProcessBlock(out, in1, in2 *argon2Block, xor bool) {
+	var t argon2Block
+	for i := range t {
+		t[i] = in1[i] ^ in2[i]
+	}
+
+	// rows
+	for i := 0; i < argon2BlockLength; i += 16 {
+		argon2Permute(
+			&t[i+0], &t[i+1], &t[i+2], &t[i+3],
+			&t[i+4], &t[i+5], &t[i+6], &t[i+7],
+			&t[i+8], &t[i+9], &t[i+10], &t[i+11],
+			&t[i+12], &t[i+13], &t[i+14], &t[i+15],
+		)
+	}
+
+	// columns
+	for i := 0; i < argon2BlockLength/8; i += 2 {
+		argon2Permute(
+			&t[i], &t[i+1], &t[16+i], &t[16+i+1],
+			&t[32+i], &t[32+i+1], &t[48+i], &t[48+i+1],
+			&t[64+i], &t[64+i+1], &t[80+i], &t[80+i+1],
+			&t[96+i], &t[96+i+1], &t[112+i], &t[112+i+1],
+		)
+	}
+
+	if xor {
+		for i := range t {
+			out[i] ^= in1[i] ^ in2[i] ^ t[i]
+		}
+	} else {
+		for i := range t {
+			out[i] = in1[i] ^ in2[i] ^ t[i]
+		}
+	}
+}
+
+func argon2BlaMka(a, b, c, d uint64) (uint64, uint64, uint64, uint64) {
+	a += b + 2*uint64(uint32(a))*uint64(uint32(b))
+	d ^= a
+	d = d>>32 | d<<32
+	c += d + 2*uint64(uint32(c))*uint64(uint32(d))
+	b ^= c
+	b = b>>24 | b<<40
+
+	a += b + 2*uint64(uint32(a))*uint64(uint32(b))
+	d ^= a
+	d = d>>16 | d<<48
+	c += d + 2*uint64(uint32(c))*uint64(uint32(d))
+	b ^= c
+	b = b>>63 | b<<1
+	return a, b, c, d
+}
+
+func argon2Permute(t00, t01, t02, t03, t04, t05, t06, t07, t08, t09, t10, t11, t12, t13, t14, t15 *uint64) {
+	v00, v01, v02, v03 := *t00, *t01, *t02, *t03
+	v04, v05, v06, v07 := *t04, *t05, *t06, *t07
+	v08, v09, v10, v11 := *t08, *t09, *t10, *t11
+	v12, v13, v14, v15 := *t12, *t13, *t14, *t15
+
+	v00, v04, v08, v12 = argon2BlaMka(v00, v04, v08, v12)
+	v01, v05, v09, v13 = argon2BlaMka(v01, v05, v09, v13)
+	v02, v06, v10, v14 = argon2BlaMka(v02, v06, v10, v14)
+	v03, v07, v11, v15 = argon2BlaMka(v03, v07, v11, v15)
+
+	v00, v05, v10, v15 = argon2BlaMka(v00, v05, v10, v15)
+	v01, v06, v11, v12 = argon2BlaMka(v01, v06, v11, v12)
+	v02, v07, v08, v13 = argon2BlaMka(v02, v07, v08, v13)
+	v03, v04, v09, v14 = argon2BlaMka(v03, v04, v09, v14)
+
+	*t00, *t01, *t02, *t03 = v00, v01, v02, v03
+	*t04, *t05, *t06, *t07 = v04, v05, v06, v07
+	*t08, *t09, *t10, *t11 = v08, v09, v10, v11
+	*t12, *t13, *t14, *t15 = v12, v13, v14, v15
+}
+
+// argon2Blake2bHash computes variable length hash H' of in and writes it to out.
+func argon2Blake2bHash(out []byte, in []byte) {
+	var b2 hash.Hash
+	if n := len(out); n < blake2b.Size {
+		b2, _ = blake2b.New(n, nil)
+	} else {
+		b2, _ = blake2b.New512(nil)
+	}
+
+	var buffer [blake2b.Size]byte
+	binary.LittleEndian.PutUint32(buffer[:4], uint32(len(out)))
+	b2.Write(buffer[:4])
+	b2.Write(in)
+
+	if len(out) <= blake2b.Size {
+		b2.Sum(out[:0])
+		return
+	}
+
+	outLen := len(out)
+	b2.Sum(buffer[:0])
+	b2.Reset()
+	copy(out, buffer[:32])
+	out = out[32:]
+	for len(out) > blake2b.Size {
+		b2.Write(buffer[:])
+		b2.Sum(buffer[:0])
+		copy(out, buffer[:32])
+		out = out[32:]
+		b2.Reset()
+	}
+
+	if outLen%blake2b.Size > 0 {
+		r := ((outLen + 31) / 32) - 2
+		b2, _ = blake2b.New(outLen-32*r, nil)
+	}
+	b2.Write(buffer[:])
+	b2.Sum(out[:0])
+}
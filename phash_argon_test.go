@@ -2,15 +2,20 @@ package crypka_test
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/teawithsand/crypka"
+	"github.com/teawithsand/crypka/crypkatest"
 )
 
-const someHash = "$argon2i$v=19$m=120,t=5000,p=2$iHSDPHzUhPzK7rCcJgOFfg$J4moa2MM0/6uf3HbY2Tf5Fux8JIBTwIhmhxGRbsY14qhTltQt.Vw3b7tcJNEbk8ium8AQfZeD4tabCnNqfkD1e"
+const someHash = "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA"
 
 func TestPHash_Argon2(t *testing.T) {
 	t.Run("can_parse", func(t *testing.T) {
@@ -71,12 +76,46 @@ func TestPHash_Argon2(t *testing.T) {
 	})
 }
 
-func FuzzPHash_Argon2Load(f *testing.F) {
-	h := crypka.Argon2PasswordHash{}
+// Seeds, which are not valid hashes, for fuzzing PHC string parsers.
+var malformedPHCSeeds = []string{
+	"",
+	"$",
+	"$$$$$$$$",
+	"$argon2id",
+	"$argon2id$",
+	"$argon2id$v=19$",
+	"$argon2id$v=19$m=65536,t=2,p=1,$c29tZXNhbHQ$AAAA",
+	"$argon2id$v=19$m=65536,,t=2$c29tZXNhbHQ$AAAA",
+	"$argon2id$v=019$m=65536,t=2,p=1$c29tZXNhbHQ$AAAA",
+	"$argon2id$v=19$m=99999999999999999999,t=2,p=1$c29tZXNhbHQ$AAAA",
+	"$argon2id$v=19$m=65536,t=2,p=1,keyid=AQIDBA,data=BAQE$c29tZXNhbHQ$AAAA$",
+	"$argon2id$v=19$m=65536,t=2,p=1$!!!!$AAAA",
+}
 
+func FuzzPHash_Argon2Load(f *testing.F) {
 	f.Add([]byte(someHash))
+	for _, vector := range argon2PHCVectors {
+		f.Add([]byte(vector))
+	}
+	for _, seed := range malformedPHCSeeds {
+		f.Add([]byte(seed))
+	}
+
 	f.Fuzz(func(t *testing.T, data []byte) {
-		_ = h.Load(bytes.NewReader(data))
+		h := crypka.Argon2PasswordHash{}
+
+		var err error
+		crypkatest.CheckAllocBound(t, 64*1024+16*uint64(len(data)), func() {
+			err = h.Load(bytes.NewReader(data))
+		})
+		if err != nil {
+			return
+		}
+
+		_, err = h.Raw()
+		if err != nil {
+			t.Error("loaded hash can't be encoded", err)
+		}
 	})
 }
 
@@ -91,3 +130,328 @@ func FuzzPHash_Argon2DifferentValuesSameHash(f *testing.F) {
 	})
 }
 */
+
+// Vectors from reference implementation: https://github.com/P-H-C/phc-winner-argon2
+// All of them use password "password" and salt "somesalt".
+var argon2PHCVectors = []string{
+	"$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA",
+	"$argon2i$v=19$m=256,t=2,p=1$c29tZXNhbHQ$iekCn0Y3spW+sCcFanM2xBT63UP2sghkUoHLIUpWRS8",
+	"$argon2i$v=19$m=256,t=2,p=2$c29tZXNhbHQ$T/XOJ2mh1/TIpJHfCdQan76Q5esCFVoT5MAeIM1Oq2E",
+	"$argon2i$v=19$m=65536,t=1,p=1$c29tZXNhbHQ$0WgHXE2YXhPr6uVgz4uUw7XYoWxRkWtvSsLaOsEbvs8",
+	"$argon2i$m=65536,t=2,p=1$c29tZXNhbHQ$9sTbSlTio3Biev89thdrlKKiCaYsjjYVJxGAL3swxpQ",
+	"$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+	"$argon2id$v=19$m=256,t=2,p=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+}
+
+func TestPHash_Argon2_PHCVectors(t *testing.T) {
+	for _, vector := range argon2PHCVectors {
+		h := crypka.Argon2PasswordHash{}
+		err := h.Load(strings.NewReader(vector))
+		if err != nil {
+			t.Error(vector, err)
+			continue
+		}
+
+		encoded, err := h.Raw()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if string(encoded) != vector {
+			t.Error("re-encoded hash differs", vector, string(encoded))
+		}
+
+		res, err := h.ComputeHash([]byte("password"), nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !bytes.Equal(res, h.Hash) {
+			t.Error("hash mismatch for vector", vector)
+		}
+	}
+}
+
+// Vectors from RFC 9106, which use secret and associated data.
+func TestPHash_Argon2_RFCVectors(t *testing.T) {
+	repeat := func(b byte, n int) []byte {
+		return bytes.Repeat([]byte{b}, n)
+	}
+	vectors := []struct {
+		name string
+		hash string
+	}{
+		{"argon2d", "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb"},
+		{"argon2i", "c814d9d1dc7f37aa13f0d77f2494bda1c8de6b016dd388d29952a4c4672b6ce8"},
+		{"argon2id", "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659"},
+	}
+
+	for _, v := range vectors {
+		expected, err := hex.DecodeString(v.hash)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		h := crypka.Argon2PasswordHash{
+			Name:    v.name,
+			Version: 0x13,
+			Memory:  32,
+			Time:    3,
+			Threads: 4,
+			Salt:    repeat(0x02, 16),
+			Data:    repeat(0x04, 12),
+			Hash:    make([]byte, 32),
+		}
+
+		res, err := h.ComputeHash(repeat(0x01, 32), repeat(0x03, 8))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if !bytes.Equal(res, expected) {
+			t.Errorf("hash mismatch for %s: %x", v.name, res)
+		}
+	}
+}
+
+func TestPHash_Argon2_LoadIsStrict(t *testing.T) {
+	invalid := []string{
+		"",
+		"$argon2id",
+		"$argon2x$v=19$m=256,t=2,p=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+		"$argon2id$v=19$t=2,m=256,p=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+		"$argon2id$v=19$m=256,m=256,t=2,p=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+		"$argon2id$v=19$m=256,t=2,p=2,x=1$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+		"$argon2id$v=19$m=256,t=2,p=2,data=AAAA,keyid=AAAA$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+		"$argon2id$v=19$m=256,t=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+		"$argon2id$v=19$m=256,t=2,p=2,$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+		"$argon2id$v=19$m=0256,t=2,p=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+		"$argon2id$v=19$m=+256,t=2,p=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+		"$argon2id$v=19$m=256,t=2,p=256$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+		"$argon2id$v=20$m=256,t=2,p=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+		"$argon2id$v=19$m=256,t=2,p=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc$",
+		"$argon2id$v=19$m=256,t=2,p=2$c29tZXNhbHQ=$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+		"$argon2id$v=19$v=19$m=256,t=2,p=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+		"$argon2id$v=19$m=4294967295,t=2,p=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+	}
+
+	for _, hash := range invalid {
+		h := crypka.Argon2PasswordHash{}
+		err := h.Load(strings.NewReader(hash))
+		if err == nil {
+			t.Error("expected error for", hash)
+		}
+	}
+}
+
+// Hashes encoded by older crypka versions use different base64 alphabet.
+func TestPHash_Argon2_LoadLegacyAlphabet(t *testing.T) {
+	legacyEncoding := base64.NewEncoding("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789").WithPadding(base64.NoPadding)
+
+	encodeLegacy := func(h crypka.Argon2PasswordHash) string {
+		return fmt.Sprintf(
+			"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			h.Name, h.Version, h.Memory, h.Time, h.Threads,
+			legacyEncoding.EncodeToString(h.Salt), legacyEncoding.EncodeToString(h.Hash),
+		)
+	}
+
+	for _, vector := range argon2PHCVectors {
+		expected := crypka.Argon2PasswordHash{}
+		err := expected.Load(strings.NewReader(vector))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		legacy := encodeLegacy(expected)
+
+		h := crypka.Argon2PasswordHash{}
+		err = h.LoadLegacy(strings.NewReader(legacy))
+		if err != nil {
+			t.Error(legacy, err)
+			return
+		}
+		if !reflect.DeepEqual(h, expected) {
+			t.Error("legacy hash was loaded incorrectly", legacy)
+			return
+		}
+
+		encoded, err := h.Raw()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if string(encoded) != vector {
+			t.Error("legacy hash was not re-encoded with standard alphabet", string(encoded))
+			return
+		}
+
+		err = h.LoadLegacy(strings.NewReader(vector))
+		if err == nil && reflect.DeepEqual(h, expected) {
+			t.Error("expected LoadLegacy not to accept standard alphabet", vector)
+			return
+		}
+	}
+
+	// Salt and hash, which lengths are multiples of 3, are valid in both alphabets,
+	// so hasher has to be told, which one is used.
+	hasher := crypka.Argon2PasswordHasher{
+		SaltLength: 18,
+		KeyLength:  24,
+		Memory:     64,
+		Time:       1,
+		Threads:    1,
+	}
+	raw, err := hasher.HashPassword(nil, []byte("password"), nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	parsed := crypka.Argon2PasswordHash{}
+	err = parsed.Load(bytes.NewReader(raw))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	legacy := []byte(encodeLegacy(parsed))
+
+	err = hasher.CheckPassword(nil, []byte("password"), legacy)
+	if err == nil {
+		t.Error("expected legacy hash to be rejected by default")
+		return
+	}
+
+	legacyHasher := hasher
+	legacyHasher.LegacyAlphabet = true
+	err = legacyHasher.CheckPassword(nil, []byte("password"), legacy)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = legacyHasher.CheckPassword(nil, []byte("password"), raw)
+	if err == nil {
+		t.Error("expected hash with standard alphabet to be rejected by legacy hasher")
+		return
+	}
+}
+
+// Hashes without version, which use argon2 version 0x10, can be checked as well.
+func TestPHash_Argon2_CheckVersion10(t *testing.T) {
+	const vector = "$argon2i$m=65536,t=2,p=1$c29tZXNhbHQ$9sTbSlTio3Biev89thdrlKKiCaYsjjYVJxGAL3swxpQ"
+
+	h := crypka.Argon2PasswordHasher{
+		AlgoName:   "argon2i",
+		SaltLength: 8,
+		KeyLength:  32,
+		Memory:     65536,
+		Time:       2,
+		Threads:    1,
+	}
+
+	err := h.CheckPassword(nil, []byte("password"), []byte(vector))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = h.CheckPassword(nil, []byte("other"), []byte(vector))
+	if !errors.Is(err, crypka.ErrPasswordHashMismatch) {
+		t.Error("expected mismatch, got", err)
+		return
+	}
+}
+
+func TestPHash_Argon2_Variants(t *testing.T) {
+	for _, name := range []string{"argon2i", "argon2d", "argon2id"} {
+		h := crypka.Argon2PasswordHasher{
+			AlgoName:   name,
+			SaltLength: 16,
+			KeyLength:  32,
+			Memory:     64,
+			Time:       1,
+			Threads:    2,
+			Secret:     []byte("pepper"),
+			KeyID:      []byte{1},
+			Data:       []byte("data"),
+		}
+		phash, err := h.HashPassword(nil, []byte("asdf"), nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if !bytes.HasPrefix(phash, []byte("$"+name+"$")) {
+			t.Error("invalid algorithm name in hash", string(phash))
+			return
+		}
+
+		err = h.CheckPassword(nil, []byte("asdf"), phash)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		err = h.CheckPassword(nil, []byte("fdsa"), phash)
+		if !errors.Is(err, crypka.ErrPasswordHashMismatch) {
+			t.Error(err)
+			return
+		}
+
+		otherSecret := h
+		otherSecret.Secret = []byte("other pepper")
+		err = otherSecret.CheckPassword(nil, []byte("asdf"), phash)
+		if !errors.Is(err, crypka.ErrPasswordHashMismatch) {
+			t.Error(err)
+			return
+		}
+	}
+}
+
+// Password is fixed and stored hashes vary, since that's what comparison of hashes depends on.
+// Class 0 hashes differ from valid one in last byte only, class 1 ones are random.
+func TestPHash_Argon2_CheckPasswordTiming(t *testing.T) {
+	h := crypka.Argon2PasswordHasher{
+		SaltLength: 16,
+		KeyLength:  32,
+		Memory:     8,
+		Time:       1,
+		Threads:    1,
+	}
+
+	password := []byte("some password")
+	encoded, err := h.HashPassword(nil, password, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	valid := crypka.Argon2PasswordHash{}
+	err = valid.Load(bytes.NewReader(encoded))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tester := &crypkatest.TimingTester{
+		Prepare: func(class int, rng crypka.RNG) (input []byte, err error) {
+			phash := valid
+			phash.Hash = append([]byte{}, valid.Hash...)
+			if class == 1 {
+				_, err = io.ReadFull(rng, phash.Hash)
+				if err != nil {
+					return
+				}
+			}
+			phash.Hash[len(phash.Hash)-1] ^= 1
+			return phash.Raw()
+		},
+		Run: func(input []byte) {
+			h.CheckPassword(nil, password, input)
+		},
+		Measurements: 10000,
+	}
+	tester.Test(t)
+}
@@ -7,12 +7,23 @@ import (
 	"io"
 )
 
-// Parsing/encoding hashes here refers to (Binary) Modular Crypt Format - binary support is NIY
+// Parsing/encoding hashes here refers to (Binary) Modular Crypt Format
 // https://github.com/ademarre/binary-mcf
+//
+// Text format follows PHC string format:
+// https://github.com/P-H-C/phc-string-format/blob/master/phc-sf-spec.md
 
-const base64Alphabeth = "./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+// PHC string format uses standard base64 alphabet without padding.
+// Strict mode is used, so each value has exactly one valid encoding.
+var passwordBase64Encoding = base64.RawStdEncoding.Strict()
 
-var passwordBase64Encoding = base64.NewEncoding(base64Alphabeth).WithPadding(base64.NoPadding)
+// Alphabet, which was used to encode salts and hashes by crypka versions, which did not follow PHC string format.
+// Hashes encoded with it have to be loaded explicitly, see Argon2PasswordHash.LoadLegacy.
+//
+// Note: it can't be detected reliably, since values, which lengths are multiples of 3, are valid in both alphabets.
+const legacyPasswordBase64Alphabet = "./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+var legacyPasswordBase64Encoding = base64.NewEncoding(legacyPasswordBase64Alphabet).WithPadding(base64.NoPadding).Strict()
 
 func parseAlgo(encoded []byte) (algo []byte, err error) {
 	if len(encoded) == 0 {
@@ -30,7 +41,183 @@ func parseAlgo(encoded []byte) (algo []byte, err error) {
 		return
 	}
 
-	algo = encoded[1:i]
+	algo = encoded[1 : i+1]
+	return
+}
+
+// BMCF scheme identifiers, which are first byte of binary encoded hash.
+//
+// binary-mcf draft does not assign identifiers for argon2, so values below are crypka-specific.
+const (
+	BMCFArgon2dID  byte = 0xa0
+	BMCFArgon2iID  byte = 0xa1
+	BMCFArgon2idID byte = 0xa2
+)
+
+// BinaryPHashScheme describes single password hashing scheme, which can be converted between PHC string and BMCF.
+type BinaryPHashScheme struct {
+	ID   byte   // BMCF scheme identifier
+	Name string // PHC string identifier
+	New  func() BinaryPHash
+}
+
+// PHashFormatConverter converts password hashes between PHC string format and binary modular crypt format.
+// Binary form is more compact, so it's useful when hashes are stored in binary columns.
+type PHashFormatConverter struct {
+	Schemes []BinaryPHashScheme
+}
+
+// NewPHashFormatConverter creates converter, which supports all password hashes implemented by crypka.
+func NewPHashFormatConverter() *PHashFormatConverter {
+	newArgon2 := func() BinaryPHash {
+		return &Argon2PasswordHash{}
+	}
+	return &PHashFormatConverter{
+		Schemes: []BinaryPHashScheme{
+			{ID: BMCFArgon2dID, Name: "argon2d", New: newArgon2},
+			{ID: BMCFArgon2iID, Name: "argon2i", New: newArgon2},
+			{ID: BMCFArgon2idID, Name: "argon2id", New: newArgon2},
+		},
+	}
+}
+
+// LoadText parses hash in PHC string format.
+func (c *PHashFormatConverter) LoadText(text []byte) (res BinaryPHash, err error) {
+	algo, err := parseAlgo(text)
+	if err != nil {
+		return
+	}
+
+	for _, scheme := range c.Schemes {
+		if scheme.Name == string(algo) {
+			h := scheme.New()
+			err = h.Load(bytes.NewReader(text))
+			if err != nil {
+				return
+			}
+
+			res = h
+			return
+		}
+	}
+
+	err = ErrPasswordHashUnknownAlgo
+	return
+}
+
+// LoadBinary parses hash in BMCF.
+func (c *PHashFormatConverter) LoadBinary(data []byte) (res BinaryPHash, err error) {
+	if len(data) == 0 {
+		err = ErrPasswordHashParseFiled
+		return
+	}
+
+	for _, scheme := range c.Schemes {
+		if scheme.ID == data[0] {
+			h := scheme.New()
+			err = h.LoadBinary(data)
+			if err != nil {
+				return
+			}
+
+			res = h
+			return
+		}
+	}
+
+	err = ErrPasswordHashUnknownAlgo
+	return
+}
+
+// TextToBinary converts hash in PHC string format into BMCF one.
+func (c *PHashFormatConverter) TextToBinary(text, appendTo []byte) (res []byte, err error) {
+	res = appendTo
+
+	h, err := c.LoadText(text)
+	if err != nil {
+		return
+	}
+
+	return h.RawBinary(res)
+}
+
+// BinaryToText converts hash in BMCF into PHC string one.
+func (c *PHashFormatConverter) BinaryToText(data, appendTo []byte) (res []byte, err error) {
+	res = appendTo
+
+	h, err := c.LoadBinary(data)
+	if err != nil {
+		return
+	}
+
+	raw, err := h.Raw()
+	if err != nil {
+		return
+	}
+
+	res = append(res, raw...)
+	return
+}
+
+// bmcfReader reads values encoded in BMCF-specific hash encodings.
+// Only canonical encodings are accepted, so conversion to BMCF and back is lossless.
+type bmcfReader struct {
+	data []byte
+}
+
+func (r *bmcfReader) ReadByte() (b byte, err error) {
+	if len(r.data) == 0 {
+		err = ErrPasswordHashParseFiled
+		return
+	}
+
+	b = r.data[0]
+	r.data = r.data[1:]
+	return
+}
+
+func (r *bmcfReader) ReadUint(bitSize int) (n uint64, err error) {
+	n, sz, err := ByteVar.DecodeAtStart(r.data)
+	if err != nil || sz != ByteVar.Size(n) || (bitSize < 64 && n >= 1<<bitSize) {
+		err = ErrPasswordHashParseFiled
+		return
+	}
+
+	r.data = r.data[sz:]
+	return
+}
+
+func (r *bmcfReader) ReadVarBytes(maxLength int) (res []byte, err error) {
+	length, err := r.ReadUint(64)
+	if err != nil {
+		return
+	}
+	if length > uint64(maxLength) || length > uint64(len(r.data)) {
+		err = ErrPasswordHashParseFiled
+		return
+	}
+
+	res = make([]byte, int(length))
+	copy(res, r.data)
+	r.data = r.data[len(res):]
+	return
+}
+
+func (r *bmcfReader) ReadRest() (res []byte) {
+	res = make([]byte, len(r.data))
+	copy(res, r.data)
+	r.data = nil
+	return
+}
+
+func bmcfAppendUint(appendTo []byte, n uint64) (res []byte) {
+	res, _ = ByteVar.AppendToBuf(appendTo, n)
+	return
+}
+
+func bmcfAppendVarBytes(appendTo []byte, data []byte) (res []byte) {
+	res = bmcfAppendUint(appendTo, uint64(len(data)))
+	res = append(res, data...)
 	return
 }
 
@@ -39,6 +226,8 @@ type bmcParser struct {
 	Value       []byte
 	state       int
 	cachedError error
+
+	afterSeparator bool
 }
 
 func (p *bmcParser) Get() []byte {
@@ -59,6 +248,10 @@ func (p *bmcParser) Next() (err error) {
 			p.cachedError = err
 			err = nil
 			return
+		} else if errors.Is(err, io.EOF) && p.afterSeparator {
+			// trailing separator
+			err = ErrPasswordHashParseFiled
+			return
 		} else if err != nil {
 			return
 		}
@@ -72,6 +265,7 @@ func (p *bmcParser) Next() (err error) {
 			p.state = 1
 		} else if p.state == 1 {
 			if b == '$' {
+				p.afterSeparator = true
 				break
 			} else {
 				p.Value = append(p.Value, b)
@@ -82,23 +276,45 @@ func (p *bmcParser) Next() (err error) {
 	return
 }
 
+// argParser parses comma separated list of name=value PHC parameters.
+// It's strict: empty names, empty values, trailing commas and characters not allowed by PHC format
+// yield ErrPasswordHashParseFiled.
 type argParser struct {
 	R           io.ByteReader
 	Name        []byte
 	Value       []byte
 	cachedError error
+
+	afterComma bool
 }
 
 func (p *argParser) Get() []byte {
 	return p.Value
 }
 
+func isPHCParamNameByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || b == '-'
+}
+
+func isPHCParamValueByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') ||
+		(b >= 'A' && b <= 'Z') ||
+		(b >= '0' && b <= '9') ||
+		b == '/' || b == '+' || b == '.' || b == '-'
+}
+
 func (p *argParser) Next() (err error) {
 	if p.cachedError != nil {
 		err = p.cachedError
 		return
 	}
 
+	defer func() {
+		if err != nil && !errors.Is(err, io.EOF) {
+			p.cachedError = err
+		}
+	}()
+
 	p.Value = nil
 	p.Name = nil
 
@@ -106,34 +322,43 @@ func (p *argParser) Next() (err error) {
 	for {
 		var b byte
 		b, err = p.R.ReadByte()
-		if errors.Is(err, io.EOF) && state == 0 {
-			err = io.ErrUnexpectedEOF
-		}
+		if errors.Is(err, io.EOF) {
+			if state == 0 && len(p.Name) == 0 && !p.afterComma {
+				return
+			}
+			if state == 1 && len(p.Value) > 0 {
+				p.cachedError = io.EOF
+				err = nil
+				return
+			}
 
-		if len(p.Value) > 0 && err != nil {
-			p.cachedError = err
-			err = nil
+			err = ErrPasswordHashParseFiled
 			return
 		} else if err != nil {
 			return
 		}
 
 		if state == 0 {
-			if b == '=' {
+			if b == '=' && len(p.Name) > 0 {
 				state = 1
-			} else {
+			} else if isPHCParamNameByte(b) {
 				p.Name = append(p.Name, b)
+			} else {
+				err = ErrPasswordHashParseFiled
+				return
 			}
 		} else if state == 1 {
-			if b == ',' {
-				state = 0
-				break
+			if b == ',' && len(p.Value) > 0 {
+				p.afterComma = true
+				return
+			} else if isPHCParamValueByte(b) {
+				p.Value = append(p.Value, b)
+			} else {
+				err = ErrPasswordHashParseFiled
+				return
 			}
-			p.Value = append(p.Value, b)
 		}
 	}
-
-	return
 }
 
 type bmcWriter struct {
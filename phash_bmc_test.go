@@ -0,0 +1,105 @@
+package crypka_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+	"github.com/teawithsand/crypka/crypkatest"
+)
+
+func makePHashFormatTester() crypkatest.PHashFormatTester {
+	samples := [][]byte{
+		[]byte("$argon2d$v=19$m=32,t=3,p=4,keyid=AQIDBA,data=BAQEBAQEBAQEBAQE$AgICAgICAgICAgICAgICAg$USs5G28RYpdTcdMJGXNClPho477mE/PBoTpNufq+Sss"),
+	}
+	for _, vector := range argon2PHCVectors {
+		samples = append(samples, []byte(vector))
+	}
+
+	return crypkatest.PHashFormatTester{
+		Samples: samples,
+	}
+}
+
+func TestPHash_BMCF(t *testing.T) {
+	tester := makePHashFormatTester()
+	tester.Test(t)
+}
+
+func FuzzPHash_BMCF_TextRoundTrip(f *testing.F) {
+	tester := makePHashFormatTester()
+	tester.Fuzz(f, crypkatest.PHashFormatFuzzTextRoundTrip)
+}
+
+func FuzzPHash_BMCF_BinaryRoundTrip(f *testing.F) {
+	tester := makePHashFormatTester()
+	tester.Fuzz(f, crypkatest.PHashFormatFuzzBinaryRoundTrip)
+}
+
+// FuzzPHash_PHCSegments feeds arbitrary text to PHC string parser, which splits it into segments and parameters.
+func FuzzPHash_PHCSegments(f *testing.F) {
+	for _, vector := range argon2PHCVectors {
+		f.Add([]byte(vector))
+	}
+	for _, seed := range malformedPHCSeeds {
+		f.Add([]byte(seed))
+	}
+
+	converter := crypka.NewPHashFormatConverter()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		crypkatest.CheckAllocBound(t, 64*1024+16*uint64(len(data)), func() {
+			_, _ = converter.LoadText(data)
+		})
+	})
+}
+
+// FuzzPHash_PHCParams fuzzes parameter list of otherwise valid argon2 hash.
+func FuzzPHash_PHCParams(f *testing.F) {
+	for _, seed := range []string{
+		"m=65536,t=2,p=1",
+		"m=32,t=3,p=4,keyid=AQIDBA,data=BAQEBAQEBAQEBAQE",
+		"m=65536,t=2,p=1,",
+		",m=65536",
+		"m==1",
+		"m=",
+		"=1",
+		"m=1,m=1,m=1",
+		"keyid=AQIDBAUGBwgJ,data=",
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, params []byte) {
+		var buf bytes.Buffer
+		buf.WriteString("$argon2id$v=19$")
+		buf.Write(params)
+		buf.WriteString("$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc")
+
+		h := crypka.Argon2PasswordHash{}
+		crypkatest.CheckAllocBound(t, 64*1024+16*uint64(buf.Len()), func() {
+			_ = h.Load(bytes.NewReader(buf.Bytes()))
+		})
+	})
+}
+
+// FuzzPHash_BMCF_LoadBinary feeds arbitrary data to BMCF parser.
+func FuzzPHash_BMCF_LoadBinary(f *testing.F) {
+	converter := crypka.NewPHashFormatConverter()
+	for _, vector := range argon2PHCVectors {
+		binary, err := converter.TextToBinary([]byte(vector), nil)
+		if err != nil {
+			f.Error(err)
+			return
+		}
+		f.Add(binary)
+	}
+	f.Add([]byte{})
+	f.Add(bytes.Repeat([]byte{0xff}, 16))
+	f.Add([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		crypkatest.CheckAllocBound(t, 64*1024+16*uint64(len(data)), func() {
+			_, _ = converter.LoadBinary(data)
+		})
+	})
+}
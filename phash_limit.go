@@ -0,0 +1,254 @@
+package crypka
+
+import (
+	"container/list"
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// LimitedPHasherHooks are called by LimitedPHasher, so it can be monitored.
+// Any of them may be nil.
+// They are called synchronously, so they should return quickly.
+type LimitedPHasherHooks struct {
+	// Called whenever request is added to or removed from queue, with current count of queued requests.
+	OnQueueDepth func(depth int)
+
+	// Called after each request with time spent in queue and time spent hashing.
+	// Hashing duration is zero, when request was not able to leave queue.
+	OnDone func(queued, hashing time.Duration, err error)
+}
+
+type limitedPHasherWaiter struct {
+	weight uint64
+	ready  chan struct{}
+}
+
+// LimitedPHasher wraps any PHasher and bounds count of concurrent HashPassword and CheckPassword calls.
+// Limit is derived from memory cost reported by wrapped hasher, so memory hard hashes like argon2 can't exhaust memory,
+// when many requests arrive at once.
+//
+// Requests are handled in FIFO order.
+//
+// It must not be copied after first use.
+type LimitedPHasher struct {
+	PHasher PHasher
+
+	// Total amount of memory in bytes, which may be used by concurrently running hashes.
+	// Hash, which requires more memory than budget, runs alone.
+	// Ignored if zero or if wrapped hasher does not report its memory cost.
+	MemoryBudget uint64
+
+	// Max count of concurrently running hashes.
+	// Defaults to GOMAXPROCS, unless MemoryBudget is set and wrapped hasher reports its memory cost,
+	// in which case only memory limits concurrency.
+	MaxConcurrency int
+
+	// Max count of requests waiting in queue. Zero means no limit.
+	MaxQueueDepth int
+
+	// Max time request may spend in queue. Zero means no limit.
+	QueueTimeout time.Duration
+
+	Hooks LimitedPHasherHooks
+
+	lock    sync.Mutex
+	used    uint64
+	running int
+	waiters list.List
+}
+
+func (h *LimitedPHasher) GetInfo() PHashAlgoInfo {
+	return h.PHasher.GetInfo()
+}
+
+func (h *LimitedPHasher) getWeight() (weight uint64) {
+	weight = h.PHasher.GetInfo().MemoryCost
+	if h.MemoryBudget > 0 && weight > h.MemoryBudget {
+		weight = h.MemoryBudget
+	}
+	return
+}
+
+func (h *LimitedPHasher) getMaxConcurrency() int {
+	if h.MaxConcurrency > 0 {
+		return h.MaxConcurrency
+	}
+	if h.MemoryBudget > 0 && h.PHasher.GetInfo().MemoryCost > 0 {
+		return 0
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// Must be called with lock held.
+func (h *LimitedPHasher) canRun(weight uint64) bool {
+	if h.MemoryBudget > 0 && h.used+weight > h.MemoryBudget {
+		return false
+	}
+
+	maxConcurrency := h.getMaxConcurrency()
+	if maxConcurrency > 0 && h.running >= maxConcurrency {
+		return false
+	}
+
+	return true
+}
+
+// Must be called with lock held.
+func (h *LimitedPHasher) notifyWaiters() {
+	for {
+		front := h.waiters.Front()
+		if front == nil {
+			return
+		}
+
+		w := front.Value.(*limitedPHasherWaiter)
+		if !h.canRun(w.weight) {
+			return
+		}
+
+		h.used += w.weight
+		h.running++
+		h.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+func (h *LimitedPHasher) reportQueueDepth(depth int) {
+	if h.Hooks.OnQueueDepth != nil {
+		h.Hooks.OnQueueDepth(depth)
+	}
+}
+
+func (h *LimitedPHasher) acquire(goCtx context.Context, weight uint64) (err error) {
+	h.lock.Lock()
+	if h.waiters.Len() == 0 && h.canRun(weight) {
+		h.used += weight
+		h.running++
+		h.lock.Unlock()
+		return
+	}
+
+	if h.MaxQueueDepth > 0 && h.waiters.Len() >= h.MaxQueueDepth {
+		h.lock.Unlock()
+		err = ErrPasswordHashQueueFull
+		return
+	}
+
+	w := &limitedPHasherWaiter{
+		weight: weight,
+		ready:  make(chan struct{}),
+	}
+	elem := h.waiters.PushBack(w)
+	depth := h.waiters.Len()
+	h.lock.Unlock()
+
+	h.reportQueueDepth(depth)
+
+	var timeout <-chan time.Time
+	if h.QueueTimeout > 0 {
+		timer := time.NewTimer(h.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-w.ready:
+	case <-goCtx.Done():
+		err = goCtx.Err()
+	case <-timeout:
+		err = ErrPasswordHashQueueTimeout
+	}
+
+	h.lock.Lock()
+	if err != nil {
+		select {
+		case <-w.ready:
+			// acquired in the meantime, so give it back
+			h.used -= weight
+			h.running--
+		default:
+			h.waiters.Remove(elem)
+		}
+		h.notifyWaiters()
+	}
+	depth = h.waiters.Len()
+	h.lock.Unlock()
+
+	h.reportQueueDepth(depth)
+	return
+}
+
+func (h *LimitedPHasher) release(weight uint64) {
+	h.lock.Lock()
+	h.used -= weight
+	h.running--
+	h.notifyWaiters()
+	depth := h.waiters.Len()
+	h.lock.Unlock()
+
+	h.reportQueueDepth(depth)
+}
+
+func (h *LimitedPHasher) run(goCtx context.Context, op func() error) (err error) {
+	if goCtx == nil {
+		goCtx = context.Background()
+	}
+
+	weight := h.getWeight()
+
+	startedAt := time.Now()
+	err = h.acquire(goCtx, weight)
+	queued := time.Since(startedAt)
+	if err != nil {
+		if h.Hooks.OnDone != nil {
+			h.Hooks.OnDone(queued, 0, err)
+		}
+		return
+	}
+
+	defer h.release(weight)
+
+	startedAt = time.Now()
+	err = op()
+	if h.Hooks.OnDone != nil {
+		h.Hooks.OnDone(queued, time.Since(startedAt), err)
+	}
+	return
+}
+
+// HashPasswordWithContext is HashPassword, which stops waiting in queue, when goCtx is done.
+// Note: hashing itself can't be cancelled once it was started.
+func (h *LimitedPHasher) HashPasswordWithContext(
+	goCtx context.Context,
+	ctx PasswordHashContext,
+	password, appendTo []byte,
+) (res []byte, err error) {
+	res = appendTo
+	err = h.run(goCtx, func() (err error) {
+		res, err = h.PHasher.HashPassword(ctx, password, appendTo)
+		return
+	})
+	return
+}
+
+// CheckPasswordWithContext is CheckPassword, which stops waiting in queue, when goCtx is done.
+// Note: hashing itself can't be cancelled once it was started.
+func (h *LimitedPHasher) CheckPasswordWithContext(
+	goCtx context.Context,
+	ctx PasswordHashContext,
+	password, hash []byte,
+) (err error) {
+	return h.run(goCtx, func() (err error) {
+		return h.PHasher.CheckPassword(ctx, password, hash)
+	})
+}
+
+func (h *LimitedPHasher) HashPassword(ctx PasswordHashContext, password, appendTo []byte) (res []byte, err error) {
+	return h.HashPasswordWithContext(context.Background(), ctx, password, appendTo)
+}
+
+func (h *LimitedPHasher) CheckPassword(ctx PasswordHashContext, password, hash []byte) (err error) {
+	return h.CheckPasswordWithContext(context.Background(), ctx, password, hash)
+}
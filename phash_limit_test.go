@@ -0,0 +1,239 @@
+package crypka_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/teawithsand/crypka"
+)
+
+type blockingPHasher struct {
+	memoryCost uint64
+	release    chan struct{}
+
+	running    int32
+	maxRunning int32
+}
+
+func (h *blockingPHasher) GetInfo() crypka.PHashAlgoInfo {
+	return crypka.PHashAlgoInfo{
+		Name:       "blocking",
+		MemoryCost: h.memoryCost,
+	}
+}
+
+func (h *blockingPHasher) enter() {
+	running := atomic.AddInt32(&h.running, 1)
+	for {
+		max := atomic.LoadInt32(&h.maxRunning)
+		if running <= max || atomic.CompareAndSwapInt32(&h.maxRunning, max, running) {
+			break
+		}
+	}
+	<-h.release
+	atomic.AddInt32(&h.running, -1)
+}
+
+func (h *blockingPHasher) HashPassword(ctx crypka.PasswordHashContext, password []byte, appendTo []byte) ([]byte, error) {
+	h.enter()
+	return append(appendTo, password...), nil
+}
+
+func (h *blockingPHasher) CheckPassword(ctx crypka.PasswordHashContext, password, hash []byte) error {
+	h.enter()
+	return nil
+}
+
+func waitForQueueDepth(t *testing.T, depth *int32, expected int32) {
+	deadline := time.Now().Add(time.Second * 5)
+	for atomic.LoadInt32(depth) != expected {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue depth did not reach %d", expected)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLimitedPHasher_LimitsConcurrency(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		hasher     *crypka.LimitedPHasher
+		memoryCost uint64
+		maxProcs   int
+		expected   int32
+	}{
+		{
+			name: "max_concurrency",
+			hasher: &crypka.LimitedPHasher{
+				MaxConcurrency: 2,
+			},
+			memoryCost: 1024,
+			expected:   2,
+		},
+		{
+			name: "memory_budget",
+			hasher: &crypka.LimitedPHasher{
+				MemoryBudget: 3 * 1024,
+			},
+			memoryCost: 1024,
+			expected:   3,
+		},
+		{
+			name: "memory_budget_smaller_than_cost",
+			hasher: &crypka.LimitedPHasher{
+				MemoryBudget: 512,
+			},
+			memoryCost: 1024,
+			expected:   1,
+		},
+		{
+			// memory budget can't limit hasher, which does not report its cost, so GOMAXPROCS is used
+			name: "memory_budget_unknown_cost",
+			hasher: &crypka.LimitedPHasher{
+				MemoryBudget: 3 * 1024,
+			},
+			memoryCost: 0,
+			maxProcs:   2,
+			expected:   2,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.maxProcs > 0 {
+				defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(tc.maxProcs))
+			}
+
+			inner := &blockingPHasher{
+				memoryCost: tc.memoryCost,
+				release:    make(chan struct{}),
+			}
+
+			var depth int32
+			h := tc.hasher
+			h.PHasher = inner
+			h.Hooks.OnQueueDepth = func(d int) {
+				atomic.StoreInt32(&depth, int32(d))
+			}
+
+			const count = 8
+			wg := sync.WaitGroup{}
+			for i := 0; i < count; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := h.HashPassword(nil, []byte("password"), nil)
+					if err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+
+			waitForQueueDepth(t, &depth, count-tc.expected)
+			for i := 0; i < count; i++ {
+				inner.release <- struct{}{}
+			}
+			wg.Wait()
+
+			if inner.maxRunning != tc.expected {
+				t.Errorf("expected at most %d hashes running, got %d", tc.expected, inner.maxRunning)
+			}
+		})
+	}
+}
+
+func TestLimitedPHasher_QueueErrors(t *testing.T) {
+	makeBusy := func(h *crypka.LimitedPHasher) (release func()) {
+		inner := &blockingPHasher{
+			release: make(chan struct{}),
+		}
+		h.PHasher = inner
+		h.MaxConcurrency = 1
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = h.CheckPassword(nil, nil, nil)
+		}()
+		for atomic.LoadInt32(&inner.running) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		return func() {
+			close(inner.release)
+			<-done
+		}
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		h := &crypka.LimitedPHasher{
+			QueueTimeout: time.Millisecond * 10,
+		}
+		release := makeBusy(h)
+		defer release()
+
+		var reportedErr error
+		h.Hooks.OnDone = func(queued, hashing time.Duration, err error) {
+			reportedErr = err
+		}
+
+		err := h.CheckPassword(nil, nil, nil)
+		if !errors.Is(err, crypka.ErrPasswordHashQueueTimeout) {
+			t.Error("expected queue timeout error, got", err)
+		}
+		if !errors.Is(reportedErr, crypka.ErrPasswordHashQueueTimeout) {
+			t.Error("expected hook to report queue timeout error, got", reportedErr)
+		}
+	})
+
+	t.Run("context_cancelled", func(t *testing.T) {
+		h := &crypka.LimitedPHasher{}
+		release := makeBusy(h)
+		defer release()
+
+		goCtx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+
+		_, err := h.HashPasswordWithContext(goCtx, nil, nil, nil)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Error("expected deadline exceeded error, got", err)
+		}
+	})
+
+	t.Run("queue_full", func(t *testing.T) {
+		h := &crypka.LimitedPHasher{
+			MaxQueueDepth: 1,
+		}
+		release := makeBusy(h)
+		defer release()
+
+		goCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var depth int32
+		h.Hooks.OnQueueDepth = func(d int) {
+			atomic.StoreInt32(&depth, int32(d))
+		}
+
+		queuedErr := make(chan error)
+		go func() {
+			queuedErr <- h.CheckPasswordWithContext(goCtx, nil, nil, nil)
+		}()
+		waitForQueueDepth(t, &depth, 1)
+
+		err := h.CheckPassword(nil, nil, nil)
+		if !errors.Is(err, crypka.ErrPasswordHashQueueFull) {
+			t.Error("expected queue full error, got", err)
+		}
+
+		cancel()
+		err = <-queuedErr
+		if !errors.Is(err, context.Canceled) {
+			t.Error("expected canceled error, got", err)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package crypka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/bits"
+	"time"
+)
+
+type PoWAlgoInfo struct {
+	BaseAlgorithmInfo
+}
+
+// PoWAlgo implements proof of work.
+// Server issues challenge, client solves it, which is expensive, and then server verifies it, which is cheap.
+//
+// Note: solution stays valid until challenge expires, so in order to prevent replays
+// user should remember nonces of challenges that were already solved, see HashPoWAlgo.SeenNonce.
+type PoWAlgo interface {
+	GetInfo() PoWAlgoInfo
+
+	// IssueChallenge creates new random challenge, which requires difficulty leading zero bits and expires after ttl.
+	IssueChallenge(ctx PoWContext, difficulty uint8, ttl time.Duration) (challenge *PoWChallenge, err error)
+
+	// SolveChallenge finds solution for given challenge.
+	// This is the expensive part.
+	SolveChallenge(ctx PoWContext, challenge *PoWChallenge) (solution uint64, err error)
+
+	// VerifySolution checks if challenge is valid, not expired and solved with given solution.
+	VerifySolution(ctx PoWContext, challenge *PoWChallenge, solution uint64) (err error)
+}
+
+// PoWChallenge is puzzle, which client has to solve.
+// It contains everything needed to solve it, so it can be sent to client in serialized form.
+type PoWChallenge struct {
+	Nonce      []byte
+	Difficulty uint8 // count of leading zero bits, which hash must have
+	ExpiresAt  int64 // unix timestamp in seconds
+
+	// Optional MAC, which prevents client from modifying challenge.
+	Tag []byte
+}
+
+const maxPoWChallengeFieldLength = 1024
+
+// IsExpired returns true if challenge is expired at given time.
+func (c *PoWChallenge) IsExpired(now time.Time) bool {
+	return now.Unix() > c.ExpiresAt
+}
+
+// writeBody writes all fields except tag.
+// This is the part, which is hashed and authenticated.
+func (c *PoWChallenge) writeBody(w io.Writer) (err error) {
+	var buf []byte
+	buf, _ = ByteVar.AppendToBuf(buf, uint64(len(c.Nonce)))
+	buf = append(buf, c.Nonce...)
+	buf = append(buf, c.Difficulty)
+	buf, _ = Byte8.AppendToBuf(buf, uint64(c.ExpiresAt))
+
+	_, err = w.Write(buf)
+	return
+}
+
+func (c *PoWChallenge) MarshalToWriter(w io.Writer) (err error) {
+	err = c.writeBody(w)
+	if err != nil {
+		return
+	}
+
+	var buf []byte
+	buf, _ = ByteVar.AppendToBuf(buf, uint64(len(c.Tag)))
+	buf = append(buf, c.Tag...)
+
+	_, err = w.Write(buf)
+	return
+}
+
+// MarshalPoWChallenge is shortcut for marshaling challenge to slice.
+func MarshalPoWChallenge(challenge *PoWChallenge) (res []byte, err error) {
+	buf := bytes.NewBuffer(nil)
+	err = challenge.MarshalToWriter(buf)
+	res = buf.Bytes()
+	return
+}
+
+// ParsePoWChallenge parses challenge marshaled with MarshalToWriter.
+func ParsePoWChallenge(data []byte) (challenge *PoWChallenge, err error) {
+	readVarBytes := func() (res []byte, err error) {
+		length, sz, err := ByteVar.DecodeAtStart(data)
+		if err != nil || length > maxPoWChallengeFieldLength || length > uint64(len(data)-sz) {
+			err = ErrPoWChallengeInvalid
+			return
+		}
+		data = data[sz:]
+
+		res = make([]byte, int(length))
+		copy(res, data)
+		data = data[len(res):]
+		return
+	}
+
+	c := &PoWChallenge{}
+
+	c.Nonce, err = readVarBytes()
+	if err != nil {
+		return
+	}
+
+	if len(data) < 1+8 {
+		err = ErrPoWChallengeInvalid
+		return
+	}
+	c.Difficulty = data[0]
+	c.ExpiresAt = int64(binary.BigEndian.Uint64(data[1:9]))
+	data = data[9:]
+
+	c.Tag, err = readVarBytes()
+	if err != nil {
+		return
+	}
+	if len(c.Tag) == 0 {
+		c.Tag = nil
+	}
+
+	if len(data) != 0 {
+		err = ErrPoWChallengeInvalid
+		return
+	}
+
+	challenge = c
+	return
+}
+
+func powLeadingZeroBits(hash []byte) (res int) {
+	for _, b := range hash {
+		if b != 0 {
+			res += bits.LeadingZeros8(b)
+			return
+		}
+		res += 8
+	}
+	return
+}
+
+func powMakeInput(challenge *PoWChallenge, solution uint64) (res []byte, err error) {
+	buf := bytes.NewBuffer(nil)
+	err = challenge.writeBody(buf)
+	if err != nil {
+		return
+	}
+
+	res, _ = Byte8.AppendToBuf(buf.Bytes(), solution)
+	return
+}
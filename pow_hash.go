@@ -0,0 +1,259 @@
+package crypka
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"io"
+	"sync"
+	"time"
+)
+
+const defaultPoWNonceLength = 16
+
+// sizedHash is implemented by hash keys, which know size of their output without computing hash.
+type sizedHash interface {
+	hashSize() int
+}
+
+// HashPoWAlgo is proof of work, which requires finding solution, which hashed along with challenge
+// yields hash with at least difficulty leading zero bits.
+//
+// Solving requires 2^difficulty hashes on average, verification requires single one.
+//
+// Without ChallengeKey, difficulty and expiration time are taken from challenge sent by client,
+// so MinDifficulty should be set and user has to make sure that challenge was issued by server.
+// Solution stays valid until challenge expires, so SeenNonce should be set in order to prevent replays.
+type HashPoWAlgo struct {
+	// Hash used to compute PoW.
+	// Any SigningKey, which behaves like hash can be used, for instance one from HashSignAlgorithm or Argon2HashKey.
+	Hash SigningKey
+
+	// Optional key used to authenticate challenges.
+	// If set, challenges can be sent to client and then back to server, so server does not have to store them.
+	// Otherwise, it's user responsibility to make sure that challenge was not modified by client.
+	ChallengeKey SymmSignKey
+
+	// Defaults to 16.
+	NonceLength int
+
+	// Minimal difficulty of challenges, which are issued and accepted.
+	// Challenges with lower difficulty are rejected with ErrPoWDifficultyTooLow,
+	// even if they were issued by this algorithm, so it can be raised at any time.
+	MinDifficulty uint8
+
+	// Optional, called with nonce of challenge, after its solution was verified.
+	// It should remember nonce until challenge expires and return true if it was seen already,
+	// in which case solution is rejected with ErrPoWChallengeReplayed.
+	// It's called concurrently, if VerifySolution is, so checking and remembering nonce must be atomic.
+	//
+	// If it's nil, single solution can be used many times until challenge expires.
+	SeenNonce func(nonce []byte) bool
+
+	// Optional, returns current time. Defaults to time.Now.
+	Now func() time.Time
+
+	hashSizeLock  sync.Mutex
+	hashSizeCache int
+}
+
+func (algo *HashPoWAlgo) GetInfo() PoWAlgoInfo {
+	return PoWAlgoInfo{
+		BaseAlgorithmInfo: BaseAlgorithmInfo{
+			Type:     PoWAlgorithmType,
+			IsSecure: true,
+		},
+	}
+}
+
+func (algo *HashPoWAlgo) now() time.Time {
+	if algo.Now != nil {
+		return algo.Now()
+	}
+	return time.Now()
+}
+
+func (algo *HashPoWAlgo) computeTag(ctx PoWContext, challenge *PoWChallenge) (tag []byte, err error) {
+	signer, err := algo.ChallengeKey.MakeSigner(ctx)
+	if err != nil {
+		return
+	}
+
+	err = challenge.writeBody(signer)
+	if err != nil {
+		return
+	}
+
+	return signer.Finalize(nil)
+}
+
+func (algo *HashPoWAlgo) computeHash(ctx PoWContext, challenge *PoWChallenge, solution uint64) (hash []byte, err error) {
+	input, err := powMakeInput(challenge, solution)
+	if err != nil {
+		return
+	}
+
+	signer, err := algo.Hash.MakeSigner(ctx)
+	if err != nil {
+		return
+	}
+
+	_, err = signer.Write(input)
+	if err != nil {
+		return
+	}
+
+	return signer.Finalize(nil)
+}
+
+// getHashSize returns size of hash without computing it, if hash knows it.
+// Otherwise hash is computed once and its size is cached, so issuing challenges stays cheap even for argon2.
+func (algo *HashPoWAlgo) getHashSize(ctx PoWContext) (size int, err error) {
+	if sh, ok := algo.Hash.(sizedHash); ok {
+		size = sh.hashSize()
+		return
+	}
+
+	algo.hashSizeLock.Lock()
+	defer algo.hashSizeLock.Unlock()
+
+	if algo.hashSizeCache == 0 {
+		var hash []byte
+		hash, err = algo.computeHash(ctx, &PoWChallenge{}, 0)
+		if err != nil {
+			return
+		}
+		algo.hashSizeCache = len(hash)
+	}
+
+	size = algo.hashSizeCache
+	return
+}
+
+func (algo *HashPoWAlgo) IssueChallenge(ctx PoWContext, difficulty uint8, ttl time.Duration) (challenge *PoWChallenge, err error) {
+	if difficulty < algo.MinDifficulty {
+		err = ErrPoWDifficultyTooLow
+		return
+	}
+
+	nonceLength := algo.NonceLength
+	if nonceLength <= 0 {
+		nonceLength = defaultPoWNonceLength
+	}
+
+	c := &PoWChallenge{
+		Nonce:      make([]byte, nonceLength),
+		Difficulty: difficulty,
+		ExpiresAt:  algo.now().Add(ttl).Unix(),
+	}
+
+	hashSize, err := algo.getHashSize(ctx)
+	if err != nil {
+		return
+	}
+	if int(difficulty) > hashSize*8 {
+		err = ErrPoWUnsolvable
+		return
+	}
+
+	_, err = io.ReadFull(ContextGetRNG(ctx), c.Nonce)
+	if err != nil {
+		return
+	}
+
+	if algo.ChallengeKey != nil {
+		c.Tag, err = algo.computeTag(ctx, c)
+		if err != nil {
+			return
+		}
+	}
+
+	challenge = c
+	return
+}
+
+func (algo *HashPoWAlgo) SolveChallenge(ctx PoWContext, challenge *PoWChallenge) (solution uint64, err error) {
+	for {
+		var hash []byte
+		hash, err = algo.computeHash(ctx, challenge, solution)
+		if err != nil {
+			return
+		}
+
+		if int(challenge.Difficulty) > len(hash)*8 {
+			err = ErrPoWUnsolvable
+			return
+		}
+
+		if powLeadingZeroBits(hash) >= int(challenge.Difficulty) {
+			return
+		}
+
+		solution++
+		if solution == 0 {
+			err = ErrPoWUnsolvable
+			return
+		}
+	}
+}
+
+// VerifySolution checks challenge and its solution.
+// See MinDifficulty and SeenNonce for checks, which have to be enabled explicitly.
+func (algo *HashPoWAlgo) VerifySolution(ctx PoWContext, challenge *PoWChallenge, solution uint64) (err error) {
+	if algo.ChallengeKey != nil {
+		var tag []byte
+		tag, err = algo.computeTag(ctx, challenge)
+		if err != nil {
+			return
+		}
+
+		if !hmac.Equal(tag, challenge.Tag) {
+			err = ErrPoWChallengeInvalid
+			return
+		}
+	} else if len(challenge.Tag) != 0 {
+		err = ErrPoWChallengeInvalid
+		return
+	}
+
+	if challenge.IsExpired(algo.now()) {
+		err = ErrPoWChallengeExpired
+		return
+	}
+
+	if challenge.Difficulty < algo.MinDifficulty {
+		err = ErrPoWDifficultyTooLow
+		return
+	}
+
+	hash, err := algo.computeHash(ctx, challenge, solution)
+	if err != nil {
+		return
+	}
+
+	if powLeadingZeroBits(hash) < int(challenge.Difficulty) {
+		err = ErrPoWSolutionInvalid
+		return
+	}
+
+	// checked last, so only nonces of solved challenges are remembered
+	if algo.SeenNonce != nil && algo.SeenNonce(challenge.Nonce) {
+		err = ErrPoWChallengeReplayed
+		return
+	}
+
+	return
+}
+
+// Registers HashPoWAlgo using SHA-256 as "pow-sha-256".
+// Registered algorithm has no ChallengeKey, so challenges have to be stored by server.
+func RegisterHashPoW(reg Registry) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	registerDefault(reg, "pow-sha-256", &HashPoWAlgo{
+		Hash: &hashKey{
+			hash: crypto.SHA256,
+		},
+	})
+}
@@ -0,0 +1,217 @@
+package crypka_test
+
+import (
+	"crypto"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/teawithsand/crypka"
+	"github.com/teawithsand/crypka/crypkatest"
+)
+
+func makeSha256PoWAlgo(t *testing.T, withChallengeKey bool) *crypka.HashPoWAlgo {
+	hashAlgo := crypka.HashSignAlgorithm{
+		Hash: crypto.SHA256,
+	}
+	hash, err := hashAlgo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	algo := &crypka.HashPoWAlgo{
+		Hash: hash,
+	}
+
+	if withChallengeKey {
+		hmacAlgo := crypka.HMACSignAlgorithm{
+			Hash:         crypto.SHA256,
+			GenKeyLength: 32,
+		}
+		algo.ChallengeKey, err = hmacAlgo.GenerateKey(nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return algo
+}
+
+func TestPoW_Hash_WithSha256(t *testing.T) {
+	tester := crypkatest.PoWTester{
+		Algo: makeSha256PoWAlgo(t, false),
+	}
+	tester.Test(t)
+}
+
+func TestPoW_Hash_WithSha256_WithChallengeKey(t *testing.T) {
+	tester := crypkatest.PoWTester{
+		Algo: makeSha256PoWAlgo(t, true),
+	}
+	tester.Test(t)
+}
+
+func TestPoW_Hash_WithArgon2(t *testing.T) {
+	tester := crypkatest.PoWTester{
+		Algo: &crypka.HashPoWAlgo{
+			Hash: &crypka.Argon2HashKey{
+				Memory:  64,
+				Time:    1,
+				Threads: 1,
+			},
+		},
+		Difficulty: 4,
+	}
+	tester.Test(t)
+}
+
+func TestPoW_Hash_DetectsModifiedChallenge(t *testing.T) {
+	algo := makeSha256PoWAlgo(t, true)
+
+	challenge, err := algo.IssueChallenge(nil, 8, time.Hour)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	challenge.Difficulty = 0
+	challenge.ExpiresAt += 3600
+
+	err = algo.VerifySolution(nil, challenge, 0)
+	if !errors.Is(err, crypka.ErrPoWChallengeInvalid) {
+		t.Error("expected modified challenge to be rejected, got", err)
+		return
+	}
+}
+
+func TestPoW_Hash_MinDifficulty(t *testing.T) {
+	algo := makeSha256PoWAlgo(t, false)
+	algo.MinDifficulty = 8
+
+	_, err := algo.IssueChallenge(nil, 4, time.Hour)
+	if !errors.Is(err, crypka.ErrPoWDifficultyTooLow) {
+		t.Error("expected error, got", err)
+		return
+	}
+
+	challenge, err := algo.IssueChallenge(nil, 8, time.Hour)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// without challenge key client can lower difficulty
+	challenge.Difficulty = 0
+	err = algo.VerifySolution(nil, challenge, 0)
+	if !errors.Is(err, crypka.ErrPoWDifficultyTooLow) {
+		t.Error("expected lowered difficulty to be rejected, got", err)
+		return
+	}
+}
+
+func TestPoW_Hash_SeenNonce(t *testing.T) {
+	algo := makeSha256PoWAlgo(t, true)
+
+	seen := map[string]struct{}{}
+	algo.SeenNonce = func(nonce []byte) bool {
+		_, ok := seen[string(nonce)]
+		seen[string(nonce)] = struct{}{}
+		return ok
+	}
+
+	challenge, err := algo.IssueChallenge(nil, 4, time.Hour)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	solution, err := algo.SolveChallenge(nil, challenge)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// find solution, which is not valid, using algorithm, which does not remember nonces
+	plain := &crypka.HashPoWAlgo{
+		Hash:         algo.Hash,
+		ChallengeKey: algo.ChallengeKey,
+	}
+	invalid := solution + 1
+	for plain.VerifySolution(nil, challenge, invalid) == nil {
+		invalid++
+	}
+
+	err = algo.VerifySolution(nil, challenge, invalid)
+	if !errors.Is(err, crypka.ErrPoWSolutionInvalid) {
+		t.Error(err)
+		return
+	}
+	if len(seen) != 0 {
+		t.Error("expected nonce of challenge, which was not solved, not to be remembered")
+		return
+	}
+
+	err = algo.VerifySolution(nil, challenge, solution)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = algo.VerifySolution(nil, challenge, solution)
+	if !errors.Is(err, crypka.ErrPoWChallengeReplayed) {
+		t.Error("expected replayed solution to be rejected, got", err)
+		return
+	}
+}
+
+func TestPoW_Hash_RejectsTooHighDifficulty(t *testing.T) {
+	algo := &crypka.HashPoWAlgo{
+		Hash: &crypka.Argon2HashKey{
+			Memory:    64,
+			Time:      1,
+			Threads:   1,
+			KeyLength: 4,
+		},
+	}
+
+	_, err := algo.IssueChallenge(nil, 33, time.Hour)
+	if !errors.Is(err, crypka.ErrPoWUnsolvable) {
+		t.Error("expected error, got", err)
+		return
+	}
+}
+
+type countingHashKey struct {
+	crypka.SigningKey
+	calls int
+}
+
+func (k *countingHashKey) MakeSigner(ctx crypka.KeyContext) (crypka.Signer, error) {
+	k.calls++
+	return k.SigningKey.MakeSigner(ctx)
+}
+
+func TestPoW_Hash_IssueDoesNotHash(t *testing.T) {
+	inner, err := (&crypka.HashSignAlgorithm{Hash: crypto.SHA256}).GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	hash := &countingHashKey{SigningKey: inner}
+	algo := &crypka.HashPoWAlgo{
+		Hash: hash,
+	}
+
+	for i := 0; i < 10; i++ {
+		_, err = algo.IssueChallenge(nil, 8, time.Hour)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	if hash.calls > 1 {
+		t.Error("expected size of hash to be computed at most once, but hash was computed", hash.calls, "times")
+		return
+	}
+}
@@ -1 +1,49 @@
 package crypka
+
+// Naming convention of algorithms registered in registries.
+//
+// Each name consists of one or more segments separated with single "-".
+// Segments consist of lowercase ASCII letters and digits only. First segment starts with letter.
+// Names are unique across all algorithm types, type of algorithm is given by AlgorithmInfo rather than by its name.
+//
+// Names of built-in algorithms are built like:
+//   - hashes: "<hash>", like "sha-256" or "sha3-512"
+//   - HMACs: "hmac-<hash>", like "hmac-sha-256"
+//   - AEAD ciphers: "<cipher>-<nonce type>", where nonce type is "counter" or "rng", like "aes-256-gcm-counter"
+//   - key wrapping: "aes-<key bits>-kw" and "aes-<key bits>-kwp" for padded variant
+//   - stream encryption: "cpk-stream-<inner symmetric encryption>", like "cpk-stream-aes-256-gcm-counter"
+//   - signing: "<algorithm>-<hash used to compress data>", like "ed25519-sha-512"
+//   - key exchange: "<algorithm>", like "x25519"
+//   - asymmetric encryption: "<key exchange>-<symmetric encryption>", like "x25519-aes-256-gcm-counter"
+//   - RNGs: "<source>-rng", like "crypto-rng" or "chacha20-rng"
+//   - password hashing: "<algorithm>", like "argon2id"
+//   - proof of work: "pow-<hash>", like "pow-sha-256"
+//   - paddings: "pad-<scheme>", like "pad-pkcs7" or "pad-padme"
+//
+// Algorithms, which are meant for testing only, like XorEncSymmAlgo, are never registered by default.
+
+// IsValidAlgorithmName returns true if given name follows naming convention.
+func IsValidAlgorithmName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	if name[0] < 'a' || name[0] > 'z' {
+		return false
+	}
+
+	previousDash := false
+	for _, c := range []byte(name) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			previousDash = false
+		case c == '-':
+			if previousDash {
+				return false
+			}
+			previousDash = true
+		default:
+			return false
+		}
+	}
+	return !previousDash
+}
@@ -18,6 +18,11 @@ func (algo *HashCompressRNGAlgo) GetInfo() RNGAlgoInfo {
 }
 
 func (algo *HashCompressRNGAlgo) MakeRng(ctx RNGGenerationContext, seed []byte) (rng RNG, err error) {
+	err = contextCheckPolicy(ctx, MakeRNGPolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
 	if len(seed) < algo.MinSeedLength {
 		err = ErrRNGInvalidSeed
 		return
@@ -43,5 +48,5 @@ func (algo *HashCompressRNGAlgo) MakeRng(ctx RNGGenerationContext, seed []byte)
 		compressedSeed = compressedSeed[:maxSeedLength]
 	}
 
-	return algo.InnerAlgo.MakeRng(ctx, compressedSeed)
+	return algo.InnerAlgo.MakeRng(contextForInnerAlgorithm(ctx), compressedSeed)
 }
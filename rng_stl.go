@@ -22,6 +22,11 @@ func (algo *CryptoRNGAlgo) GetInfo() RNGAlgoInfo {
 }
 
 func (algo *CryptoRNGAlgo) MakeRng(ctx RNGGenerationContext, seed []byte) (rng RNG, err error) {
+	err = contextCheckPolicy(ctx, MakeRNGPolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
 	rng = rand.Reader
 	return
 }
@@ -44,6 +49,11 @@ func (algo *MathRNGAlgo) GetInfo() RNGAlgoInfo {
 }
 
 func (algo *MathRNGAlgo) MakeRng(ctx RNGGenerationContext, seed []byte) (rng RNG, err error) {
+	err = contextCheckPolicy(ctx, MakeRNGPolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
 	if len(seed) != 8 {
 		err = ErrRNGInvalidSeed
 		return
@@ -60,3 +70,13 @@ func (algo *MathRNGAlgo) MakeRng(ctx RNGGenerationContext, seed []byte) (rng RNG
 	rng = plainRng
 	return
 }
+
+// Registers CryptoRNGAlgo as "crypto-rng".
+// MathRNGAlgo is not registered, since it's not secure.
+func RegisterSTLRNGs(reg Registry) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	registerDefault(reg, "crypto-rng", &CryptoRNGAlgo{})
+}
@@ -1,7 +1,10 @@
 package crypka
 
 import (
+	"crypto/aes"
 	"crypto/cipher"
+
+	"golang.org/x/crypto/chacha20"
 )
 
 // RNG, which uses some stream encryption algorithm in order to generate random data from small seed.
@@ -35,6 +38,11 @@ func (algo *EncStreamRNGAlgo) GetInfo() RNGAlgoInfo {
 }
 
 func (algo *EncStreamRNGAlgo) MakeRng(ctx RNGGenerationContext, seed []byte) (rng RNG, err error) {
+	err = contextCheckPolicy(ctx, MakeRNGPolicyOperation, algo, -1)
+	if err != nil {
+		return
+	}
+
 	if len(seed) != algo.KeyLength {
 		err = ErrRNGInvalidSeed
 		return
@@ -149,3 +157,46 @@ func (rng *cipherRNG) Read(buf []byte) (sz int, err error) {
 		}
 	}
 }
+
+// Amount of bytes generated by single cipher of default stream cipher RNGs before it's reseeded.
+const streamCipherRNGMaxGeneratedBytes = 1 << 32
+
+func newAESCTRRNGAlgo() *EncStreamRNGAlgo {
+	return &EncStreamRNGAlgo{
+		CipherFactory: func(key []byte) (res cipher.Stream, err error) {
+			block, err := aes.NewCipher(key)
+			if err != nil {
+				return
+			}
+			iv := make([]byte, aes.BlockSize)
+			res = cipher.NewCTR(block, iv)
+			return
+		},
+		KeyLength:               32,
+		ResedKeyLength:          32,
+		CipherMaxGeneratedBytes: streamCipherRNGMaxGeneratedBytes,
+	}
+}
+
+func newChaCha20RNGAlgo() *EncStreamRNGAlgo {
+	return &EncStreamRNGAlgo{
+		CipherFactory: func(key []byte) (res cipher.Stream, err error) {
+			nonce := make([]byte, chacha20.NonceSize)
+			return chacha20.NewUnauthenticatedCipher(key, nonce)
+		},
+		KeyLength:               chacha20.KeySize,
+		ResedKeyLength:          chacha20.KeySize,
+		CipherMaxGeneratedBytes: streamCipherRNGMaxGeneratedBytes,
+	}
+}
+
+// Registers seedable RNGs based on AES-256 in CTR mode and ChaCha20 as "aes-256-ctr-rng" and "chacha20-rng".
+// Both require 32 byte seed and reseed themselves every 4GB of generated data.
+func RegisterStreamCipherRNGs(reg Registry) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	registerDefault(reg, "aes-256-ctr-rng", newAESCTRRNGAlgo())
+	registerDefault(reg, "chacha20-rng", newChaCha20RNGAlgo())
+}
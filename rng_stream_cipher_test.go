@@ -1,6 +1,7 @@
 package crypka_test
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"testing"
@@ -97,3 +98,37 @@ func BenchmarkRNG_Cipher_WithAES128CTR(b *testing.B) {
 		tester.Benchmark(b)
 	})
 }
+
+func TestRNG_Cipher_SeededTestRNG(t *testing.T) {
+	read := func(seed uint64) []byte {
+		return crypkatest.RNGReadBuffer(crypkatest.NewSeededRNG(seed), 64)
+	}
+
+	if !bytes.Equal(read(42), read(42)) {
+		t.Error("expected same seed to yield same data")
+	}
+	if bytes.Equal(read(42), read(43)) {
+		t.Error("expected different seeds to yield different data")
+	}
+
+	util := &crypkatest.TestScopeUtil{}
+	util.LogSeed(t)
+	lhs := crypkatest.RNGReadBuffer(util.GetTestScopeFor(t).GetRNG(), 64)
+	rhs := crypkatest.RNGReadBuffer(util.GetTestScopeFor(t).GetRNG(), 64)
+	if !bytes.Equal(lhs, rhs) {
+		t.Error("expected test scopes to be reproducible")
+	}
+
+	t.Run("other", func(t *testing.T) {
+		other := crypkatest.RNGReadBuffer(util.GetTestScopeFor(t).GetRNG(), 64)
+		if bytes.Equal(lhs, other) {
+			t.Error("expected different tests to get different streams")
+		}
+	})
+
+	lhs = crypkatest.RNGReadBuffer(util.GetTestScope().GetRNG(), 64)
+	rhs = crypkatest.RNGReadBuffer(util.GetTestScope().GetRNG(), 64)
+	if bytes.Equal(lhs, rhs) {
+		t.Error("expected untitled test scopes to get different streams")
+	}
+}
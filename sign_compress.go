@@ -16,7 +16,13 @@ func (s *compressSigner) Finalize(appendTo []byte) (res []byte, err error) {
 		return
 	}
 
-	return s.actualSigner(s.ctx, compressedData)
+	sign, err := s.actualSigner(s.ctx, compressedData)
+	if err != nil {
+		return
+	}
+
+	res = append(appendTo, sign...)
+	return
 }
 
 type CompressVerifier struct {
@@ -2,6 +2,7 @@ package crypka
 
 import (
 	"crypto/ed25519"
+	"fmt"
 	"io"
 )
 
@@ -19,8 +20,20 @@ func innerActualVerifyEd25519(ctx KeyContext, key ed25519.PublicKey, data, sign
 
 // Ed25519SignAsymAlgo, which uses compressor given to prevent buffering data before signing.
 // This allows for easier signing of arbitrarily sized data.
+//
+// AlgoName is name of algorithm, which keys are tagged with, when they are marshaled to text or JSON.
+// Registry sets it to name algorithm is registered with. Keys of algorithm without name can't be marshaled that way.
 type Ed25519SignAsymAlgo struct {
 	Compressor SigningKey
+	AlgoName   string
+}
+
+func (a *Ed25519SignAsymAlgo) getAlgoName() string {
+	return a.AlgoName
+}
+
+func (a *Ed25519SignAsymAlgo) setAlgoName(name string) {
+	a.AlgoName = name
 }
 
 func (a *Ed25519SignAsymAlgo) GetInfo() SignAlgoInfo {
@@ -33,6 +46,11 @@ func (a *Ed25519SignAsymAlgo) GetInfo() SignAlgoInfo {
 }
 
 func (a *Ed25519SignAsymAlgo) GenerateKeyPair(ctx KeyGenerationContext, rng RNG) (sk SigningKey, vk VerifyingKey, err error) {
+	err = contextCheckPolicy(ctx, KeyGenerationPolicyOperation, a, -1)
+	if err != nil {
+		return
+	}
+
 	rng = FallbackContextGetRNG(ctx, rng)
 
 	rawVk, rawSk, err := ed25519.GenerateKey(rng)
@@ -40,30 +58,18 @@ func (a *Ed25519SignAsymAlgo) GenerateKeyPair(ctx KeyGenerationContext, rng RNG)
 		return
 	}
 
-	sk = &ed25519SigningKey{
-		compressSigningKey: CompressSigningKey{
-			Compressor: a.Compressor,
-			ActualSigner: func(ctx KeyContext, data []byte) (sign []byte, err error) {
-				return innerActualSignEd25519(ctx, rawSk, data)
-			},
-		},
-		signingKey: rawSk,
-	}
-
-	vk = &ed25519VerifyingKey{
-		compressVerifyingKey: CompressVerifyingKey{
-			Compressor: a.Compressor,
-			ActualVerifier: func(ctx KeyContext, sign, data []byte) (err error) {
-				return innerActualVerifyEd25519(ctx, rawVk, data, sign)
-			},
-		},
-		verifyingKey: rawVk,
-	}
+	sk = a.makeSigningKey(rawSk)
 
+	vk = a.makeVerifyingKey(rawVk)
 	return
 }
 
 func (a *Ed25519SignAsymAlgo) ParseSigningKey(ctx KeyParseContext, key []byte) (sk SigningKey, err error) {
+	err = contextCheckPolicy(ctx, KeyParsePolicyOperation, a, -1)
+	if err != nil {
+		return
+	}
+
 	if len(key) != ed25519.PrivateKeySize {
 		err = ErrKeyParseField
 		return
@@ -71,23 +77,52 @@ func (a *Ed25519SignAsymAlgo) ParseSigningKey(ctx KeyParseContext, key []byte) (
 
 	// do copy, so modifying key slice won't modify inner key here
 	// just for safety
-	rawSk := make(ed25519.PrivateKey, ed25519.PrivateKeySize)
-	copy(rawSk, key)
+	sk = a.makeSigningKey(ed25519.PrivateKey(append([]byte{}, key...)))
+	return
+}
 
+// makeSigningKey wraps raw key.
+//
+// Note: key material is always kept on go heap, so LockKeyMemory is ignored.
+// Stdlib's ed25519 caches keys using weak pointers, which can't point to memory allocated outside of go heap.
+func (a *Ed25519SignAsymAlgo) makeSigningKey(rawSk ed25519.PrivateKey) (sk *ed25519SigningKey) {
 	sk = &ed25519SigningKey{
-		compressSigningKey: CompressSigningKey{
+		signingKey: rawSk,
+		algo:       a,
+	}
+	sk.compressSigningKey = CompressSigningKey{
+		Compressor: a.Compressor,
+		ActualSigner: func(ctx KeyContext, data []byte) (sign []byte, err error) {
+			// signers may outlive key, so check is required here as well
+			if sk.destroyed {
+				err = ErrKeyDestroyed
+				return
+			}
+			return innerActualSignEd25519(ctx, sk.signingKey, data)
+		},
+	}
+	return
+}
+
+func (a *Ed25519SignAsymAlgo) makeVerifyingKey(rawVk ed25519.PublicKey) *ed25519VerifyingKey {
+	return &ed25519VerifyingKey{
+		compressVerifyingKey: CompressVerifyingKey{
 			Compressor: a.Compressor,
-			ActualSigner: func(ctx KeyContext, data []byte) (sign []byte, err error) {
-				return innerActualSignEd25519(ctx, rawSk, data)
+			ActualVerifier: func(ctx KeyContext, sign, data []byte) (err error) {
+				return innerActualVerifyEd25519(ctx, rawVk, data, sign)
 			},
 		},
-		signingKey: rawSk,
+		verifyingKey: rawVk,
+		algo:         a,
 	}
-
-	return
 }
 
 func (a *Ed25519SignAsymAlgo) ParseVerifyingKey(ctx KeyParseContext, key []byte) (vk VerifyingKey, err error) {
+	err = contextCheckPolicy(ctx, KeyParsePolicyOperation, a, -1)
+	if err != nil {
+		return
+	}
+
 	if len(key) != ed25519.PublicKeySize {
 		err = ErrKeyParseField
 		return
@@ -98,36 +133,65 @@ func (a *Ed25519SignAsymAlgo) ParseVerifyingKey(ctx KeyParseContext, key []byte)
 	rawVk := make(ed25519.PublicKey, ed25519.PublicKeySize)
 	copy(rawVk, key)
 
-	vk = &ed25519VerifyingKey{
-		compressVerifyingKey: CompressVerifyingKey{
-			Compressor: a.Compressor,
-			ActualVerifier: func(ctx KeyContext, sign, data []byte) (err error) {
-				return innerActualVerifyEd25519(ctx, rawVk, data, sign)
-			},
-		},
-		verifyingKey: rawVk,
-	}
-
+	vk = a.makeVerifyingKey(rawVk)
 	return
 }
 
 type ed25519SigningKey struct {
+	secretKeyMarshalGuard
+
 	compressSigningKey CompressSigningKey
 	signingKey         ed25519.PrivateKey
+	algo               *Ed25519SignAsymAlgo
+	destroyed          bool
 }
 
 func (sk *ed25519SigningKey) MakeSigner(ctx KeyContext) (signer Signer, err error) {
+	if sk.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
 	return sk.compressSigningKey.MakeSigner(ctx)
 }
 
+// DerivePublicKey returns VerifyingKey matching this key.
+func (sk *ed25519SigningKey) DerivePublicKey(ctx KeyContext) (vk interface{}, err error) {
+	if sk.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	rawVk := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	copy(rawVk, sk.signingKey.Public().(ed25519.PublicKey))
+
+	vk = sk.algo.makeVerifyingKey(rawVk)
+	return
+}
+
+func (sk *ed25519SigningKey) Destroy() {
+	zeroBytes(sk.signingKey)
+	sk.signingKey = nil
+	sk.destroyed = true
+}
+
 func (sk *ed25519SigningKey) MarshalToWriter(w io.Writer) (err error) {
+	if sk.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
 	_, err = w.Write(sk.signingKey)
 	return
 }
 
+func (sk *ed25519SigningKey) toSerializedKey() (SerializedKey, error) {
+	return makeSerializedKey(sk.algo.AlgoName, SecretSerializedKeyType, sk)
+}
+
 type ed25519VerifyingKey struct {
 	compressVerifyingKey CompressVerifyingKey
 	verifyingKey         ed25519.PublicKey
+	algo                 *Ed25519SignAsymAlgo
 }
 
 func (vk *ed25519VerifyingKey) MakeVerifier(ctx KeyContext) (verifier Verifier, err error) {
@@ -139,6 +203,26 @@ func (vk *ed25519VerifyingKey) MarshalToWriter(w io.Writer) (err error) {
 	return
 }
 
+func (vk *ed25519VerifyingKey) toSerializedKey() (SerializedKey, error) {
+	return makeSerializedKey(vk.algo.AlgoName, PublicSerializedKeyType, vk)
+}
+
+func (vk *ed25519VerifyingKey) MarshalText() (res []byte, err error) {
+	sk, err := vk.toSerializedKey()
+	if err != nil {
+		return
+	}
+	return sk.MarshalText()
+}
+
+func (vk *ed25519VerifyingKey) MarshalJSON() (res []byte, err error) {
+	sk, err := vk.toSerializedKey()
+	if err != nil {
+		return
+	}
+	return sk.MarshalJSON()
+}
+
 type RegisterEd25519Options struct {
 	CompressorData []struct {
 		Suffix     string
@@ -146,6 +230,12 @@ type RegisterEd25519Options struct {
 	}
 }
 
+// Registers ed25519 as "ed25519-<suffix>" for each compressor in options.
+// If compressor is nil, then symmetric signing algorithm named suffix is taken from registry.
+// By default, compressors are "sha-256", "sha-512", "sha3-256" and "sha3-512", so they have to be registered first.
+//
+// Names, which are already registered, are skipped.
+// Panics if compressor is missing from registry.
 func RegisterEd25519(reg Registry, options RegisterEd25519Options) {
 	if reg == nil {
 		reg = GlobalRegistry
@@ -177,22 +267,27 @@ func RegisterEd25519(reg Registry, options RegisterEd25519Options) {
 	}
 
 	for _, config := range options.CompressorData {
+		name := "ed25519-" + config.Suffix
+		if reg.GetAlgo(name) != nil {
+			continue
+		}
+
 		if config.Compressor == nil {
 			var signingAlgo SignSymmAlgo
 			innerErr := reg.GetAlgorithmTyped(config.Suffix, &signingAlgo)
 			if innerErr != nil {
-				continue
+				panic(fmt.Errorf("crypka: can't register ed25519 with compressor %s: %w", config.Suffix, innerErr))
 			}
 
 			key, innerErr := signingAlgo.GenerateKey(nil, nil)
 			if innerErr != nil {
-				continue
+				panic(fmt.Errorf("crypka: can't register ed25519 with compressor %s: %w", config.Suffix, innerErr))
 			}
 
 			config.Compressor = key
 		}
 
-		reg.RegisterAlgo("ed25519-"+config.Suffix, Ed25519SignAsymAlgo{
+		reg.RegisterAlgo(name, &Ed25519SignAsymAlgo{
 			Compressor: config.Compressor,
 		})
 	}
@@ -0,0 +1,197 @@
+package crypka
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const openSSHPrivateKeyMagic = "openssh-key-v1\x00"
+const openSSHPrivateKeyPEMType = "OPENSSH PRIVATE KEY"
+
+type openSSHPrivateKey struct {
+	CipherName   string
+	KdfName      string
+	KdfOpts      string
+	NumKeys      uint32
+	PubKey       []byte
+	PrivKeyBlock []byte
+}
+
+type openSSHEd25519PrivateKey struct {
+	Check1  uint32
+	Check2  uint32
+	KeyType string
+	Pub     []byte
+	Priv    []byte
+	Comment string
+	Pad     []byte `ssh:"rest"`
+}
+
+// marshalOpenSSHEd25519 encodes key in unencrypted openssh-key-v1 format.
+// It's implemented here, since x/crypto/ssh is able to parse it, but not to create it.
+func marshalOpenSSHEd25519(ctx KeyContext, key ed25519.PrivateKey) (res []byte, err error) {
+	sshPub, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		return
+	}
+
+	var checkBuf [4]byte
+	_, err = io.ReadFull(ContextGetRNG(ctx), checkBuf[:])
+	if err != nil {
+		return
+	}
+	check := binary.BigEndian.Uint32(checkBuf[:])
+
+	private := openSSHEd25519PrivateKey{
+		Check1:  check,
+		Check2:  check,
+		KeyType: ssh.KeyAlgoED25519,
+		Pub:     []byte(key.Public().(ed25519.PublicKey)),
+		Priv:    []byte(key),
+	}
+
+	// private block has to be padded to cipher's block size, which is 8 for "none" cipher
+	const blockSize = 8
+	unpaddedLength := len(ssh.Marshal(private))
+	for i := 0; (unpaddedLength+i)%blockSize != 0; i++ {
+		private.Pad = append(private.Pad, byte(i+1))
+	}
+
+	encoded := ssh.Marshal(openSSHPrivateKey{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PubKey:       sshPub.Marshal(),
+		PrivKeyBlock: ssh.Marshal(private),
+	})
+
+	res = pem.EncodeToMemory(&pem.Block{
+		Type:  openSSHPrivateKeyPEMType,
+		Bytes: append([]byte(openSSHPrivateKeyMagic), encoded...),
+	})
+	return
+}
+
+func (sk *ed25519SigningKey) ExportKeyFormat(ctx KeyContext, format KeyFormat) (data []byte, err error) {
+	if sk.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
+	key := okpKey{
+		Secret: sk.signingKey.Seed(),
+		Public: []byte(sk.signingKey.Public().(ed25519.PublicKey)),
+	}
+
+	switch format {
+	case PEMKeyFormat:
+		return okpEd25519.MarshalPEM(key)
+	case JWKKeyFormat:
+		return okpEd25519.MarshalJWK(key)
+	case OpenSSHKeyFormat:
+		return marshalOpenSSHEd25519(ctx, sk.signingKey)
+	default:
+		err = ErrKeyFormatUnsupported
+		return
+	}
+}
+
+func (vk *ed25519VerifyingKey) ExportKeyFormat(ctx KeyContext, format KeyFormat) (data []byte, err error) {
+	key := okpKey{
+		Public: []byte(vk.verifyingKey),
+	}
+
+	switch format {
+	case PEMKeyFormat:
+		return okpEd25519.MarshalPEM(key)
+	case JWKKeyFormat:
+		return okpEd25519.MarshalJWK(key)
+	case OpenSSHKeyFormat:
+		var sshPub ssh.PublicKey
+		sshPub, err = ssh.NewPublicKey(vk.verifyingKey)
+		if err != nil {
+			return
+		}
+
+		data = ssh.MarshalAuthorizedKey(sshPub)
+		return
+	default:
+		err = ErrKeyFormatUnsupported
+		return
+	}
+}
+
+func (a *Ed25519SignAsymAlgo) importOpenSSH(ctx KeyParseContext, data []byte) (key interface{}, err error) {
+	if block, _ := pem.Decode(data); block != nil {
+		var rawKey interface{}
+		rawKey, err = ssh.ParseRawPrivateKey(data)
+		if err != nil {
+			err = ErrKeyParseField
+			return
+		}
+
+		switch typedKey := rawKey.(type) {
+		case ed25519.PrivateKey:
+			return a.ParseSigningKey(ctx, typedKey)
+		case *ed25519.PrivateKey:
+			return a.ParseSigningKey(ctx, *typedKey)
+		default:
+			err = ErrKeyParseField
+			return
+		}
+	}
+
+	sshPub, _, _, rest, err := ssh.ParseAuthorizedKey(data)
+	if err != nil || len(bytes.TrimSpace(rest)) != 0 {
+		err = ErrKeyParseField
+		return
+	}
+
+	cryptoPub, ok := sshPub.(ssh.CryptoPublicKey)
+	if !ok {
+		err = ErrKeyParseField
+		return
+	}
+
+	pub, ok := cryptoPub.CryptoPublicKey().(ed25519.PublicKey)
+	if !ok {
+		err = ErrKeyParseField
+		return
+	}
+
+	return a.ParseVerifyingKey(ctx, pub)
+}
+
+func (a *Ed25519SignAsymAlgo) ImportKeyFormat(ctx KeyParseContext, format KeyFormat, data []byte) (key interface{}, err error) {
+	var okp okpKey
+	switch format {
+	case PEMKeyFormat:
+		okp, err = okpEd25519.ParsePEM(data, ed25519.SeedSize)
+	case JWKKeyFormat:
+		okp, err = okpEd25519.ParseJWK(data, ed25519.SeedSize)
+	case OpenSSHKeyFormat:
+		return a.importOpenSSH(ctx, data)
+	default:
+		err = ErrKeyFormatUnsupported
+	}
+	if err != nil {
+		return
+	}
+
+	if okp.Secret == nil {
+		return a.ParseVerifyingKey(ctx, okp.Public)
+	}
+
+	rawSk := ed25519.NewKeyFromSeed(okp.Secret)
+	if okp.Public != nil && !bytes.Equal(okp.Public, rawSk.Public().(ed25519.PublicKey)) {
+		err = ErrKeyParseField
+		return
+	}
+
+	return a.ParseSigningKey(ctx, rawSk)
+}
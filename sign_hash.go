@@ -36,6 +36,10 @@ func (w *hashSignerVerifier) Verify(sign []byte) (err error) {
 	return
 }
 
+func (k *hashKey) hashSize() int {
+	return k.hash.Size()
+}
+
 func (k *hashKey) MakeSigner(key KeyContext) (Signer, error) {
 	return &hashSignerVerifier{
 		hash: k.hash.New(),
@@ -57,11 +61,21 @@ func (a *HashSignAlgorithm) GetInfo() SignAlgoInfo {
 }
 
 func (a *HashSignAlgorithm) GenerateKey(ctx KeyGenerationContext, rng RNG) (SymmSignKey, error) {
+	err := contextCheckPolicy(ctx, KeyGenerationPolicyOperation, a, -1)
+	if err != nil {
+		return nil, err
+	}
+
 	return &hashKey{
 		hash: a.Hash,
 	}, nil
 }
 func (a *HashSignAlgorithm) ParseSymmSignKey(ctx KeyParseContext, data []byte) (SymmSignKey, error) {
+	err := contextCheckPolicy(ctx, KeyParsePolicyOperation, a, -1)
+	if err != nil {
+		return nil, err
+	}
+
 	return &hashKey{
 		hash: a.Hash,
 	}, nil
@@ -78,8 +92,8 @@ func RegisterSTLHashes(reg Registry) {
 		reg = GlobalRegistry
 	}
 
-	reg.RegisterAlgo("sha-256", &HashSignAlgorithm{crypto.SHA256})
-	reg.RegisterAlgo("sha-512", &HashSignAlgorithm{crypto.SHA512})
-	reg.RegisterAlgo("sha3-256", &HashSignAlgorithm{crypto.SHA3_256})
-	reg.RegisterAlgo("sha3-512", &HashSignAlgorithm{crypto.SHA3_512})
+	registerDefault(reg, "sha-256", &HashSignAlgorithm{crypto.SHA256})
+	registerDefault(reg, "sha-512", &HashSignAlgorithm{crypto.SHA512})
+	registerDefault(reg, "sha3-256", &HashSignAlgorithm{crypto.SHA3_256})
+	registerDefault(reg, "sha3-512", &HashSignAlgorithm{crypto.SHA3_512})
 }
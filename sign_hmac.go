@@ -11,8 +11,20 @@ import (
 type HMACSignAlgorithm struct {
 	Hash         crypto.Hash
 	MinKeyLength int
-	MaxKeyLength int
-	GenKeyLength int
+	MaxKeyLength int // zero means no limit
+	GenKeyLength int // must not be less than MinKeyLength
+
+	// Name of algorithm, which keys are tagged with, when they are marshaled to text or JSON.
+	// Registry sets it to name algorithm is registered with.
+	AlgoName string
+}
+
+func (a *HMACSignAlgorithm) getAlgoName() string {
+	return a.AlgoName
+}
+
+func (a *HMACSignAlgorithm) setAlgoName(name string) {
+	a.AlgoName = name
 }
 
 func (a *HMACSignAlgorithm) GetInfo() SignAlgoInfo {
@@ -25,15 +37,28 @@ func (a *HMACSignAlgorithm) GetInfo() SignAlgoInfo {
 }
 
 func (a *HMACSignAlgorithm) GenerateKey(ctx KeyGenerationContext, rng RNG) (key SymmSignKey, err error) {
-	keyBuf := make([]byte, a.GenKeyLength)
+	// algorithm would generate keys, which it's not able to parse
+	if a.GenKeyLength < a.MinKeyLength || (a.MaxKeyLength > 0 && a.GenKeyLength > a.MaxKeyLength) {
+		err = ErrKeyGenerationInvalidLength
+		return
+	}
+
+	err = contextCheckPolicy(ctx, KeyGenerationPolicyOperation, a, a.GenKeyLength)
+	if err != nil {
+		return
+	}
+
+	keyBuf := allocKeyMaterial(ctx, a.GenKeyLength)
 
 	rng = FallbackContextGetRNG(ctx, rng)
 	_, err = io.ReadFull(rng, keyBuf)
 	if err != nil {
+		destroyKeyMaterial(keyBuf)
 		return
 	}
 
 	return &hmacKey{
+		algo: a,
 		hash: a.Hash,
 		key:  keyBuf,
 	}, nil
@@ -44,39 +69,73 @@ func (a *HMACSignAlgorithm) ParseSymmSignKey(ctx KeyGenerationContext, data []by
 		err = ErrKeyParseField
 		return
 	}
-	if len(data) > a.MaxKeyLength {
+	if a.MaxKeyLength > 0 && len(data) > a.MaxKeyLength {
 		err = ErrKeyParseField
 		return
 	}
 
+	err = contextCheckPolicy(ctx, KeyParsePolicyOperation, a, len(data))
+	if err != nil {
+		return
+	}
+
+	// do copy, so destroying key won't modify data given
 	return &hmacKey{
+		algo: a,
 		hash: a.Hash,
-		key:  data,
+		key:  copyKeyMaterial(ctx, data),
 	}, nil
 }
 
 type hmacKey struct {
-	hash crypto.Hash
-	key  []byte
+	secretKeyMarshalGuard
+
+	algo      *HMACSignAlgorithm
+	hash      crypto.Hash
+	key       []byte
+	destroyed bool
 }
 
 func (k *hmacKey) MakeSigner(key KeyContext) (Signer, error) {
+	if k.destroyed {
+		return nil, ErrKeyDestroyed
+	}
+
 	return &hmacSignerVerifier{
 		hash: hmac.New(k.hash.New, k.key),
 	}, nil
 }
 
 func (k *hmacKey) MakeVerifier(key KeyContext) (Verifier, error) {
+	if k.destroyed {
+		return nil, ErrKeyDestroyed
+	}
+
 	return &hmacSignerVerifier{
 		hash: hmac.New(k.hash.New, k.key),
 	}, nil
 }
 
 func (sk *hmacKey) MarshalToWriter(w io.Writer) (err error) {
+	if sk.destroyed {
+		err = ErrKeyDestroyed
+		return
+	}
+
 	_, err = w.Write(sk.key)
 	return
 }
 
+func (sk *hmacKey) toSerializedKey() (SerializedKey, error) {
+	return makeSerializedKey(sk.algo.AlgoName, SecretSerializedKeyType, sk)
+}
+
+func (sk *hmacKey) Destroy() {
+	destroyKeyMaterial(sk.key)
+	sk.key = nil
+	sk.destroyed = true
+}
+
 type hmacSignerVerifier struct {
 	hash hash.Hash
 }
@@ -123,22 +182,22 @@ func RegisterSTLHMACs(reg Registry, options RegisterSTLHMACsOptions) {
 		options.GenKeyLength = 0
 	}
 
-	reg.RegisterAlgo("hmac-sha-256", &HMACSignAlgorithm{
+	registerDefault(reg, "hmac-sha-256", &HMACSignAlgorithm{
 		Hash:         crypto.SHA256,
 		MinKeyLength: options.MinKeyLength,
 		GenKeyLength: options.GenKeyLength,
 	})
-	reg.RegisterAlgo("hmac-sha-512", &HMACSignAlgorithm{
+	registerDefault(reg, "hmac-sha-512", &HMACSignAlgorithm{
 		Hash:         crypto.SHA512,
 		MinKeyLength: options.MinKeyLength,
 		GenKeyLength: options.GenKeyLength,
 	})
-	reg.RegisterAlgo("hmac-sha3-256", &HMACSignAlgorithm{
+	registerDefault(reg, "hmac-sha3-256", &HMACSignAlgorithm{
 		Hash:         crypto.SHA3_256,
 		MinKeyLength: options.MinKeyLength,
 		GenKeyLength: options.GenKeyLength,
 	})
-	reg.RegisterAlgo("hmac-sha3-512", &HMACSignAlgorithm{
+	registerDefault(reg, "hmac-sha3-512", &HMACSignAlgorithm{
 		Hash:         crypto.SHA3_512,
 		MinKeyLength: options.MinKeyLength,
 		GenKeyLength: options.GenKeyLength,
@@ -28,3 +28,45 @@ func TestSign_HMAC_CanRegisterWithDefaultOptions(t *testing.T) {
 
 	crypka.RegisterSTLHMACs(reg, crypka.RegisterSTLHMACsOptions{})
 }
+
+func TestSign_HMAC_VerifyTiming(t *testing.T) {
+	algo := &crypka.HMACSignAlgorithm{
+		Hash:         crypto.SHA256,
+		GenKeyLength: 32,
+		MinKeyLength: 32,
+		MaxKeyLength: 32,
+	}
+
+	key, err := algo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	msg := []byte("some message")
+
+	signer, err := key.MakeSigner(nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = signer.Write(msg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	sign, err := signer.Finalize(nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	makeMismatchTimingTester(sign, func(input []byte) {
+		verifier, err := key.MakeVerifier(nil)
+		if err != nil {
+			panic(err)
+		}
+		verifier.Write(msg)
+		verifier.Verify(input)
+	}).Test(t)
+}
@@ -0,0 +1,737 @@
+package crypka
+
+import (
+	"crypto"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Algorithm specification grammar:
+//
+//	spec  = name [ "(" arg { "," arg } ")" ]
+//	arg   = spec | param
+//	param = name "=" value
+//
+// Names and values consist of ASCII letters, digits, "-", "_" and ".". Whitespace between tokens is ignored.
+// Sizes may have "k", "m" or "g" suffix, which multiplies them by 1024, 1024^2 or 1024^3.
+//
+// Specification without arguments refers to algorithm registered with given name.
+// Ones with arguments build composite algorithm from algorithms given as positional arguments:
+//   - "cpk-stream(<enc>, chunk=<size>, max-chunk=<size>)" builds CPKStreamSymmEncAlgo
+//   - "enc-kx(<kx>, <enc>[, <rng>], kx-length=<size>, max-ephemeral=<size>)" builds EncAsymKXAlgo
+//   - "kx-rng(<kx>, <rng>, seed=<size>)" builds KXRngAlgo
+//   - "hash-compress-rng(<hash>, <rng>, min-seed=<size>)" builds HashCompressRNGAlgo
+//   - "hmac(<hash>, min-key=<size>, max-key=<size>, gen-key=<size>)" builds HMACSignAlgorithm
+//   - "padded(<enc>, <padding>)" builds PaddedEncSymmAlgo
+//
+// For instance: "cpk-stream(aes-256-gcm-counter,chunk=64k)".
+
+// Max nesting of algorithm specification.
+const maxAlgorithmSpecDepth = 16
+
+// AlgorithmSpec is parsed algorithm specification.
+type AlgorithmSpec struct {
+	Name   string
+	Args   []AlgorithmSpec
+	Params map[string]string
+}
+
+// ParseAlgorithmSpec parses algorithm specification.
+func ParseAlgorithmSpec(text string) (spec AlgorithmSpec, err error) {
+	parser := algorithmSpecParser{input: text}
+	spec, err = parser.parseSpec(0)
+	if err != nil {
+		return
+	}
+
+	parser.skipSpaces()
+	if parser.pos != len(parser.input) {
+		err = ErrAlgorithmSpecInvalid
+		return
+	}
+	return
+}
+
+// String returns canonical form of specification, in which parameters are sorted and there is no whitespace.
+func (spec AlgorithmSpec) String() string {
+	var b strings.Builder
+	spec.writeTo(&b)
+	return b.String()
+}
+
+func (spec AlgorithmSpec) writeTo(b *strings.Builder) {
+	b.WriteString(spec.Name)
+	if len(spec.Args) == 0 && len(spec.Params) == 0 {
+		return
+	}
+
+	b.WriteByte('(')
+	for i, arg := range spec.Args {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		arg.writeTo(b)
+	}
+
+	keys := make([]string, 0, len(spec.Params))
+	for key := range spec.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for i, key := range keys {
+		if i > 0 || len(spec.Args) > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(spec.Params[key])
+	}
+	b.WriteByte(')')
+}
+
+func (spec AlgorithmSpec) MarshalText() ([]byte, error) {
+	return []byte(spec.String()), nil
+}
+
+func (spec *AlgorithmSpec) UnmarshalText(text []byte) (err error) {
+	*spec, err = ParseAlgorithmSpec(string(text))
+	return
+}
+
+type algorithmSpecParser struct {
+	input string
+	pos   int
+}
+
+func isAlgorithmSpecTokenByte(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+		c == '-' || c == '_' || c == '.'
+}
+
+func (p *algorithmSpecParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *algorithmSpecParser) peek() (c byte, ok bool) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return
+	}
+	return p.input[p.pos], true
+}
+
+func (p *algorithmSpecParser) token() (token string, err error) {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) && isAlgorithmSpecTokenByte(p.input[p.pos]) {
+		p.pos++
+	}
+	if start == p.pos {
+		err = ErrAlgorithmSpecInvalid
+		return
+	}
+
+	token = p.input[start:p.pos]
+	return
+}
+
+func (p *algorithmSpecParser) parseSpec(depth int) (spec AlgorithmSpec, err error) {
+	name, err := p.token()
+	if err != nil {
+		return
+	}
+	return p.parseSpecArgs(name, depth)
+}
+
+func (p *algorithmSpecParser) parseSpecArgs(name string, depth int) (spec AlgorithmSpec, err error) {
+	if depth >= maxAlgorithmSpecDepth {
+		err = ErrAlgorithmSpecInvalid
+		return
+	}
+
+	spec.Name = name
+	if c, ok := p.peek(); !ok || c != '(' {
+		return
+	}
+	p.pos++
+
+	for {
+		var token string
+		token, err = p.token()
+		if err != nil {
+			return
+		}
+
+		if c, ok := p.peek(); ok && c == '=' {
+			p.pos++
+
+			var value string
+			value, err = p.token()
+			if err != nil {
+				return
+			}
+
+			if spec.Params == nil {
+				spec.Params = map[string]string{}
+			}
+			if _, ok := spec.Params[token]; ok {
+				err = ErrAlgorithmSpecInvalid
+				return
+			}
+			spec.Params[token] = value
+		} else {
+			var arg AlgorithmSpec
+			arg, err = p.parseSpecArgs(token, depth+1)
+			if err != nil {
+				return
+			}
+			spec.Args = append(spec.Args, arg)
+		}
+
+		c, ok := p.peek()
+		if !ok {
+			err = ErrAlgorithmSpecInvalid
+			return
+		}
+		p.pos++
+
+		if c == ')' {
+			return
+		} else if c != ',' {
+			err = ErrAlgorithmSpecInvalid
+			return
+		}
+	}
+}
+
+func parseAlgorithmSpecSize(value string) (size int, err error) {
+	multiplier := 1
+	if len(value) > 0 {
+		switch value[len(value)-1] {
+		case 'k', 'K':
+			multiplier = 1 << 10
+		case 'm', 'M':
+			multiplier = 1 << 20
+		case 'g', 'G':
+			multiplier = 1 << 30
+		}
+		if multiplier != 1 {
+			value = value[:len(value)-1]
+		}
+	}
+
+	// digits only, so signs are rejected
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			err = ErrAlgorithmSpecInvalid
+			return
+		}
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || parsed > int64(int(^uint32(0)>>1)/multiplier) {
+		err = ErrAlgorithmSpecInvalid
+		return
+	}
+
+	size = int(parsed) * multiplier
+	return
+}
+
+func formatAlgorithmSpecSize(size int) string {
+	for _, unit := range []struct {
+		suffix string
+		value  int
+	}{
+		{"g", 1 << 30},
+		{"m", 1 << 20},
+		{"k", 1 << 10},
+	} {
+		if size != 0 && size%unit.value == 0 {
+			return strconv.Itoa(size/unit.value) + unit.suffix
+		}
+	}
+	return strconv.Itoa(size)
+}
+
+// checkShape returns error if specification has unexpected number of arguments or unknown params.
+func (spec AlgorithmSpec) checkShape(minArgs, maxArgs int, params ...string) (err error) {
+	if len(spec.Args) < minArgs || len(spec.Args) > maxArgs {
+		err = ErrAlgorithmSpecInvalid
+		return
+	}
+
+outer:
+	for key := range spec.Params {
+		for _, param := range params {
+			if key == param {
+				continue outer
+			}
+		}
+		err = ErrAlgorithmSpecInvalid
+		return
+	}
+	return
+}
+
+func (spec AlgorithmSpec) sizeParam(key string, defaultValue int) (size int, err error) {
+	value, ok := spec.Params[key]
+	if !ok {
+		size = defaultValue
+		return
+	}
+	return parseAlgorithmSpecSize(value)
+}
+
+func (spec *AlgorithmSpec) setSizeParam(key string, size int, defaultValue int) {
+	if size == defaultValue {
+		return
+	}
+	if spec.Params == nil {
+		spec.Params = map[string]string{}
+	}
+	spec.Params[key] = formatAlgorithmSpecSize(size)
+}
+
+func buildAlgorithmSpecArg[T any](reg Registry, spec AlgorithmSpec, i int) (algo T, err error) {
+	rawAlgo, err := BuildAlgorithmSpec(reg, spec.Args[i])
+	if err != nil {
+		return
+	}
+
+	algo, ok := rawAlgo.(T)
+	if !ok {
+		err = ErrInvalidAlgorithmType
+		return
+	}
+	return
+}
+
+// BuildAlgorithm parses specification and builds algorithm described by it.
+// Algorithms referenced by specification are taken from given registry or from GlobalRegistry if it's nil.
+func BuildAlgorithm(reg Registry, text string) (algo interface{}, err error) {
+	spec, err := ParseAlgorithmSpec(text)
+	if err != nil {
+		return
+	}
+	return BuildAlgorithmSpec(reg, spec)
+}
+
+// BuildTyped is like BuildAlgorithm, but it also checks if algorithm built has type given.
+func BuildTyped[T any](reg Registry, text string) (algo T, err error) {
+	rawAlgo, err := BuildAlgorithm(reg, text)
+	if err != nil {
+		return
+	}
+
+	algo, ok := rawAlgo.(T)
+	if !ok {
+		err = ErrInvalidAlgorithmType
+		return
+	}
+	return
+}
+
+// BuildAlgorithmSpec builds algorithm described by parsed specification.
+func BuildAlgorithmSpec(reg Registry, spec AlgorithmSpec) (algo interface{}, err error) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	if len(spec.Args) == 0 && len(spec.Params) == 0 {
+		algo = reg.GetAlgo(spec.Name)
+		if algo == nil {
+			err = ErrNoSuchAlgorithm
+		}
+		return
+	}
+
+	switch spec.Name {
+	case "cpk-stream":
+		algo, err = buildCPKStreamSpec(reg, spec)
+	case "enc-kx":
+		algo, err = buildEncAsymKXSpec(reg, spec)
+	case "kx-rng":
+		algo, err = buildKXRngSpec(reg, spec)
+	case "hash-compress-rng":
+		algo, err = buildHashCompressRNGSpec(reg, spec)
+	case "hmac":
+		algo, err = buildHMACSpec(reg, spec)
+	case "padded":
+		algo, err = buildPaddedSpec(reg, spec)
+	default:
+		err = ErrNoSuchAlgorithm
+	}
+	if err != nil {
+		algo = nil
+		return
+	}
+
+	// built algorithm is not registered, so its canonical specification is used as its name
+	// this way keys of such algorithm can be serialized and checked against policy
+	name, formatErr := FormatAlgorithmSpec(reg, algo)
+	if formatErr != nil {
+		name = spec.String()
+	}
+	tagAlgoName(name, algo)
+	return
+}
+
+// resolveAlgorithm returns algorithm registered with given name.
+// If there is no such algorithm, name is treated as specification, so keys of algorithms built from specification can be parsed.
+func resolveAlgorithm(reg Registry, name string) (algo interface{}) {
+	algo = reg.GetAlgo(name)
+	if algo != nil {
+		return
+	}
+
+	algo, err := BuildAlgorithm(reg, name)
+	if err != nil {
+		algo = nil
+	}
+	return
+}
+
+// canonicalAlgorithmSpec returns canonical form of given specification or false if it can't be built.
+func canonicalAlgorithmSpec(reg Registry, text string) (canonical string, ok bool) {
+	algo, err := BuildAlgorithm(reg, text)
+	if err != nil {
+		return
+	}
+	canonical, err = FormatAlgorithmSpec(reg, algo)
+	ok = err == nil
+	return
+}
+
+func buildCPKStreamSpec(reg Registry, spec AlgorithmSpec) (algo interface{}, err error) {
+	err = spec.checkShape(1, 1, "chunk", "max-chunk")
+	if err != nil {
+		return
+	}
+
+	res := &CPKStreamSymmEncAlgo{}
+	res.EncSymmAlgo, err = buildAlgorithmSpecArg[EncSymmAlgo](reg, spec, 0)
+	if err != nil {
+		return
+	}
+
+	res.ChunkSize, err = spec.sizeParam("chunk", 0)
+	if err != nil {
+		return
+	}
+	res.MaxChunkSize, err = spec.sizeParam("max-chunk", 0)
+	if err != nil {
+		return
+	}
+
+	algo = res
+	return
+}
+
+func defaultEncAsymKXResultLength(kxAlgo KXAlgo) int {
+	kxResultLength := kxAlgo.GetInfo().MaxResLen
+	if kxResultLength == 0 {
+		kxResultLength = fallbackKXRNGAlgoSeedSize
+	}
+	return kxResultLength
+}
+
+func buildEncAsymKXSpec(reg Registry, spec AlgorithmSpec) (algo interface{}, err error) {
+	err = spec.checkShape(2, 3, "kx-length", "max-ephemeral")
+	if err != nil {
+		return
+	}
+
+	res := &EncAsymKXAlgo{}
+	res.KXAlgo, err = buildAlgorithmSpecArg[KXAlgo](reg, spec, 0)
+	if err != nil {
+		return
+	}
+	res.EncSymmAlgo, err = buildAlgorithmSpecArg[EncSymmAlgo](reg, spec, 1)
+	if err != nil {
+		return
+	}
+	if len(spec.Args) > 2 {
+		res.RNGAlgo, err = buildAlgorithmSpecArg[RNGAlgo](reg, spec, 2)
+		if err != nil {
+			return
+		}
+	}
+
+	res.KXResultLength, err = spec.sizeParam("kx-length", defaultEncAsymKXResultLength(res.KXAlgo))
+	if err != nil {
+		return
+	}
+	res.MaxMarshaledEphemeralLength, err = spec.sizeParam("max-ephemeral", 0)
+	if err != nil {
+		return
+	}
+
+	algo = res
+	return
+}
+
+func buildKXRngSpec(reg Registry, spec AlgorithmSpec) (algo interface{}, err error) {
+	err = spec.checkShape(2, 2, "seed")
+	if err != nil {
+		return
+	}
+
+	res := &KXRngAlgo{}
+	res.KXAlgo, err = buildAlgorithmSpecArg[KXAlgo](reg, spec, 0)
+	if err != nil {
+		return
+	}
+	res.RNGAlgo, err = buildAlgorithmSpecArg[RNGAlgo](reg, spec, 1)
+	if err != nil {
+		return
+	}
+
+	res.RNGSeedBytes, err = spec.sizeParam("seed", 0)
+	if err != nil {
+		return
+	}
+
+	algo = res
+	return
+}
+
+func buildHashCompressRNGSpec(reg Registry, spec AlgorithmSpec) (algo interface{}, err error) {
+	err = spec.checkShape(2, 2, "min-seed")
+	if err != nil {
+		return
+	}
+
+	hashAlgo, err := buildAlgorithmSpecArg[*HashSignAlgorithm](reg, spec, 0)
+	if err != nil {
+		return
+	}
+
+	res := &HashCompressRNGAlgo{
+		Compressor: &hashKey{
+			hash: hashAlgo.Hash,
+		},
+	}
+	res.InnerAlgo, err = buildAlgorithmSpecArg[RNGAlgo](reg, spec, 1)
+	if err != nil {
+		return
+	}
+
+	res.MinSeedLength, err = spec.sizeParam("min-seed", 0)
+	if err != nil {
+		return
+	}
+
+	algo = res
+	return
+}
+
+func buildHMACSpec(reg Registry, spec AlgorithmSpec) (algo interface{}, err error) {
+	err = spec.checkShape(1, 1, "min-key", "max-key", "gen-key")
+	if err != nil {
+		return
+	}
+
+	hashAlgo, err := buildAlgorithmSpecArg[*HashSignAlgorithm](reg, spec, 0)
+	if err != nil {
+		return
+	}
+
+	res := &HMACSignAlgorithm{
+		Hash: hashAlgo.Hash,
+	}
+	res.MinKeyLength, err = spec.sizeParam("min-key", 32)
+	if err != nil {
+		return
+	}
+	res.MaxKeyLength, err = spec.sizeParam("max-key", 0)
+	if err != nil {
+		return
+	}
+	res.GenKeyLength, err = spec.sizeParam("gen-key", 32)
+	if err != nil {
+		return
+	}
+
+	algo = res
+	return
+}
+
+func buildPaddedSpec(reg Registry, spec AlgorithmSpec) (algo interface{}, err error) {
+	err = spec.checkShape(2, 2)
+	if err != nil {
+		return
+	}
+
+	res := &PaddedEncSymmAlgo{}
+	res.EncSymmAlgo, err = buildAlgorithmSpecArg[EncSymmAlgo](reg, spec, 0)
+	if err != nil {
+		return
+	}
+	res.Padding, err = buildAlgorithmSpecArg[PaddingAlgo](reg, spec, 1)
+	if err != nil {
+		return
+	}
+
+	algo = res
+	return
+}
+
+// FormatAlgorithmSpec returns canonical specification of given algorithm.
+// Algorithms registered in registry are represented by their names.
+// Composite algorithms, which are not registered, are represented by their components.
+//
+// Parameters, which have default values, are omitted.
+func FormatAlgorithmSpec(reg Registry, algo interface{}) (text string, err error) {
+	spec, err := describeAlgorithm(reg, algo)
+	if err != nil {
+		return
+	}
+
+	text = spec.String()
+	return
+}
+
+// findRegisteredName returns name of algorithm in registry, which is exactly same object as one given.
+func findRegisteredName(reg Registry, algo interface{}) (name string, ok bool) {
+	// only pointers are compared, since other values may be not comparable
+	if algo == nil || reflect.TypeOf(algo).Kind() != reflect.Ptr {
+		return
+	}
+
+	for _, candidate := range reg.ListAlgorithms() {
+		registered := reg.GetAlgo(candidate)
+		if reflect.TypeOf(registered) == reflect.TypeOf(algo) && registered == algo {
+			return candidate, true
+		}
+	}
+	return
+}
+
+func findHashName(reg Registry, hash crypto.Hash) (name string, ok bool) {
+	for _, candidate := range reg.ListAlgorithms() {
+		hashAlgo, isHash := reg.GetAlgo(candidate).(*HashSignAlgorithm)
+		if isHash && hashAlgo.Hash == hash {
+			return candidate, true
+		}
+	}
+	return
+}
+
+func describeHash(reg Registry, hash crypto.Hash) (spec AlgorithmSpec, err error) {
+	name, ok := findHashName(reg, hash)
+	if !ok {
+		err = ErrAlgorithmSpecNotRepresentable
+		return
+	}
+
+	spec.Name = name
+	return
+}
+
+func describeAlgorithm(reg Registry, algo interface{}) (spec AlgorithmSpec, err error) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	if name, ok := findRegisteredName(reg, algo); ok {
+		spec.Name = name
+		return
+	}
+
+	describeArgs := func(args ...interface{}) (err error) {
+		for _, arg := range args {
+			var argSpec AlgorithmSpec
+			argSpec, err = describeAlgorithm(reg, arg)
+			if err != nil {
+				return
+			}
+			spec.Args = append(spec.Args, argSpec)
+		}
+		return
+	}
+
+	switch typedAlgo := algo.(type) {
+	case *CPKStreamSymmEncAlgo:
+		spec.Name = "cpk-stream"
+		err = describeArgs(typedAlgo.EncSymmAlgo)
+		if err != nil {
+			return
+		}
+
+		spec.setSizeParam("chunk", typedAlgo.ChunkSize, 0)
+		spec.setSizeParam("max-chunk", typedAlgo.MaxChunkSize, 0)
+	case *EncAsymKXAlgo:
+		if typedAlgo.EphemeralRNG != nil {
+			err = ErrAlgorithmSpecNotRepresentable
+			return
+		}
+
+		spec.Name = "enc-kx"
+		err = describeArgs(typedAlgo.KXAlgo, typedAlgo.EncSymmAlgo)
+		if err != nil {
+			return
+		}
+		if typedAlgo.RNGAlgo != nil {
+			err = describeArgs(typedAlgo.RNGAlgo)
+			if err != nil {
+				return
+			}
+		}
+
+		spec.setSizeParam("kx-length", typedAlgo.KXResultLength, defaultEncAsymKXResultLength(typedAlgo.KXAlgo))
+		spec.setSizeParam("max-ephemeral", typedAlgo.MaxMarshaledEphemeralLength, 0)
+	case *KXRngAlgo:
+		spec.Name = "kx-rng"
+		err = describeArgs(typedAlgo.KXAlgo, typedAlgo.RNGAlgo)
+		if err != nil {
+			return
+		}
+
+		spec.setSizeParam("seed", typedAlgo.RNGSeedBytes, 0)
+	case *HashCompressRNGAlgo:
+		compressor, ok := typedAlgo.Compressor.(*hashKey)
+		if !ok {
+			err = ErrAlgorithmSpecNotRepresentable
+			return
+		}
+
+		var hashSpec AlgorithmSpec
+		hashSpec, err = describeHash(reg, compressor.hash)
+		if err != nil {
+			return
+		}
+
+		spec.Name = "hash-compress-rng"
+		spec.Args = append(spec.Args, hashSpec)
+		err = describeArgs(typedAlgo.InnerAlgo)
+		if err != nil {
+			return
+		}
+
+		spec.setSizeParam("min-seed", typedAlgo.MinSeedLength, 0)
+	case *HMACSignAlgorithm:
+		var hashSpec AlgorithmSpec
+		hashSpec, err = describeHash(reg, typedAlgo.Hash)
+		if err != nil {
+			return
+		}
+
+		spec.Name = "hmac"
+		spec.Args = append(spec.Args, hashSpec)
+		spec.setSizeParam("min-key", typedAlgo.MinKeyLength, 32)
+		spec.setSizeParam("max-key", typedAlgo.MaxKeyLength, 0)
+		spec.setSizeParam("gen-key", typedAlgo.GenKeyLength, 32)
+	case *PaddedEncSymmAlgo:
+		spec.Name = "padded"
+		err = describeArgs(typedAlgo.EncSymmAlgo, typedAlgo.Padding)
+	default:
+		err = ErrAlgorithmSpecNotRepresentable
+	}
+	return
+}
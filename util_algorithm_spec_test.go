@@ -0,0 +1,253 @@
+package crypka_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+func TestParseAlgorithmSpec_Canonical(t *testing.T) {
+	for _, tc := range []struct {
+		input     string
+		canonical string
+	}{
+		{"aes-256-gcm-counter", "aes-256-gcm-counter"},
+		{" cpk-stream( aes-256-gcm-counter , chunk = 64k ) ", "cpk-stream(aes-256-gcm-counter,chunk=64k)"},
+		{"hmac(sha-256,min-key=16,gen-key=64)", "hmac(sha-256,gen-key=64,min-key=16)"},
+		{"enc-kx(x25519,cpk-stream(chacha20-poly1305-counter),hash-compress-rng(sha-512,chacha20-rng))", "enc-kx(x25519,cpk-stream(chacha20-poly1305-counter),hash-compress-rng(sha-512,chacha20-rng))"},
+	} {
+		spec, err := crypka.ParseAlgorithmSpec(tc.input)
+		if err != nil {
+			t.Error(tc.input, err)
+			continue
+		}
+		if spec.String() != tc.canonical {
+			t.Error("invalid canonical form", spec.String(), "expected", tc.canonical)
+		}
+	}
+}
+
+func TestParseAlgorithmSpec_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"()",
+		"cpk-stream(",
+		"cpk-stream()",
+		"cpk-stream(aes-256-gcm-counter,)",
+		"cpk-stream(aes-256-gcm-counter) x",
+		"cpk-stream(aes-256-gcm-counter,chunk=)",
+		"cpk-stream(aes-256-gcm-counter,chunk=1,chunk=2)",
+		"a(a(a(a(a(a(a(a(a(a(a(a(a(a(a(a(a(a(a))))))))))))))))))",
+	} {
+		_, err := crypka.ParseAlgorithmSpec(input)
+		if !errors.Is(err, crypka.ErrAlgorithmSpecInvalid) {
+			t.Error("expected invalid spec error for", input, "got", err)
+		}
+	}
+}
+
+func TestBuildAlgorithm_CPKStream(t *testing.T) {
+	algo, err := crypka.BuildTyped[*crypka.CPKStreamSymmEncAlgo](nil, "cpk-stream(aes-256-gcm-counter,chunk=64k)")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if algo.ChunkSize != 64*1024 {
+		t.Error("invalid chunk size", algo.ChunkSize)
+		return
+	}
+
+	key, err := algo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = checkEncRoundTrip(key, key, make([]byte, 200*1024))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	text, err := crypka.FormatAlgorithmSpec(nil, algo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if text != "cpk-stream(aes-256-gcm-counter,chunk=64k)" {
+		t.Error("invalid spec", text)
+		return
+	}
+}
+
+func TestBuildAlgorithm_AllowedBySpec(t *testing.T) {
+	ctx := &crypka.Context{
+		Policy: &crypka.Policy{
+			AllowedAlgorithms: []string{"cpk-stream( aes-256-gcm-counter, chunk=64k )"},
+		},
+	}
+
+	algo, err := crypka.BuildTyped[crypka.EncSymmAlgo](nil, "cpk-stream(aes-256-gcm-counter,chunk=65536)")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, err := algo.GenerateKey(ctx, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = key.MakeEncryptor(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	otherAlgo, err := crypka.BuildTyped[crypka.EncSymmAlgo](nil, "cpk-stream(aes-256-gcm-counter)")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = otherAlgo.GenerateKey(ctx, nil)
+	if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+		t.Error("expected policy error, got", err)
+		return
+	}
+}
+
+func TestBuildAlgorithm_KeyJSON(t *testing.T) {
+	algo, err := crypka.BuildTyped[crypka.EncSymmAlgo](nil, "cpk-stream(aes-256-gcm-counter,chunk=64k)")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, err := algo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	data, err := crypka.ExportableSecret{Key: key}.MarshalJSON()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var sk crypka.SerializedKey
+	err = sk.UnmarshalJSON(data)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if sk.Algo != "cpk-stream(aes-256-gcm-counter,chunk=64k)" {
+		t.Error("invalid algorithm name", sk.Algo)
+		return
+	}
+
+	parsed, err := crypka.UnmarshalKeyJSON[crypka.EncSymmKey](nil, nil, data)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = checkEncRoundTrip(key, parsed, make([]byte, 100*1024))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestBuildAlgorithm_RoundTrip(t *testing.T) {
+	for _, text := range []string{
+		"x25519-aes-256-gcm-counter",
+		"hmac(sha3-256)",
+		"hmac(sha-512,max-key=1k,min-key=64)",
+		"kx-rng(x25519,chacha20-rng,seed=32)",
+		"hash-compress-rng(sha-512,aes-256-ctr-rng,min-seed=16)",
+		"enc-kx(x25519,aes-128-gcm-counter,hash-compress-rng(sha-512,chacha20-rng,min-seed=32))",
+		"enc-kx(x25519,chacha20-poly1305-counter,kx-length=16)",
+		"cpk-stream(padded(aes-256-gcm-counter,pad-padme))",
+	} {
+		algo, err := crypka.BuildAlgorithm(nil, text)
+		if err != nil {
+			t.Error(text, err)
+			continue
+		}
+
+		formatted, err := crypka.FormatAlgorithmSpec(nil, algo)
+		if err != nil {
+			t.Error(text, err)
+			continue
+		}
+		if formatted != text {
+			t.Error("invalid spec", formatted, "expected", text)
+		}
+	}
+}
+
+func TestBuildAlgorithm_EncAsymKXUsable(t *testing.T) {
+	algo, err := crypka.BuildTyped[crypka.EncAsymAlgo](nil, "enc-kx(x25519,cpk-stream(aes-256-gcm-counter),hash-compress-rng(sha-512,chacha20-rng,min-seed=32))")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ek, dk, err := algo.GenerateKeyPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = checkEncRoundTrip(ek, dk, []byte("some data"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestBuildAlgorithm_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		err  error
+	}{
+		{"missing", crypka.ErrNoSuchAlgorithm},
+		{"missing(aes-256-gcm-counter)", crypka.ErrNoSuchAlgorithm},
+		{"cpk-stream(missing)", crypka.ErrNoSuchAlgorithm},
+		{"cpk-stream(sha-256)", crypka.ErrInvalidAlgorithmType},
+		{"cpk-stream(aes-256-gcm-counter,unknown=1)", crypka.ErrAlgorithmSpecInvalid},
+		{"cpk-stream(aes-256-gcm-counter,chunk=-1)", crypka.ErrAlgorithmSpecInvalid},
+		{"cpk-stream(aes-256-gcm-counter,chunk=99999999g)", crypka.ErrAlgorithmSpecInvalid},
+		{"hmac(sha-256,sha-512)", crypka.ErrAlgorithmSpecInvalid},
+		{"padded(aes-256-gcm-counter,sha-256)", crypka.ErrInvalidAlgorithmType},
+		{"padded(aes-256-gcm-counter)", crypka.ErrAlgorithmSpecInvalid},
+	} {
+		_, err := crypka.BuildAlgorithm(nil, tc.text)
+		if !errors.Is(err, tc.err) {
+			t.Error("expected", tc.err, "for", tc.text, "got", err)
+		}
+	}
+}
+
+func TestFormatAlgorithmSpec_NotRepresentable(t *testing.T) {
+	_, err := crypka.FormatAlgorithmSpec(nil, &crypka.XorEncSymmAlgo{})
+	if !errors.Is(err, crypka.ErrAlgorithmSpecNotRepresentable) {
+		t.Error("expected not representable error, got", err)
+	}
+}
+
+func TestAlgorithmSpec_Text(t *testing.T) {
+	var spec crypka.AlgorithmSpec
+	err := spec.UnmarshalText([]byte("cpk-stream(aes-256-gcm-counter, chunk=1m)"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	text, err := spec.MarshalText()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(text) != "cpk-stream(aes-256-gcm-counter,chunk=1m)" {
+		t.Error("invalid text", string(text))
+		return
+	}
+}
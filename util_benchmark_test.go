@@ -0,0 +1,123 @@
+package crypka_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/teawithsand/crypka"
+	"github.com/teawithsand/crypka/crypkatest"
+)
+
+func TestBenchmarkRunner(t *testing.T) {
+	reg := crypka.NewRegistry()
+	crypka.RegisterSTLHashes(reg)
+	crypka.RegisterAES128GCM(reg)
+	crypka.RegisterAESKW(reg)
+	crypka.RegisterEd25519(reg, crypka.RegisterEd25519Options{})
+	crypka.RegisterX25519(reg)
+	crypka.RegisterEncAsymKX(reg, "x25519", "aes-128-gcm-counter")
+	crypka.RegisterStreamCipherRNGs(reg)
+
+	runner := crypkatest.BenchmarkRunner{
+		Registry:     reg,
+		PayloadSizes: []int{7, 64},
+		MinDuration:  time.Microsecond * 100,
+	}
+	results := runner.Run()
+
+	operations := map[string]bool{}
+	for _, res := range results {
+		operations[res.Operation] = true
+
+		if (res.Algorithm == "aes-128-kw" || res.Algorithm == "aes-256-kw") && res.PayloadSize == 7 {
+			if len(res.Error) == 0 {
+				t.Error("expected error for payload, which is not multiple of 8 with AES-KW")
+			}
+			continue
+		}
+
+		if len(res.Error) != 0 {
+			t.Error("unexpected error", res.Algorithm, res.Operation, res.Error)
+			continue
+		}
+		if res.Iterations <= 0 || res.NsPerOp <= 0 {
+			t.Error("invalid measurement", res)
+		}
+		if res.PayloadSize > 0 && res.BytesPerSecond <= 0 {
+			t.Error("invalid throughput", res)
+		}
+	}
+
+	for _, op := range []string{
+		crypkatest.BenchmarkOpKeygen,
+		crypkatest.BenchmarkOpEncrypt,
+		crypkatest.BenchmarkOpDecrypt,
+		crypkatest.BenchmarkOpSign,
+		crypkatest.BenchmarkOpVerify,
+		crypkatest.BenchmarkOpExchange,
+		crypkatest.BenchmarkOpRead,
+	} {
+		if !operations[op] {
+			t.Error("missing operation", op)
+		}
+	}
+
+	var csvBuf bytes.Buffer
+	err := results.WriteCSV(&csvBuf)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	records, err := csv.NewReader(&csvBuf).ReadAll()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(records) != len(results)+1 {
+		t.Error("invalid CSV record count", len(records))
+		return
+	}
+
+	var jsonBuf bytes.Buffer
+	err = results.WriteJSON(&jsonBuf)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var decoded crypkatest.BenchmarkResults
+	err = json.Unmarshal(jsonBuf.Bytes(), &decoded)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(decoded) != len(results) {
+		t.Error("invalid JSON result count", len(decoded))
+		return
+	}
+}
+
+func TestBenchmarkRunner_Types(t *testing.T) {
+	runner := crypkatest.BenchmarkRunner{
+		Types:        []crypka.AlgorithmType{crypka.KXAlgorithmType},
+		PayloadSizes: []int{64},
+		MinDuration:  time.Microsecond * 100,
+	}
+	results := runner.Run()
+	if len(results) == 0 {
+		t.Error("expected some results")
+		return
+	}
+	for _, res := range results {
+		if res.Type != crypka.KXAlgorithmType.String() {
+			t.Error("unexpected algorithm type", res)
+		}
+	}
+}
+
+func BenchmarkRegisteredAlgorithms(b *testing.B) {
+	runner := crypkatest.BenchmarkRunner{}
+	runner.Benchmark(b)
+}
@@ -15,7 +15,7 @@ func mapIntEncodingError(err error) error {
 
 type intEncoding int8
 
-const intEncodingMaxSize = 9
+const intEncodingMaxSize = binary.MaxVarintLen64
 
 func (e intEncoding) IsValid() bool {
 	return e == ByteVar || e == Byte1 || e == Byte2 || e == Byte4 || e == Byte8
@@ -74,6 +74,7 @@ func (e intEncoding) EncodeAtStart(buf []byte, n uint64) (sz int) {
 	case ByteVar:
 		sz = binary.PutUvarint(buf, n)
 	case Byte1:
+		sz = 1
 		buf[0] = byte(n)
 	case Byte2:
 		sz = 2
@@ -170,7 +171,7 @@ func (e intEncoding) Decode(r io.Reader) (n uint64, err error) {
 		}
 		n = uint64(binary.BigEndian.Uint64(arr[:]))
 	default:
-		panic("NIY ERROR HERE INVALID INT ENCODING")
+		err = errIntEncodingError
 	}
 	return
 }
@@ -0,0 +1,87 @@
+package crypka_test
+
+import (
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+func TestIntEncoding_RoundTrip(t *testing.T) {
+	for _, e := range []struct {
+		name     string
+		encoding interface {
+			Size(n uint64) int
+			AppendToBuf(appendTo []byte, n uint64) (res []byte, sz int)
+			DecodeAtStart(buf []byte) (n uint64, sz int, err error)
+		}
+		max uint64
+	}{
+		{"var", crypka.ByteVar, 1<<64 - 1},
+		{"1", crypka.Byte1, 1<<8 - 1},
+		{"2", crypka.Byte2, 1<<16 - 1},
+		{"4", crypka.Byte4, 1<<32 - 1},
+		{"8", crypka.Byte8, 1<<64 - 1},
+	} {
+		for _, n := range []uint64{0, 1, 127, 128, 255, 1<<16 - 1, 1<<32 - 1, 1 << 62, 1 << 63, 1<<64 - 1} {
+			if n > e.max {
+				continue
+			}
+
+			buf, sz := e.encoding.AppendToBuf([]byte{0xaa}, n)
+			if sz != e.encoding.Size(n) || len(buf) != 1+sz {
+				t.Error(e.name, n, "invalid encoded size", sz)
+				continue
+			}
+
+			decoded, decodedSize, err := e.encoding.DecodeAtStart(buf[1:])
+			if err != nil {
+				t.Error(e.name, n, err)
+				continue
+			}
+			if decoded != n || decodedSize != sz {
+				t.Error(e.name, n, "decoded", decoded, "of size", decodedSize)
+			}
+		}
+	}
+}
+
+func FuzzIntEncoding_DecodeAtStart(f *testing.F) {
+	f.Add(byte(0), []byte{})
+	f.Add(byte(0), []byte{0x80})
+	f.Add(byte(0), []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+	f.Add(byte(0), []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+	f.Add(byte(1), []byte{0x12})
+	f.Add(byte(2), []byte{0x12})
+	f.Add(byte(4), []byte{0x12, 0x34, 0x56, 0x78})
+	f.Add(byte(8), []byte{0x12, 0x34, 0x56, 0x78})
+
+	encodings := []interface {
+		Size(n uint64) int
+		MaxSize() int
+		DecodeAtStart(buf []byte) (n uint64, sz int, err error)
+	}{
+		crypka.ByteVar,
+		crypka.Byte1,
+		crypka.Byte2,
+		crypka.Byte4,
+		crypka.Byte8,
+	}
+
+	f.Fuzz(func(t *testing.T, selector byte, data []byte) {
+		e := encodings[int(selector)%len(encodings)]
+
+		n, sz, err := e.DecodeAtStart(data)
+		if err != nil {
+			return
+		}
+
+		if sz <= 0 || sz > len(data) || sz > e.MaxSize() {
+			t.Error("invalid size of decoded value", sz)
+		}
+
+		// non canonical encodings may be longer, but never shorter than canonical one
+		if sz < e.Size(n) {
+			t.Error("decoded value is shorter than its canonical encoding", n, sz)
+		}
+	})
+}
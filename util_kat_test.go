@@ -0,0 +1,89 @@
+package crypka_test
+
+import (
+	"crypto/sha256"
+	"io"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+	"github.com/teawithsand/crypka/crypkatest"
+	"golang.org/x/crypto/hkdf"
+)
+
+func TestKAT_Builtin(t *testing.T) {
+	crypkatest.BuiltinKATTester{}.Test(t)
+}
+
+func TestKAT_BuiltinCoverage(t *testing.T) {
+	for _, name := range crypka.GlobalRegistry.ListAlgorithms() {
+		_, covered := crypkatest.BuiltinKATVectors[name]
+		_, exempt := crypkatest.BuiltinKATExempt[name]
+		if !covered && !exempt {
+			t.Error("no known answer tests for", name)
+		}
+	}
+}
+
+// identityKey passes data to ed25519 as is, so it signs message itself rather than its digest.
+type identityKey struct{}
+
+type identitySigner struct {
+	data []byte
+}
+
+func (s *identitySigner) Write(data []byte) (int, error) {
+	s.data = append(s.data, data...)
+	return len(data), nil
+}
+
+func (s *identitySigner) Finalize(appendTo []byte) ([]byte, error) {
+	return append(appendTo, s.data...), nil
+}
+
+func (identityKey) MakeSigner(ctx crypka.KeyContext) (crypka.Signer, error) {
+	return &identitySigner{}, nil
+}
+
+func TestKAT_Ed25519(t *testing.T) {
+	vectors, err := crypkatest.LoadKATVectors("eddsa_test.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	crypkatest.KATTester{
+		Algo: &crypka.Ed25519SignAsymAlgo{
+			Compressor: identityKey{},
+		},
+		Vectors: vectors,
+	}.Test(t)
+}
+
+func TestKAT_HKDF(t *testing.T) {
+	vectors, err := crypkatest.LoadKATVectors("hkdf_sha256_test.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	crypkatest.KATTester{
+		Vectors: vectors,
+		HKDF: func(ikm, salt, info []byte, size int) (okm []byte, err error) {
+			okm = make([]byte, size)
+			_, err = io.ReadFull(hkdf.New(sha256.New, ikm, salt, info), okm)
+			return
+		},
+	}.Test(t)
+}
+
+func TestKAT_Argon2(t *testing.T) {
+	vectors, err := crypkatest.LoadKATVectors("argon2_test.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	crypkatest.KATTester{
+		Vectors: vectors,
+	}.Test(t)
+}
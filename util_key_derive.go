@@ -0,0 +1,178 @@
+package crypka
+
+import (
+	"crypto/sha256"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// MinKeyDerivationMasterLength is minimum length of master secret accepted by KeyDeriver.
+const MinKeyDerivationMasterLength = 16
+
+const keyDerivationChildDomain = "crypka-key-derivation-child\x00"
+const keyDerivationKeyDomain = "crypka-key-derivation-key\x00"
+
+// KeyDerivationPathSeparator separates segments of paths accepted by KeyDeriver.
+const KeyDerivationPathSeparator = "/"
+
+// KeyDeriver deterministically derives keys of any algorithm from single master secret and path like "tenant/42/enc".
+//
+// Each path segment except the last one selects child deriver, so "tenant/42/enc" derives same key
+// as Child("tenant/42") followed by DeriveKey("enc"). This way child deriver may be handed out, without revealing parent's master.
+//
+// Segments are encoded with length prefix and different domains are used for children and keys,
+// so distinct paths never yield same HKDF input.
+//
+// Derivation uses HKDF-SHA256 and RNG created this way is passed to algorithm's key generation function.
+// This means that keys depend on the way algorithm consumes RNG, so same path should never be used with different algorithms.
+type KeyDeriver struct {
+	Master []byte
+
+	// Optional. If set, HKDF output is used as seed for this RNG, which is then passed to key generation.
+	// It must be seedable.
+	RNGAlgo RNGAlgo
+}
+
+func (kd *KeyDeriver) expand(domain, label string) (rng RNG, err error) {
+	if len(kd.Master) < MinKeyDerivationMasterLength {
+		err = ErrKeyDerivationInvalidMaster
+		return
+	}
+	if len(label) == 0 {
+		err = ErrKeyDerivationInvalidLabel
+		return
+	}
+
+	info := []byte(domain)
+	info, _ = ByteVar.AppendToBuf(info, uint64(len(label)))
+	info = append(info, label...)
+
+	rng = hkdf.New(sha256.New, kd.Master, nil, info)
+	return
+}
+
+func splitKeyDerivationPath(path string) (parents []string, last string, err error) {
+	segments := strings.Split(path, KeyDerivationPathSeparator)
+	for _, s := range segments {
+		if len(s) == 0 {
+			err = ErrKeyDerivationInvalidLabel
+			return
+		}
+	}
+
+	parents = segments[:len(segments)-1]
+	last = segments[len(segments)-1]
+	return
+}
+
+func (kd *KeyDeriver) child(label string) (res *KeyDeriver, err error) {
+	rng, err := kd.expand(keyDerivationChildDomain, label)
+	if err != nil {
+		return
+	}
+
+	master := make([]byte, sha256.Size)
+	_, err = io.ReadFull(rng, master)
+	if err != nil {
+		return
+	}
+
+	res = &KeyDeriver{
+		Master:  master,
+		RNGAlgo: kd.RNGAlgo,
+	}
+	return
+}
+
+// Child returns deriver for given path, which is independent of parent's master.
+func (kd *KeyDeriver) Child(path string) (res *KeyDeriver, err error) {
+	parents, last, err := splitKeyDerivationPath(path)
+	if err != nil {
+		return
+	}
+
+	res = kd
+	for _, segment := range append(parents, last) {
+		res, err = res.child(segment)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeriveRNG returns deterministic RNG for given path.
+// It can be used with any function, which accepts RNG, for instance GenerateKey of some algorithm.
+func (kd *KeyDeriver) DeriveRNG(ctx RNGGenerationContext, path string) (rng RNG, err error) {
+	parents, last, err := splitKeyDerivationPath(path)
+	if err != nil {
+		return
+	}
+
+	parent := kd
+	for _, segment := range parents {
+		parent, err = parent.child(segment)
+		if err != nil {
+			return
+		}
+	}
+
+	rng, err = parent.expand(keyDerivationKeyDomain, last)
+	if err != nil {
+		return
+	}
+
+	if kd.RNGAlgo != nil {
+		var seed []byte
+		seed, err = GenerateReasonableRNGSeed(rng, kd.RNGAlgo.GetInfo())
+		if err != nil {
+			return
+		}
+
+		rng, err = kd.RNGAlgo.MakeRng(ctx, seed)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeriveKey derives key for given path using given algorithm.
+//
+// For asymmetric algorithms both secret and public are set.
+// For symmetric ones only secret is set.
+//
+// Supported algorithms are ones implementing EncSymmKeygen, EncAsymKeygen, SignSymmKeyGen, SignAsymKeyGen or KXKeygen.
+func (kd *KeyDeriver) DeriveKey(ctx KeyGenerationContext, path string, algo interface{}) (secret, public interface{}, err error) {
+	rng, err := kd.DeriveRNG(ctx, path)
+	if err != nil {
+		return
+	}
+
+	switch typedAlgo := algo.(type) {
+	case EncSymmKeygen:
+		secret, err = typedAlgo.GenerateKey(ctx, rng)
+	case EncAsymKeygen:
+		public, secret, err = typedAlgo.GenerateKeyPair(ctx, rng)
+	case SignSymmKeyGen:
+		secret, err = typedAlgo.GenerateKey(ctx, rng)
+	case SignAsymKeyGen:
+		secret, public, err = typedAlgo.GenerateKeyPair(ctx, rng)
+	case KXKeygen:
+		public, secret, err = typedAlgo.GenerateKXPair(ctx, rng)
+	default:
+		err = ErrKeyDerivationUnsupportedAlgo
+	}
+	return
+}
+
+// DeriveKey derives key for given path from master secret using given algorithm.
+// It's shortcut for KeyDeriver.DeriveKey.
+func DeriveKey(ctx KeyGenerationContext, master []byte, path string, algo interface{}) (secret, public interface{}, err error) {
+	kd := &KeyDeriver{
+		Master: master,
+	}
+	return kd.DeriveKey(ctx, path, algo)
+}
@@ -0,0 +1,165 @@
+package crypka_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+func TestKeyDeriver(t *testing.T) {
+	reg := crypka.NewRegistry()
+	crypka.RegisterAES128GCM(reg)
+	crypka.RegisterSTLHashes(reg)
+	crypka.RegisterEd25519(reg, crypka.RegisterEd25519Options{})
+
+	var encAlgo crypka.EncSymmAlgo
+	err := reg.GetAlgorithmTyped("aes-128-gcm-rng", &encAlgo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var signAlgo crypka.SignAsymAlgo
+	err = reg.GetAlgorithmTyped("ed25519-sha-256", &signAlgo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	master := bytes.Repeat([]byte{42}, 32)
+
+	aesCtrRNG := &crypka.EncStreamRNGAlgo{
+		CipherFactory: func(key []byte) (res cipher.Stream, err error) {
+			block, err := aes.NewCipher(key)
+			if err != nil {
+				return
+			}
+			iv := make([]byte, aes.BlockSize)
+			res = cipher.NewCTR(block, iv)
+			return
+		},
+		KeyLength: 32,
+	}
+
+	for _, tc := range []struct {
+		name string
+		algo interface{}
+	}{
+		{"aes-128-gcm", encAlgo},
+		{"ed25519", signAlgo},
+		{"x25519", &crypka.X25519KXAlgo{}},
+		{"hmac", &crypka.HMACSignAlgorithm{Hash: crypto.SHA256, GenKeyLength: 32}},
+	} {
+		for _, rngAlgo := range []crypka.RNGAlgo{nil, aesCtrRNG} {
+			tc := tc
+			rngAlgo := rngAlgo
+
+			name := tc.name
+			if rngAlgo != nil {
+				name += "_aes_ctr_rng"
+			}
+
+			t.Run(name, func(t *testing.T) {
+				kd := &crypka.KeyDeriver{
+					Master:  master,
+					RNGAlgo: rngAlgo,
+				}
+
+				derive := func(kd *crypka.KeyDeriver, path string) []byte {
+					secret, _, err := kd.DeriveKey(nil, path, tc.algo)
+					if err != nil {
+						t.Fatal(err)
+					}
+					data, err := crypka.MarshalKeyToSlice(secret)
+					if err != nil {
+						t.Fatal(err)
+					}
+					return data
+				}
+
+				k1 := derive(kd, "tenant/42/enc")
+				k2 := derive(kd, "tenant/42/enc")
+				if !bytes.Equal(k1, k2) {
+					t.Error("derivation is not deterministic")
+					return
+				}
+
+				for _, otherPath := range []string{"tenant/43/enc", "tenant/42", "tenant42/enc", "tenant/42/enc/x"} {
+					other := derive(kd, otherPath)
+					if bytes.Equal(k1, other) {
+						t.Error("expected different keys for different paths, but got same for", otherPath)
+						return
+					}
+				}
+
+				child, err := kd.Child("tenant/42")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if !bytes.Equal(derive(child, "enc"), k1) {
+					t.Error("expected child to derive same key as parent with full path")
+					return
+				}
+			})
+		}
+	}
+}
+
+func TestKeyDeriver_PublicMatchesSecret(t *testing.T) {
+	kd := &crypka.KeyDeriver{
+		Master: bytes.Repeat([]byte{1}, 32),
+	}
+
+	secret, public, err := kd.DeriveKey(nil, "kx", &crypka.X25519KXAlgo{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	derived, err := crypka.DerivePublicKey(nil, secret)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	equal, err := crypka.KeysEqual(public, derived)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !equal {
+		t.Error("public key does not match secret one")
+		return
+	}
+}
+
+func TestKeyDeriver_Invalid(t *testing.T) {
+	algo := &crypka.X25519KXAlgo{}
+
+	_, _, err := crypka.DeriveKey(nil, []byte("short"), "kx", algo)
+	if !errors.Is(err, crypka.ErrKeyDerivationInvalidMaster) {
+		t.Error("expected invalid master error, got", err)
+		return
+	}
+
+	master := bytes.Repeat([]byte{1}, 32)
+	for _, path := range []string{"", "/", "a//b", "a/", "/a"} {
+		_, _, err = crypka.DeriveKey(nil, master, path, algo)
+		if !errors.Is(err, crypka.ErrKeyDerivationInvalidLabel) {
+			t.Error("expected invalid label error for", path, "got", err)
+			return
+		}
+	}
+
+	_, _, err = crypka.DeriveKey(nil, master, "a", struct{}{})
+	if !errors.Is(err, crypka.ErrKeyDerivationUnsupportedAlgo) {
+		t.Error("expected unsupported algo error, got", err)
+		return
+	}
+}
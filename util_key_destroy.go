@@ -0,0 +1,53 @@
+package crypka
+
+// DestroyableKey is secret key, which is able to wipe its material from memory.
+// Once key is destroyed, all its methods fail with ErrKeyDestroyed.
+//
+// Note: encryptors, signers and other objects created from key before it was destroyed may either keep working,
+// since they hold their own copy of key material, or fail with ErrKeyDestroyed. They should be dropped as well.
+//
+// Note #2: Destroy must not be called concurrently with other methods of key.
+type DestroyableKey interface {
+	Destroy()
+}
+
+// DestroyKey destroys key if it implements DestroyableKey.
+// Returns true if it did so.
+func DestroyKey(key interface{}) (ok bool) {
+	dk, ok := key.(DestroyableKey)
+	if ok {
+		dk.Destroy()
+	}
+	return
+}
+
+func zeroBytes(buf []byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
+}
+
+// allocKeyMaterial allocates buffer for secret key material.
+// If context requests it, memory is locked, so it's never swapped to disk.
+func allocKeyMaterial(ctx AnyContext, length int) []byte {
+	if ctx != nil && ctx.LockKeyMemory {
+		buf, ok := allocLockedMemory(length)
+		if ok {
+			return buf
+		}
+	}
+	return make([]byte, length)
+}
+
+func copyKeyMaterial(ctx AnyContext, data []byte) []byte {
+	buf := allocKeyMaterial(ctx, len(data))
+	copy(buf, data)
+	return buf
+}
+
+// destroyKeyMaterial zeroes buffer allocated with allocKeyMaterial and frees it, if it was locked.
+// Buffer must not be used afterwards.
+func destroyKeyMaterial(buf []byte) {
+	zeroBytes(buf)
+	freeLockedMemory(buf)
+}
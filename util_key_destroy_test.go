@@ -0,0 +1,147 @@
+package crypka_test
+
+import (
+	"crypto"
+	"errors"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+
+	// Required, so hash is available
+	_ "crypto/sha256"
+)
+
+func TestKeyDestroy(t *testing.T) {
+	reg := crypka.NewRegistry()
+	crypka.RegisterAES128GCM(reg)
+	crypka.RegisterAESKW(reg)
+	crypka.RegisterSTLHashes(reg)
+	crypka.RegisterEd25519(reg, crypka.RegisterEd25519Options{})
+	crypka.RegisterX25519(reg)
+
+	getEncSymm := func(name string) func(ctx crypka.KeyGenerationContext) (interface{}, error) {
+		return func(ctx crypka.KeyGenerationContext) (interface{}, error) {
+			var algo crypka.EncSymmAlgo
+			err := reg.GetAlgorithmTyped(name, &algo)
+			if err != nil {
+				return nil, err
+			}
+			return algo.GenerateKey(ctx, nil)
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		generate func(ctx crypka.KeyGenerationContext) (interface{}, error)
+	}{
+		{"aes-128-gcm", getEncSymm("aes-128-gcm-rng")},
+		{"aes-256-kwp", getEncSymm("aes-256-kwp")},
+		{"xor", func(ctx crypka.KeyGenerationContext) (interface{}, error) {
+			return (&crypka.XorEncSymmAlgo{GenerateKeyLength: 16}).GenerateKey(ctx, nil)
+		}},
+		{"cpk-stream", func(ctx crypka.KeyGenerationContext) (interface{}, error) {
+			var algo crypka.EncSymmAlgo
+			err := reg.GetAlgorithmTyped("aes-128-gcm-counter", &algo)
+			if err != nil {
+				return nil, err
+			}
+			return (&crypka.CPKStreamSymmEncAlgo{EncSymmAlgo: algo}).GenerateKey(ctx, nil)
+		}},
+		{"hmac", func(ctx crypka.KeyGenerationContext) (interface{}, error) {
+			return (&crypka.HMACSignAlgorithm{Hash: crypto.SHA256, GenKeyLength: 32}).GenerateKey(ctx, nil)
+		}},
+		{"ed25519", func(ctx crypka.KeyGenerationContext) (interface{}, error) {
+			var algo crypka.SignAsymAlgo
+			err := reg.GetAlgorithmTyped("ed25519-sha-256", &algo)
+			if err != nil {
+				return nil, err
+			}
+			sk, _, err := algo.GenerateKeyPair(ctx, nil)
+			return sk, err
+		}},
+		{"x25519", func(ctx crypka.KeyGenerationContext) (interface{}, error) {
+			_, sec, err := (&crypka.X25519KXAlgo{}).GenerateKXPair(ctx, nil)
+			return sec, err
+		}},
+		{"enc-kx", func(ctx crypka.KeyGenerationContext) (interface{}, error) {
+			var encAlgo crypka.EncSymmAlgo
+			err := reg.GetAlgorithmTyped("aes-128-gcm-counter", &encAlgo)
+			if err != nil {
+				return nil, err
+			}
+			_, dk, err := (&crypka.EncAsymKXAlgo{
+				KXAlgo:      &crypka.X25519KXAlgo{},
+				EncSymmAlgo: encAlgo,
+			}).GenerateKeyPair(ctx, nil)
+			return dk, err
+		}},
+	} {
+		tc := tc
+		for _, lockMemory := range []bool{false, true} {
+			ctx := &crypka.Context{
+				LockKeyMemory: lockMemory,
+			}
+
+			name := tc.name
+			if lockMemory {
+				name += "_locked"
+			}
+
+			t.Run(name, func(t *testing.T) {
+				key, err := tc.generate(ctx)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				err = useKey(key)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				if !crypka.DestroyKey(key) {
+					t.Error("expected key to be destroyable")
+					return
+				}
+
+				err = useKey(key)
+				if !errors.Is(err, crypka.ErrKeyDestroyed) {
+					t.Error("expected key destroyed error, got", err)
+					return
+				}
+
+				_, err = crypka.MarshalKeyToSlice(key)
+				if !errors.Is(err, crypka.ErrKeyDestroyed) {
+					t.Error("expected key destroyed error when marshaling, got", err)
+					return
+				}
+			})
+		}
+	}
+}
+
+func useKey(key interface{}) (err error) {
+	switch typedKey := key.(type) {
+	case crypka.EncKey:
+		_, err = typedKey.MakeEncryptor(nil)
+	case crypka.DecKey:
+		_, err = typedKey.MakeDecryptor(nil)
+	case crypka.SigningKey:
+		var signer crypka.Signer
+		signer, err = typedKey.MakeSigner(nil)
+		if err != nil {
+			return
+		}
+		_, err = signer.Finalize(nil)
+	default:
+		algo := &crypka.X25519KXAlgo{}
+		var pub crypka.KXPublic
+		pub, _, err = algo.GenerateKXPair(nil, nil)
+		if err != nil {
+			return
+		}
+		err = algo.PerformExchange(nil, pub, key, make([]byte, 32))
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package crypka
+
+// KeyFormat is name of format used outside of crypka, which keys may be exported to and imported from.
+type KeyFormat string
+
+const (
+	// PEM encoded PKCS#8 for secret keys and PKIX(SubjectPublicKeyInfo) for public keys.
+	// This is what OpenSSL uses.
+	PEMKeyFormat KeyFormat = "pem"
+
+	// JSON Web Key as described in RFC 7517.
+	// Octet key pairs(ed25519, x25519) are encoded as described in RFC 8037.
+	JWKKeyFormat KeyFormat = "jwk"
+
+	// OpenSSH authorized_keys line for public keys and "OPENSSH PRIVATE KEY" PEM block for secret keys.
+	// Secret keys are exported unencrypted.
+	OpenSSHKeyFormat KeyFormat = "openssh"
+)
+
+// FormatExportableKey is key, which can be exported to formats used outside of crypka.
+//
+// Note: exported secret keys are not protected in any way. See WrapKey if they have to be stored.
+type FormatExportableKey interface {
+	ExportKeyFormat(ctx KeyContext, format KeyFormat) (data []byte, err error)
+}
+
+// FormatKeyImporter is algorithm, which is able to import keys exported by its FormatExportableKey keys.
+// Key returned is exactly same, as one which would be returned by parse function of algorithm, so
+// it's either public or secret key depending on data provided.
+type FormatKeyImporter interface {
+	ImportKeyFormat(ctx KeyParseContext, format KeyFormat, data []byte) (key interface{}, err error)
+}
+
+// ExportKeyFormat exports key to given format.
+// It returns ErrKeyFormatUnsupported if key can't be exported to that format.
+func ExportKeyFormat(ctx KeyContext, key interface{}, format KeyFormat) (data []byte, err error) {
+	exportable, ok := key.(FormatExportableKey)
+	if !ok {
+		err = ErrKeyFormatUnsupported
+		return
+	}
+
+	return exportable.ExportKeyFormat(ctx, format)
+}
+
+// ImportKeyFormat imports key using algorithm with given name from registry.
+// If registry is nil, GlobalRegistry is used.
+func ImportKeyFormat(
+	ctx KeyParseContext,
+	reg Registry,
+	algo string,
+	format KeyFormat,
+	data []byte,
+) (key interface{}, err error) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	var importer FormatKeyImporter
+	err = reg.GetAlgorithmTyped(algo, &importer)
+	if err == ErrInvalidAlgorithmType {
+		err = ErrKeyFormatUnsupported
+		return
+	} else if err != nil {
+		return
+	}
+
+	return importer.ImportKeyFormat(ctx, format, data)
+}
@@ -0,0 +1,198 @@
+package crypka
+
+import (
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+)
+
+// Octet key pair(OKP) is key type from RFC 8037, which covers ed25519 and x25519.
+// Both of them are encoded in same way in all supported formats, so code is shared.
+
+const (
+	pemPrivateKeyType = "PRIVATE KEY"
+	pemPublicKeyType  = "PUBLIC KEY"
+)
+
+// OIDs from RFC 8410.
+var (
+	oidX25519  = asn1.ObjectIdentifier{1, 3, 101, 110}
+	oidEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+)
+
+type okpKeyType struct {
+	Curve string // JWK crv parameter
+	OID   asn1.ObjectIdentifier
+}
+
+var (
+	okpEd25519 = okpKeyType{
+		Curve: "Ed25519",
+		OID:   oidEd25519,
+	}
+	okpX25519 = okpKeyType{
+		Curve: "X25519",
+		OID:   oidX25519,
+	}
+)
+
+type okpAlgorithmIdentifier struct {
+	Algorithm asn1.ObjectIdentifier
+}
+
+type okpPKIX struct {
+	Algorithm okpAlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+type okpPKCS8 struct {
+	Version    int
+	Algorithm  okpAlgorithmIdentifier
+	PrivateKey []byte
+}
+
+type okpJWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	D   string `json:"d,omitempty"`
+}
+
+// okpKey is decoded key. Secret is set only for secret keys.
+// Public is always set for public keys. For secret keys it's set only if format carries it,
+// like JWK does, and PEM does not, so users have to derive it from Secret, when it's nil.
+type okpKey struct {
+	Secret []byte
+	Public []byte
+}
+
+func (ty okpKeyType) MarshalPEM(key okpKey) (res []byte, err error) {
+	if key.Secret != nil {
+		// private key is wrapped in another octet string, see RFC 8410 section 7
+		var innerKey []byte
+		innerKey, err = asn1.Marshal(key.Secret)
+		if err != nil {
+			return
+		}
+
+		var der []byte
+		der, err = asn1.Marshal(okpPKCS8{
+			Algorithm: okpAlgorithmIdentifier{
+				Algorithm: ty.OID,
+			},
+			PrivateKey: innerKey,
+		})
+		if err != nil {
+			return
+		}
+
+		res = pem.EncodeToMemory(&pem.Block{
+			Type:  pemPrivateKeyType,
+			Bytes: der,
+		})
+		return
+	}
+
+	der, err := asn1.Marshal(okpPKIX{
+		Algorithm: okpAlgorithmIdentifier{
+			Algorithm: ty.OID,
+		},
+		PublicKey: asn1.BitString{
+			Bytes:     key.Public,
+			BitLength: len(key.Public) * 8,
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	res = pem.EncodeToMemory(&pem.Block{
+		Type:  pemPublicKeyType,
+		Bytes: der,
+	})
+	return
+}
+
+// ParsePEM parses PEM key. Public part is not derived for secret keys.
+func (ty okpKeyType) ParsePEM(data []byte, keyLength int) (key okpKey, err error) {
+	block, rest := pem.Decode(data)
+	if block == nil || len(rest) != 0 || len(block.Headers) != 0 {
+		err = ErrKeyParseField
+		return
+	}
+
+	switch block.Type {
+	case pemPrivateKeyType:
+		var pkcs8 okpPKCS8
+		rest, err = asn1.Unmarshal(block.Bytes, &pkcs8)
+		if err != nil || len(rest) != 0 || pkcs8.Version != 0 || !pkcs8.Algorithm.Algorithm.Equal(ty.OID) {
+			err = ErrKeyParseField
+			return
+		}
+
+		var secret []byte
+		rest, err = asn1.Unmarshal(pkcs8.PrivateKey, &secret)
+		if err != nil || len(rest) != 0 || len(secret) != keyLength {
+			err = ErrKeyParseField
+			return
+		}
+
+		key.Secret = secret
+	case pemPublicKeyType:
+		var pkix okpPKIX
+		rest, err = asn1.Unmarshal(block.Bytes, &pkix)
+		if err != nil || len(rest) != 0 || !pkix.Algorithm.Algorithm.Equal(ty.OID) {
+			err = ErrKeyParseField
+			return
+		}
+
+		if pkix.PublicKey.BitLength != keyLength*8 {
+			err = ErrKeyParseField
+			return
+		}
+
+		key.Public = pkix.PublicKey.Bytes
+	default:
+		err = ErrKeyParseField
+	}
+	return
+}
+
+func (ty okpKeyType) MarshalJWK(key okpKey) (res []byte, err error) {
+	jwk := okpJWK{
+		Kty: "OKP",
+		Crv: ty.Curve,
+		X:   base64.RawURLEncoding.EncodeToString(key.Public),
+	}
+	if key.Secret != nil {
+		jwk.D = base64.RawURLEncoding.EncodeToString(key.Secret)
+	}
+
+	return json.Marshal(jwk)
+}
+
+// ParseJWK parses JWK key. For secret keys, caller must check if public part matches secret one.
+func (ty okpKeyType) ParseJWK(data []byte, keyLength int) (key okpKey, err error) {
+	var jwk okpJWK
+	err = json.Unmarshal(data, &jwk)
+	if err != nil || jwk.Kty != "OKP" || jwk.Crv != ty.Curve {
+		err = ErrKeyParseField
+		return
+	}
+
+	key.Public, err = base64.RawURLEncoding.Strict().DecodeString(jwk.X)
+	if err != nil || len(key.Public) != keyLength {
+		err = ErrKeyParseField
+		return
+	}
+
+	if len(jwk.D) != 0 {
+		key.Secret, err = base64.RawURLEncoding.Strict().DecodeString(jwk.D)
+		if err != nil || len(key.Secret) != keyLength {
+			err = ErrKeyParseField
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,173 @@
+package crypka
+
+import (
+	"encoding/json"
+)
+
+// serializableKey is implemented by keys, which know name of their algorithm.
+type serializableKey interface {
+	toSerializedKey() (sk SerializedKey, err error)
+}
+
+// namedAlgorithm is implemented by algorithms, which tag their keys with their name, when keys are marshaled to text or JSON.
+type namedAlgorithm interface {
+	getAlgoName() string
+	setAlgoName(name string)
+}
+
+// tagAlgoName is called by registry, so algorithm knows name it's registered with.
+// Name is not changed if it was set already, for instance when algorithm is registered under multiple names.
+func tagAlgoName(name string, algo interface{}) {
+	if na, ok := algo.(namedAlgorithm); ok && len(na.getAlgoName()) == 0 {
+		na.setAlgoName(name)
+	}
+}
+
+// makeSerializedKey tags key with algorithm name.
+// Keys of algorithms, which were never registered and have no name set, can't be serialized.
+func makeSerializedKey(algo string, ty SerializedKeyType, key MarshalableKey) (sk SerializedKey, err error) {
+	if len(algo) == 0 {
+		err = ErrKeyNotMarshalable
+		return
+	}
+
+	data, err := MarshalKeyToSlice(key)
+	if err != nil {
+		return
+	}
+
+	sk = SerializedKey{
+		Algo: algo,
+		Type: ty,
+		Data: data,
+	}
+	return
+}
+
+// secretKeyMarshalGuard is embedded in secret keys, so they are never accidentally marshaled with encoding/json
+// or other encoding package, which would otherwise ignore unexported fields and yield empty value silently.
+//
+// Use ExportableSecret in order to marshal them.
+type secretKeyMarshalGuard struct{}
+
+func (secretKeyMarshalGuard) MarshalText() (res []byte, err error) {
+	err = ErrKeySecretNotExportable
+	return
+}
+
+func (secretKeyMarshalGuard) MarshalJSON() (res []byte, err error) {
+	err = ErrKeySecretNotExportable
+	return
+}
+
+// ExportableSecret explicitly allows secret key to be marshaled to text or JSON in same form as public keys are.
+// It only works for keys of algorithms, which know their name, which is set when they are registered.
+//
+// Note: key marshaled this way is not protected in any way. See WrapKey if it has to be stored.
+type ExportableSecret struct {
+	Key interface{}
+}
+
+func (es ExportableSecret) toSerializedKey() (sk SerializedKey, err error) {
+	serializable, ok := es.Key.(serializableKey)
+	if !ok {
+		err = ErrKeyNotMarshalable
+		return
+	}
+
+	return serializable.toSerializedKey()
+}
+
+func (es ExportableSecret) MarshalText() (res []byte, err error) {
+	sk, err := es.toSerializedKey()
+	if err != nil {
+		return
+	}
+	return sk.MarshalText()
+}
+
+func (es ExportableSecret) MarshalJSON() (res []byte, err error) {
+	sk, err := es.toSerializedKey()
+	if err != nil {
+		return
+	}
+	return sk.MarshalJSON()
+}
+
+func castParsedKey[T any](key interface{}) (res T, err error) {
+	res, ok := key.(T)
+	if !ok {
+		err = ErrInvalidAlgorithmType
+		return
+	}
+	return
+}
+
+// UnmarshalKeyJSON parses key marshaled to JSON and resolves its algorithm using registry.
+// If registry is nil, GlobalRegistry is used.
+//
+// T is type of key expected, like VerifyingKey or KXPublic. If parsed key has other type, error is returned.
+func UnmarshalKeyJSON[T any](ctx KeyParseContext, reg Registry, data []byte) (key T, err error) {
+	var sk SerializedKey
+	err = json.Unmarshal(data, &sk)
+	if err != nil {
+		return
+	}
+
+	rawKey, err := ParseSerializedKey(ctx, reg, sk)
+	if err != nil {
+		return
+	}
+	return castParsedKey[T](rawKey)
+}
+
+// UnmarshalKeyText is like UnmarshalKeyJSON, but parses text form of key.
+func UnmarshalKeyText[T any](ctx KeyParseContext, reg Registry, text []byte) (key T, err error) {
+	var sk SerializedKey
+	err = sk.UnmarshalText(text)
+	if err != nil {
+		return
+	}
+
+	rawKey, err := ParseSerializedKey(ctx, reg, sk)
+	if err != nil {
+		return
+	}
+	return castParsedKey[T](rawKey)
+}
+
+// KeyField is key, which may be embedded in config structs, so it's unmarshaled by encoding/json or other encoding packages.
+// Algorithms are resolved using GlobalRegistry, which should be locked at that point.
+//
+// Secret keys are marshaled like they were wrapped in ExportableSecret, since putting them in KeyField is explicit enough.
+type KeyField[T any] struct {
+	Key T
+}
+
+func (kf KeyField[T]) MarshalText() (res []byte, err error) {
+	return ExportableSecret{Key: kf.Key}.MarshalText()
+}
+
+func (kf KeyField[T]) MarshalJSON() (res []byte, err error) {
+	return ExportableSecret{Key: kf.Key}.MarshalJSON()
+}
+
+func (kf *KeyField[T]) UnmarshalText(text []byte) (err error) {
+	key, err := UnmarshalKeyText[T](nil, nil, text)
+	if err != nil {
+		return
+	}
+
+	kf.Key = key
+	return
+}
+
+func (kf *KeyField[T]) UnmarshalJSON(data []byte) (err error) {
+	key, err := UnmarshalKeyJSON[T](nil, nil, data)
+	if err != nil {
+		return
+	}
+
+	kf.Key = key
+	return
+}
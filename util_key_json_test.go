@@ -0,0 +1,341 @@
+package crypka_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+func makeKeyJSONRegistry() crypka.Registry {
+	reg := makeKeyFormatRegistry()
+	crypka.RegisterAES128GCM(reg)
+	crypka.RegisterAESKW(reg)
+	crypka.RegisterSTLHMACs(reg, crypka.RegisterSTLHMACsOptions{})
+
+	var inner crypka.EncSymmAlgo
+	err := reg.GetAlgorithmTyped("aes-128-gcm-counter", &inner)
+	if err != nil {
+		panic(err)
+	}
+
+	reg.RegisterAlgo("cpk-stream-aes-128-gcm-counter", &crypka.CPKStreamSymmEncAlgo{
+		EncSymmAlgo: inner,
+	})
+	reg.RegisterAlgo("x25519-aes-128-gcm-counter", &crypka.EncAsymKXAlgo{
+		EncSymmAlgo:    inner,
+		KXAlgo:         &crypka.X25519KXAlgo{},
+		KXResultLength: 16,
+	})
+	return reg
+}
+
+func TestKeyJSON_PublicKeys(t *testing.T) {
+	reg := makeKeyJSONRegistry()
+
+	var signAlgo crypka.SignAsymAlgo
+	err := reg.GetAlgorithmTyped("ed25519-sha-512", &signAlgo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, vk, err := signAlgo.GenerateKeyPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var kxAlgo crypka.KXAlgo
+	err = reg.GetAlgorithmTyped("x25519", &kxAlgo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pub, _, err := kxAlgo.GenerateKXPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var encAlgo crypka.EncAsymAlgo
+	err = reg.GetAlgorithmTyped("x25519-aes-128-gcm-counter", &encAlgo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ek, _, err := encAlgo.GenerateKeyPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, tc := range []struct {
+		name string
+		algo string
+		key  interface{}
+	}{
+		{"ed25519", "ed25519-sha-512", vk},
+		{"x25519", "x25519", pub},
+		{"enc_kx", "x25519-aes-128-gcm-counter", ek},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			expected, err := crypka.MarshalKeyToSlice(tc.key)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			encoded, err := json.Marshal(tc.key)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			var sk crypka.SerializedKey
+			err = json.Unmarshal(encoded, &sk)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if sk.Algo != tc.algo || sk.Type != crypka.PublicSerializedKeyType {
+				t.Errorf("invalid tag: %s", encoded)
+				return
+			}
+
+			parsed, err := crypka.UnmarshalKeyJSON[interface{}](nil, reg, encoded)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			actual, err := crypka.MarshalKeyToSlice(parsed)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !bytes.Equal(expected, actual) {
+				t.Error("JSON round trip yielded different key")
+				return
+			}
+
+			text, err := tc.key.(interface{ MarshalText() ([]byte, error) }).MarshalText()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			parsed, err = crypka.UnmarshalKeyText[interface{}](nil, reg, text)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			actual, err = crypka.MarshalKeyToSlice(parsed)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !bytes.Equal(expected, actual) {
+				t.Error("text round trip yielded different key")
+				return
+			}
+		})
+	}
+
+	t.Run("not_registered", func(t *testing.T) {
+		_, vk, err := (&crypka.Ed25519SignAsymAlgo{}).GenerateKeyPair(nil, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		_, err = json.Marshal(vk)
+		if !errors.Is(err, crypka.ErrKeyNotMarshalable) {
+			t.Error("expected key of algorithm, which is not registered, to be rejected, got", err)
+		}
+	})
+
+	t.Run("type_mismatch", func(t *testing.T) {
+		encoded, err := json.Marshal(pub)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		_, err = crypka.UnmarshalKeyJSON[crypka.VerifyingKey](nil, reg, encoded)
+		if !errors.Is(err, crypka.ErrInvalidAlgorithmType) {
+			t.Error("expected invalid algorithm type error, got", err)
+		}
+	})
+}
+
+func TestKeyJSON_SecretKeys(t *testing.T) {
+	reg := makeKeyFormatRegistry()
+
+	var signAlgo crypka.SignAsymAlgo
+	err := reg.GetAlgorithmTyped("ed25519-sha-256", &signAlgo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	sk, _, err := signAlgo.GenerateKeyPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	symmKey, err := (&crypka.XorEncSymmAlgo{GenerateKeyLength: 16}).GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("refuses_by_default", func(t *testing.T) {
+		for _, key := range []interface{}{sk, symmKey} {
+			_, err := json.Marshal(key)
+			if !errors.Is(err, crypka.ErrKeySecretNotExportable) {
+				t.Error("expected not exportable error, got", err)
+			}
+		}
+	})
+
+	t.Run("exportable_secret", func(t *testing.T) {
+		encoded, err := json.Marshal(crypka.ExportableSecret{Key: sk})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		parsed, err := crypka.UnmarshalKeyJSON[crypka.SigningKey](nil, reg, encoded)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		expected, _ := crypka.MarshalKeyToSlice(sk)
+		actual, _ := crypka.MarshalKeyToSlice(parsed)
+		if !bytes.Equal(expected, actual) {
+			t.Error("round trip yielded different key")
+		}
+
+		_, err = json.Marshal(crypka.ExportableSecret{Key: symmKey})
+		if !errors.Is(err, crypka.ErrKeyNotMarshalable) {
+			t.Error("expected key without algorithm name to be rejected, got", err)
+		}
+	})
+}
+
+func TestKeyJSON_SymmetricSecretKeys(t *testing.T) {
+	reg := makeKeyJSONRegistry()
+
+	for _, name := range []string{
+		"aes-128-gcm-rng",
+		"cpk-stream-aes-128-gcm-counter",
+		"aes-256-kwp",
+		"hmac-sha-256",
+	} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			var key interface{}
+			var err error
+			switch algo := reg.GetAlgo(name).(type) {
+			case crypka.EncSymmAlgo:
+				key, err = algo.GenerateKey(nil, nil)
+			case crypka.SignSymmAlgo:
+				key, err = algo.GenerateKey(nil, nil)
+			default:
+				t.Fatal("unexpected algorithm type")
+			}
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			encoded, err := json.Marshal(crypka.ExportableSecret{Key: key})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			var sk crypka.SerializedKey
+			err = json.Unmarshal(encoded, &sk)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if sk.Algo != name || sk.Type != crypka.SecretSerializedKeyType {
+				t.Errorf("invalid tag: %s", encoded)
+				return
+			}
+
+			parsed, err := crypka.UnmarshalKeyJSON[interface{}](nil, reg, encoded)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			expected, _ := crypka.MarshalKeyToSlice(key)
+			actual, _ := crypka.MarshalKeyToSlice(parsed)
+			if !bytes.Equal(expected, actual) {
+				t.Error("round trip yielded different key")
+			}
+		})
+	}
+}
+
+func TestKeyJSON_KeyField(t *testing.T) {
+	type config struct {
+		Verifying crypka.KeyField[crypka.VerifyingKey] `json:"verifying"`
+		Secret    crypka.KeyField[crypka.KXSecret]     `json:"secret"`
+	}
+
+	var signAlgo crypka.SignAsymAlgo
+	err := crypka.GlobalRegistry.GetAlgorithmTyped("ed25519-sha-256", &signAlgo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, vk, err := signAlgo.GenerateKeyPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var kxAlgo crypka.KXAlgo
+	err = crypka.GlobalRegistry.GetAlgorithmTyped("x25519", &kxAlgo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, sec, err := kxAlgo.GenerateKXPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	original := config{}
+	original.Verifying.Key = vk
+	original.Secret.Key = sec
+
+	encoded, err := json.Marshal(original)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var parsed config
+	err = json.Unmarshal(encoded, &parsed)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	reencoded, err := json.Marshal(parsed)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.Equal(encoded, reencoded) {
+		t.Errorf("config changed after round trip:\n%s\n%s", encoded, reencoded)
+	}
+}
@@ -0,0 +1,83 @@
+package crypka
+
+import (
+	"crypto/subtle"
+	"reflect"
+)
+
+// PublicKeyDeriver is implemented by secret keys of asymmetric algorithms, which are able to compute their public counterpart.
+//
+// Returned value is VerifyingKey for signing keys, KXPublic for KX secrets and EncKey for DecKeys.
+type PublicKeyDeriver interface {
+	DerivePublicKey(ctx KeyContext) (interface{}, error)
+}
+
+// DerivePublicKey returns public key matching given secret key.
+// Returns ErrKeyPublicNotDerivable if key does not implement PublicKeyDeriver.
+func DerivePublicKey(ctx KeyContext, key interface{}) (pub interface{}, err error) {
+	deriver, ok := key.(PublicKeyDeriver)
+	if !ok {
+		err = ErrKeyPublicNotDerivable
+		return
+	}
+
+	return deriver.DerivePublicKey(ctx)
+}
+
+// DeriveVerifyingKey returns VerifyingKey matching given SigningKey.
+func DeriveVerifyingKey(ctx KeyContext, sk SigningKey) (vk VerifyingKey, err error) {
+	pub, err := DerivePublicKey(ctx, sk)
+	if err != nil {
+		return
+	}
+
+	vk, ok := pub.(VerifyingKey)
+	if !ok {
+		err = ErrKeyPublicNotDerivable
+		return
+	}
+	return
+}
+
+// DeriveKXPublic returns KXPublic matching given KXSecret.
+func DeriveKXPublic(ctx KeyContext, secret KXSecret) (public KXPublic, err error) {
+	return DerivePublicKey(ctx, secret)
+}
+
+// DeriveEncKey returns EncKey matching given DecKey.
+func DeriveEncKey(ctx KeyContext, dk DecKey) (ek EncKey, err error) {
+	pub, err := DerivePublicKey(ctx, dk)
+	if err != nil {
+		return
+	}
+
+	ek, ok := pub.(EncKey)
+	if !ok {
+		err = ErrKeyPublicNotDerivable
+		return
+	}
+	return
+}
+
+// KeysEqual compares marshaled forms of given keys in constant time.
+// Keys of different types are never equal.
+//
+// Note: only contents of keys are compared in constant time, their lengths are not.
+// Note #2: algorithm parameters, which are not part of marshaled key, are not compared.
+func KeysEqual(a, b interface{}) (equal bool, err error) {
+	aData, err := MarshalKeyToSlice(a)
+	if err != nil {
+		return
+	}
+	defer zeroBytes(aData)
+
+	bData, err := MarshalKeyToSlice(b)
+	if err != nil {
+		return
+	}
+	defer zeroBytes(bData)
+
+	typesEqual := reflect.TypeOf(a) == reflect.TypeOf(b)
+	equal = typesEqual && subtle.ConstantTimeCompare(aData, bData) == 1
+	return
+}
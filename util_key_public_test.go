@@ -0,0 +1,237 @@
+package crypka_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+func TestDerivePublicKey_Ed25519(t *testing.T) {
+	reg := crypka.NewRegistry()
+	crypka.RegisterSTLHashes(reg)
+	crypka.RegisterEd25519(reg, crypka.RegisterEd25519Options{})
+
+	var algo crypka.SignAsymAlgo
+	err := reg.GetAlgorithmTyped("ed25519-sha-256", &algo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	sk, vk, err := algo.GenerateKeyPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// simulate loading secret key only from disk
+	rawSk, err := crypka.MarshalKeyToSlice(sk)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	loadedSk, err := algo.ParseSigningKey(nil, rawSk)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	derivedVk, err := crypka.DeriveVerifyingKey(nil, loadedSk)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	equal, err := crypka.KeysEqual(vk, derivedVk)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !equal {
+		t.Error("expected derived key to be equal to generated one")
+		return
+	}
+
+	data := []byte("some data")
+	signer, err := loadedSk.MakeSigner(nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	signer.Write(data)
+	sign, err := signer.Finalize(nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	verifier, err := derivedVk.MakeVerifier(nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	verifier.Write(data)
+	err = verifier.Verify(sign)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestDerivePublicKey_X25519(t *testing.T) {
+	algo := &crypka.X25519KXAlgo{}
+	public, secret, err := algo.GenerateKXPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	derived, err := crypka.DeriveKXPublic(nil, secret)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	equal, err := crypka.KeysEqual(public, derived)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !equal {
+		t.Error("expected derived key to be equal to generated one")
+		return
+	}
+
+	otherPublic, otherSecret, err := algo.GenerateKXPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	res1 := make([]byte, 32)
+	res2 := make([]byte, 32)
+	err = algo.PerformExchange(nil, derived, otherSecret, res1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = algo.PerformExchange(nil, otherPublic, secret, res2)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.Equal(res1, res2) {
+		t.Error("exchange results do not match")
+		return
+	}
+}
+
+func TestDerivePublicKey_EncKX(t *testing.T) {
+	reg := crypka.NewRegistry()
+	crypka.RegisterAES128GCM(reg)
+
+	var encAlgo crypka.EncSymmAlgo
+	err := reg.GetAlgorithmTyped("aes-128-gcm-counter", &encAlgo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	algo := &crypka.EncAsymKXAlgo{
+		KXAlgo:      &crypka.X25519KXAlgo{},
+		EncSymmAlgo: encAlgo,
+	}
+	ek, dk, err := algo.GenerateKeyPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	derived, err := crypka.DeriveEncKey(nil, dk)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	equal, err := crypka.KeysEqual(ek, derived)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !equal {
+		t.Error("expected derived key to be equal to generated one")
+		return
+	}
+}
+
+func TestDerivePublicKey_NotDerivable(t *testing.T) {
+	key, err := (&crypka.XorEncSymmAlgo{GenerateKeyLength: 16}).GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err = crypka.DerivePublicKey(nil, key)
+	if !errors.Is(err, crypka.ErrKeyPublicNotDerivable) {
+		t.Error("expected not derivable error, got", err)
+		return
+	}
+}
+
+func TestKeysEqual(t *testing.T) {
+	algo := &crypka.XorEncSymmAlgo{MinKeyLength: 1, MaxKeyLength: 64}
+	k1, err := algo.ParseSymmEncKey(nil, []byte("0123456789abcdef"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	k2, err := algo.ParseSymmEncKey(nil, []byte("0123456789abcdef"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	k3, err := algo.ParseSymmEncKey(nil, []byte("0123456789abcdeF"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// same bytes, but different key type
+	x25519Pub, err := (&crypka.X25519KXAlgo{}).ParseKXPublic(nil, bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	k4, err := algo.ParseSymmEncKey(nil, bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, tc := range []struct {
+		a, b  interface{}
+		equal bool
+	}{
+		{k1, k2, true},
+		{k1, k3, false},
+		{x25519Pub, k4, false},
+	} {
+		equal, err := crypka.KeysEqual(tc.a, tc.b)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if equal != tc.equal {
+			t.Error("invalid comparison result, expected", tc.equal)
+			return
+		}
+	}
+
+	_, err = crypka.KeysEqual(k1, struct{}{})
+	if !errors.Is(err, crypka.ErrKeyNotMarshalable) {
+		t.Error("expected not marshalable error, got", err)
+		return
+	}
+}
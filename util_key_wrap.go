@@ -0,0 +1,149 @@
+package crypka
+
+import (
+	"bytes"
+)
+
+const maxWrappedKeyAlgoNameLength = 256
+
+// checkKEK rejects keys, which yield block encryptors, but can't encrypt payloads of arbitrary length.
+// Length of wrapped payload depends on length of algorithm name and marshaled key, so it can't be relied on.
+func checkKEK(kek EncSymmKey) (err error) {
+	if kw, ok := kek.(*aesKWKey); ok && !kw.padded {
+		err = ErrKeyWrapUnsupportedKEK
+	}
+	return
+}
+
+// WrapKey marshals key and encrypts it using key encryption key(KEK), so it can be stored safely.
+// Name of algorithm, which key belongs to, is wrapped along with key, so it's authenticated as well.
+//
+// KEK must yield eager authenticated block encryptors, like aes-*-kwp or AEAD with RNG nonce.
+// Chain and stream encryptors are rejected, since each wrap would use fresh encryptor and reuse its nonces.
+// Unpadded aes-*-kw is rejected as well, since it can't encrypt payloads of most lengths.
+//
+// Note: unauthenticated KEK algorithms(like XOR one) are not detected, so it's user's job to not use them.
+func WrapKey(ctx KeyContext, kek EncSymmKey, algo string, key MarshalableKey) (res []byte, err error) {
+	if len(algo) > maxWrappedKeyAlgoNameLength {
+		err = ErrKeyWrapInvalid
+		return
+	}
+
+	header, _ := ByteVar.AppendToBuf(nil, uint64(len(algo)))
+	header = append(header, algo...)
+
+	buf := bytes.NewBuffer(header)
+
+	err = key.MarshalToWriter(buf)
+	if err != nil {
+		return
+	}
+
+	payload := buf.Bytes()
+	defer func() {
+		for i := range payload {
+			payload[i] = 0
+		}
+	}()
+
+	err = checkKEK(kek)
+	if err != nil {
+		return
+	}
+
+	enc, err := kek.MakeEncryptor(ctx)
+	if err != nil {
+		return
+	}
+	if enc.GetEncInfo().EncType != EncTypeBlock {
+		err = ErrKeyWrapUnsupportedKEK
+		return
+	}
+
+	res, err = enc.Encrypt(payload, nil)
+	if err != nil {
+		return
+	}
+
+	res, err = enc.Finalize(res)
+	return
+}
+
+// UnwrapKey reverses WrapKey.
+// It returns name of algorithm given to WrapKey and marshaled key, which may be parsed using that algorithm.
+func UnwrapKey(ctx KeyContext, kek EncSymmKey, wrapped []byte) (algo string, data []byte, err error) {
+	err = checkKEK(kek)
+	if err != nil {
+		return
+	}
+
+	dec, err := kek.MakeDecryptor(ctx)
+	if err != nil {
+		return
+	}
+	if dec.GetEncInfo().EncType != EncTypeBlock {
+		err = ErrKeyWrapUnsupportedKEK
+		return
+	}
+
+	payload, err := dec.Decrypt(wrapped, nil)
+	if err != nil {
+		return
+	}
+
+	err = dec.Finalize()
+	if err != nil {
+		return
+	}
+
+	length, sz, err := ByteVar.DecodeAtStart(payload)
+	if err != nil || length > maxWrappedKeyAlgoNameLength || length > uint64(len(payload)-sz) {
+		err = ErrKeyWrapInvalid
+		return
+	}
+
+	algo = string(payload[sz : sz+int(length)])
+	data = payload[sz+int(length):]
+	return
+}
+
+// UnwrapKeyWithRegistry reverses WrapKey and parses unwrapped key using algorithm with name given to WrapKey.
+// Name may also be specification of algorithm, as accepted by BuildAlgorithm.
+// If registry is nil, GlobalRegistry is used.
+//
+// Wrapped key is parsed as secret one, so key returned is one, which would be returned by algorithm's parse method,
+// like SigningKey, KXSecret or EncSymmKey.
+func UnwrapKeyWithRegistry(ctx KeyContext, reg Registry, kek EncSymmKey, wrapped []byte) (algo string, key interface{}, err error) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	algo, data, err := UnwrapKey(ctx, kek, wrapped)
+	if err != nil {
+		return
+	}
+
+	key, err = parseSecretKey(ctx, resolveAlgorithm(reg, algo), data)
+	return
+}
+
+// parseSecretKey parses secret key of any kind using given algorithm.
+func parseSecretKey(ctx KeyParseContext, algo interface{}, data []byte) (key interface{}, err error) {
+	switch typedAlgo := algo.(type) {
+	case nil:
+		err = ErrNoSuchAlgorithm
+	case SignAsymKeyParser:
+		return typedAlgo.ParseSigningKey(ctx, data)
+	case KXParser:
+		return typedAlgo.ParseKXSecret(ctx, data)
+	case EncAsymKeyParser:
+		return typedAlgo.ParseDecKey(ctx, data)
+	case EncSymmKeyParser:
+		return typedAlgo.ParseSymmEncKey(ctx, data)
+	case SignSymmKeyParser:
+		return typedAlgo.ParseSymmSignKey(ctx, data)
+	default:
+		err = ErrInvalidAlgorithmType
+	}
+	return
+}
@@ -0,0 +1,170 @@
+package crypka_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+func TestKeyWrap(t *testing.T) {
+	reg := crypka.NewRegistry()
+	crypka.RegisterAES128GCM(reg)
+	crypka.RegisterAESKW(reg)
+	crypka.RegisterSTLHashes(reg)
+	crypka.RegisterEd25519(reg, crypka.RegisterEd25519Options{})
+
+	var signAlgo crypka.SignAsymAlgo
+	err := reg.GetAlgorithmTyped("ed25519-sha-256", &signAlgo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	sk, _, err := signAlgo.GenerateKeyPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	marshaledSK, err := crypka.MarshalKeyToSlice(sk)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	makeKEK := func(t *testing.T, name string) crypka.EncSymmKey {
+		var algo crypka.EncSymmAlgo
+		err := reg.GetAlgorithmTyped(name, &algo)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		kek, err := algo.GenerateKey(nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return kek
+	}
+
+	for _, name := range []string{
+		"aes-128-kwp",
+		"aes-256-kwp",
+		"aes-128-gcm-rng",
+	} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			kek := makeKEK(t, name)
+
+			wrapped, err := crypka.WrapKey(nil, kek, "ed25519-sha-256", sk.(crypka.MarshalableKey))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			algo, data, err := crypka.UnwrapKey(nil, kek, wrapped)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if algo != "ed25519-sha-256" {
+				t.Error("invalid algorithm name", algo)
+				return
+			}
+			if !bytes.Equal(data, marshaledSK) {
+				t.Error("unwrapped key differs from marshaled one")
+				return
+			}
+
+			_, err = signAlgo.ParseSigningKey(nil, data)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			algo, key, err := crypka.UnwrapKeyWithRegistry(nil, reg, kek, wrapped)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if algo != "ed25519-sha-256" {
+				t.Error("invalid algorithm name", algo)
+				return
+			}
+			if _, ok := key.(crypka.SigningKey); !ok {
+				t.Errorf("expected signing key, got %T", key)
+				return
+			}
+			marshaled, err := crypka.MarshalKeyToSlice(key)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !bytes.Equal(marshaled, marshaledSK) {
+				t.Error("parsed key differs from wrapped one")
+				return
+			}
+
+			for i := range wrapped {
+				modified := append([]byte{}, wrapped...)
+				modified[i] ^= 1
+
+				_, _, err = crypka.UnwrapKey(nil, kek, modified)
+				if err == nil {
+					t.Error("expected modified key to be rejected")
+					return
+				}
+			}
+
+			_, _, err = crypka.UnwrapKey(nil, makeKEK(t, name), wrapped)
+			if err == nil {
+				t.Error("expected unwrap with other key to fail")
+				return
+			}
+		})
+	}
+
+	t.Run("rejects_unpadded_kw_kek", func(t *testing.T) {
+		for _, name := range []string{"aes-128-kw", "aes-256-kw"} {
+			kek := makeKEK(t, name)
+
+			_, err := crypka.WrapKey(nil, kek, "ed25519-sha-256", sk.(crypka.MarshalableKey))
+			if !errors.Is(err, crypka.ErrKeyWrapUnsupportedKEK) {
+				t.Error("expected unsupported KEK error, got", name, err)
+				return
+			}
+
+			_, _, err = crypka.UnwrapKey(nil, kek, make([]byte, 64))
+			if !errors.Is(err, crypka.ErrKeyWrapUnsupportedKEK) {
+				t.Error("expected unsupported KEK error, got", name, err)
+				return
+			}
+		}
+	})
+
+	t.Run("unwrap_with_registry_unknown_algo", func(t *testing.T) {
+		kek := makeKEK(t, "aes-256-kwp")
+
+		wrapped, err := crypka.WrapKey(nil, kek, "no-such-algo", sk.(crypka.MarshalableKey))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		_, _, err = crypka.UnwrapKeyWithRegistry(nil, reg, kek, wrapped)
+		if !errors.Is(err, crypka.ErrNoSuchAlgorithm) {
+			t.Error("expected no such algorithm error, got", err)
+			return
+		}
+	})
+
+	t.Run("rejects_chain_kek", func(t *testing.T) {
+		kek := makeKEK(t, "aes-128-gcm-counter")
+
+		_, err := crypka.WrapKey(nil, kek, "ed25519-sha-256", sk.(crypka.MarshalableKey))
+		if !errors.Is(err, crypka.ErrKeyWrapUnsupportedKEK) {
+			t.Error("expected unsupported KEK error, got", err)
+			return
+		}
+	})
+}
@@ -0,0 +1,488 @@
+package crypka
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+const KeyIDLength = 8
+
+const keyIDDomain = "crypka-key-id\x00"
+
+// KeyID identifies key in keyring.
+// It's derived from algorithm name and marshaled key, so same key always has same ID.
+//
+// Note: since it's short, it's not guaranteed to be unique. Keyrings reject keys, which ID is same as ID of key
+// already in keyring, with ErrKeyringKeyExists, so each ID identifies single key in keyring.
+// For distinct keys it happens with negligible probability, so in practice it means that same key was added twice.
+type KeyID [KeyIDLength]byte
+
+func (id KeyID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// ComputeKeyID computes ID of given key, which belongs to algorithm with given name.
+// For asymmetric algorithms ID of public key is used, so parties without secret key can compute it as well.
+func ComputeKeyID(algo string, key interface{}) (id KeyID, err error) {
+	data, err := MarshalKeyToSlice(key)
+	if err != nil {
+		return
+	}
+
+	h := sha256.New()
+	h.Write([]byte(keyIDDomain))
+
+	var buf []byte
+	buf, _ = ByteVar.AppendToBuf(buf, uint64(len(algo)))
+	buf = append(buf, algo...)
+	h.Write(buf)
+	h.Write(data)
+
+	copy(id[:], h.Sum(nil))
+	return
+}
+
+type keyringEntry[T any] struct {
+	id     KeyID
+	algo   string
+	value  T
+	usable bool // if false, entry can't be primary
+}
+
+// keyring contains logic shared between EncKeyring and SignKeyring.
+type keyring[T any] struct {
+	lock    sync.RWMutex
+	entries []*keyringEntry[T]
+	primary *keyringEntry[T]
+}
+
+func (kr *keyring[T]) find(id KeyID) *keyringEntry[T] {
+	for _, e := range kr.entries {
+		if e.id == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (kr *keyring[T]) add(id KeyID, algo string, value T, usable, makePrimary bool) (err error) {
+	kr.lock.Lock()
+	defer kr.lock.Unlock()
+
+	if kr.find(id) != nil {
+		err = ErrKeyringKeyExists
+		return
+	}
+
+	e := &keyringEntry[T]{
+		id:     id,
+		algo:   algo,
+		value:  value,
+		usable: usable,
+	}
+	kr.entries = append(kr.entries, e)
+	if makePrimary {
+		kr.primary = e
+	}
+	return
+}
+
+func (kr *keyring[T]) setPrimary(id KeyID) (err error) {
+	kr.lock.Lock()
+	defer kr.lock.Unlock()
+
+	e := kr.find(id)
+	if e == nil {
+		err = ErrKeyringKeyNotFound
+		return
+	}
+	if !e.usable {
+		err = ErrKeyringKeyNotUsable
+		return
+	}
+
+	kr.primary = e
+	return
+}
+
+func (kr *keyring[T]) remove(id KeyID) (err error) {
+	kr.lock.Lock()
+	defer kr.lock.Unlock()
+
+	for i, e := range kr.entries {
+		if e.id != id {
+			continue
+		}
+		if e == kr.primary {
+			err = ErrKeyringKeyNotUsable
+			return
+		}
+
+		kr.entries = append(kr.entries[:i], kr.entries[i+1:]...)
+		return
+	}
+
+	err = ErrKeyringKeyNotFound
+	return
+}
+
+func (kr *keyring[T]) getPrimary() (e keyringEntry[T], err error) {
+	kr.lock.RLock()
+	defer kr.lock.RUnlock()
+
+	if kr.primary == nil {
+		err = ErrKeyringNoPrimaryKey
+		return
+	}
+
+	e = *kr.primary
+	return
+}
+
+// getByID returns entry with given ID.
+// Since add rejects repeated IDs, there is at most one of them.
+func (kr *keyring[T]) getByID(id KeyID) (e keyringEntry[T], err error) {
+	kr.lock.RLock()
+	defer kr.lock.RUnlock()
+
+	found := kr.find(id)
+	if found == nil {
+		err = ErrKeyringKeyNotFound
+		return
+	}
+
+	e = *found
+	return
+}
+
+func (kr *keyring[T]) primaryID() (id KeyID, ok bool) {
+	kr.lock.RLock()
+	defer kr.lock.RUnlock()
+
+	if kr.primary == nil {
+		return
+	}
+	return kr.primary.id, true
+}
+
+func (kr *keyring[T]) ids() (res []KeyID) {
+	kr.lock.RLock()
+	defer kr.lock.RUnlock()
+
+	for _, e := range kr.entries {
+		res = append(res, e.id)
+	}
+	return
+}
+
+func keyringSplitID(data []byte) (id KeyID, rest []byte, err error) {
+	if len(data) < KeyIDLength {
+		err = ErrKeyringKeyNotFound
+		return
+	}
+
+	copy(id[:], data)
+	rest = data[KeyIDLength:]
+	return
+}
+
+// isRandomizedEncKey returns true if key yields randomized encryptors, so it may be used to encrypt many messages.
+func isRandomizedEncKey(key EncKey) bool {
+	enc, err := key.MakeEncryptor(nil)
+	return err == nil && enc.GetEncInfo().IsRandomized
+}
+
+// EncKeyring holds multiple symmetric encryption keys.
+// New data is always encrypted using primary key, and old keys are kept, so data encrypted with them can still be decrypted.
+//
+// Each message is encrypted with new encryptor, so only keys yielding randomized encryptors(see EncInfo.IsRandomized),
+// like AEAD ones with RNG nonce, can be primary. Other keys can be added for decryption only.
+//
+// Each ciphertext is prefixed with ID of key, which was used to encrypt it.
+//
+// It's safe to use concurrently. It must not be copied after first use.
+type EncKeyring struct {
+	// Registry used to check if algorithms of keys are deprecated. GlobalRegistry is used if nil.
+	Registry Registry
+
+	// Optional, called each time data is decrypted with key of deprecated algorithm.
+	OnDeprecatedUse func(usage DeprecatedUsage)
+
+	inner keyring[EncSymmKey]
+}
+
+// AddKey adds key, which can be used for decryption.
+// It won't be used for encryption until it's made primary.
+func (kr *EncKeyring) AddKey(algo string, key EncSymmKey) (id KeyID, err error) {
+	id, err = ComputeKeyID(algo, key)
+	if err != nil {
+		return
+	}
+
+	err = kr.inner.add(id, algo, key, isRandomizedEncKey(key), false)
+	return
+}
+
+// Rotate adds key and makes it primary one.
+// Previous primary key is still used for decryption.
+//
+// It returns ErrEncNotRandomized if key does not yield randomized encryptors.
+func (kr *EncKeyring) Rotate(algo string, key EncSymmKey) (id KeyID, err error) {
+	if !isRandomizedEncKey(key) {
+		err = ErrEncNotRandomized
+		return
+	}
+
+	id, err = ComputeKeyID(algo, key)
+	if err != nil {
+		return
+	}
+
+	err = kr.inner.add(id, algo, key, true, true)
+	return
+}
+
+func (kr *EncKeyring) SetPrimary(id KeyID) (err error) {
+	return kr.inner.setPrimary(id)
+}
+
+// RemoveKey removes key, so data encrypted with it can't be decrypted anymore.
+// Primary key can't be removed.
+func (kr *EncKeyring) RemoveKey(id KeyID) (err error) {
+	return kr.inner.remove(id)
+}
+
+func (kr *EncKeyring) PrimaryID() (id KeyID, ok bool) {
+	return kr.inner.primaryID()
+}
+
+func (kr *EncKeyring) KeyIDs() []KeyID {
+	return kr.inner.ids()
+}
+
+// Encrypt encrypts plaintext with primary key.
+// Whole plaintext is encrypted as single chunk followed by finalization.
+func (kr *EncKeyring) Encrypt(ctx KeyContext, plaintext, appendTo []byte) (res []byte, err error) {
+	primary, err := kr.inner.getPrimary()
+	if err != nil {
+		return
+	}
+
+	enc, err := primary.value.MakeEncryptor(ctx)
+	if err != nil {
+		return
+	}
+	if !enc.GetEncInfo().IsRandomized {
+		err = ErrEncNotRandomized
+		return
+	}
+
+	res = append(appendTo, primary.id[:]...)
+	res, err = enc.Encrypt(plaintext, res)
+	if err != nil {
+		return
+	}
+
+	res, err = enc.Finalize(res)
+	return
+}
+
+// Decrypt decrypts ciphertext created with Encrypt using key with ID found in it.
+func (kr *EncKeyring) Decrypt(ctx KeyContext, ciphertext, appendTo []byte) (res []byte, err error) {
+	res = appendTo
+
+	id, rest, err := keyringSplitID(ciphertext)
+	if err != nil {
+		return
+	}
+
+	e, err := kr.inner.getByID(id)
+	if err != nil {
+		return
+	}
+
+	res, err = kr.decryptWithKey(ctx, e.value, rest, appendTo)
+	if err != nil {
+		return
+	}
+
+	reportDeprecatedUsage(kr.Registry, kr.OnDeprecatedUse, e.algo, MakeDecryptorPolicyOperation, len(rest))
+	return
+}
+
+// Reencrypt decrypts ciphertext created with Encrypt and encrypts it again with primary key.
+// If ciphertext was already encrypted with primary key, it's appended to appendTo unchanged.
+func (kr *EncKeyring) Reencrypt(ctx KeyContext, ciphertext, appendTo []byte) (res []byte, err error) {
+	res = appendTo
+
+	id, _, err := keyringSplitID(ciphertext)
+	if err != nil {
+		return
+	}
+
+	if primaryID, ok := kr.PrimaryID(); ok && primaryID == id {
+		res = append(res, ciphertext...)
+		return
+	}
+
+	plaintext, err := kr.Decrypt(ctx, ciphertext, nil)
+	if err != nil {
+		return
+	}
+	defer zeroBytes(plaintext)
+
+	return kr.Encrypt(ctx, plaintext, appendTo)
+}
+
+func (kr *EncKeyring) decryptWithKey(ctx KeyContext, key EncSymmKey, ciphertext, appendTo []byte) (res []byte, err error) {
+	res = appendTo
+
+	dec, err := key.MakeDecryptor(ctx)
+	if err != nil {
+		return
+	}
+
+	res, err = dec.Decrypt(ciphertext, res)
+	if err != nil {
+		res = appendTo
+		return
+	}
+
+	err = dec.Finalize()
+	if err != nil {
+		res = appendTo
+		return
+	}
+	return
+}
+
+type signKeyringKeys struct {
+	signingKey   SigningKey
+	verifyingKey VerifyingKey
+}
+
+// SignKeyring holds multiple signing keys.
+// New data is always signed using primary key and signatures made with any key in keyring can be verified.
+//
+// Each signature is prefixed with ID of key, which was used to create it.
+// ID is derived from verifying key, so keyring with verifying keys only is able to verify signatures.
+//
+// It's safe to use concurrently. It must not be copied after first use.
+type SignKeyring struct {
+	// Registry used to check if algorithms of keys are deprecated. GlobalRegistry is used if nil.
+	Registry Registry
+
+	// Optional, called each time signature made with key of deprecated algorithm is verified.
+	OnDeprecatedUse func(usage DeprecatedUsage)
+
+	inner keyring[signKeyringKeys]
+}
+
+// AddKey adds key pair to keyring. Signing key may be nil, in which case key is used for verification only.
+// Key won't be used for signing until it's made primary.
+//
+// For symmetric algorithms, same key should be passed as both signing and verifying key.
+func (kr *SignKeyring) AddKey(algo string, sk SigningKey, vk VerifyingKey) (id KeyID, err error) {
+	id, err = ComputeKeyID(algo, vk)
+	if err != nil {
+		return
+	}
+
+	err = kr.inner.add(id, algo, signKeyringKeys{
+		signingKey:   sk,
+		verifyingKey: vk,
+	}, sk != nil, false)
+	return
+}
+
+// Rotate adds key pair and makes it primary one.
+// Previous primary key is still used for verification.
+func (kr *SignKeyring) Rotate(algo string, sk SigningKey, vk VerifyingKey) (id KeyID, err error) {
+	if sk == nil {
+		err = ErrKeyringKeyNotUsable
+		return
+	}
+
+	id, err = ComputeKeyID(algo, vk)
+	if err != nil {
+		return
+	}
+
+	err = kr.inner.add(id, algo, signKeyringKeys{
+		signingKey:   sk,
+		verifyingKey: vk,
+	}, true, true)
+	return
+}
+
+func (kr *SignKeyring) SetPrimary(id KeyID) (err error) {
+	return kr.inner.setPrimary(id)
+}
+
+// RemoveKey removes key, so signatures made with it won't be valid anymore.
+// Primary key can't be removed.
+func (kr *SignKeyring) RemoveKey(id KeyID) (err error) {
+	return kr.inner.remove(id)
+}
+
+func (kr *SignKeyring) PrimaryID() (id KeyID, ok bool) {
+	return kr.inner.primaryID()
+}
+
+func (kr *SignKeyring) KeyIDs() []KeyID {
+	return kr.inner.ids()
+}
+
+// Sign signs data with primary key.
+func (kr *SignKeyring) Sign(ctx KeyContext, data, appendTo []byte) (res []byte, err error) {
+	primary, err := kr.inner.getPrimary()
+	if err != nil {
+		return
+	}
+
+	signer, err := primary.value.signingKey.MakeSigner(ctx)
+	if err != nil {
+		return
+	}
+
+	_, err = signer.Write(data)
+	if err != nil {
+		return
+	}
+
+	res = append(appendTo, primary.id[:]...)
+	res, err = signer.Finalize(res)
+	return
+}
+
+// Verify verifies signature made with Sign using key with ID found in it.
+func (kr *SignKeyring) Verify(ctx KeyContext, data, sign []byte) (err error) {
+	id, rest, err := keyringSplitID(sign)
+	if err != nil {
+		return
+	}
+
+	e, err := kr.inner.getByID(id)
+	if err != nil {
+		return
+	}
+
+	verifier, err := e.value.verifyingKey.MakeVerifier(ctx)
+	if err != nil {
+		return
+	}
+
+	_, err = verifier.Write(data)
+	if err != nil {
+		return
+	}
+
+	err = verifier.Verify(rest)
+	if err != nil {
+		return
+	}
+
+	reportDeprecatedUsage(kr.Registry, kr.OnDeprecatedUse, e.algo, MakeVerifierPolicyOperation, len(data))
+	return
+}
@@ -0,0 +1,290 @@
+package crypka_test
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+
+	// Required, so hash is available
+	_ "crypto/sha256"
+)
+
+func TestKeyring_Enc(t *testing.T) {
+	reg := crypka.NewRegistry()
+	crypka.RegisterAES128GCM(reg)
+
+	var algo crypka.EncSymmAlgo
+	err := reg.GetAlgorithmTyped("aes-128-gcm-rng", &algo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	generate := func() crypka.EncSymmKey {
+		key, err := algo.GenerateKey(nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+
+	kr := &crypka.EncKeyring{}
+	plaintext := []byte("secret message")
+
+	_, err = kr.Encrypt(nil, plaintext, nil)
+	if !errors.Is(err, crypka.ErrKeyringNoPrimaryKey) {
+		t.Error("expected no primary key error, got", err)
+		return
+	}
+
+	oldKey := generate()
+	oldID, err := kr.Rotate("aes-128-gcm-rng", oldKey)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// ID identifies single key, so same key can't be added twice
+	_, err = kr.AddKey("aes-128-gcm-rng", oldKey)
+	if !errors.Is(err, crypka.ErrKeyringKeyExists) {
+		t.Error("expected key exists error, got", err)
+		return
+	}
+
+	oldCiphertext, err := kr.Encrypt(nil, plaintext, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.HasPrefix(oldCiphertext, oldID[:]) {
+		t.Error("expected ciphertext to be prefixed with key ID")
+		return
+	}
+
+	newID, err := kr.Rotate("aes-128-gcm-rng", generate())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if primaryID, _ := kr.PrimaryID(); primaryID != newID {
+		t.Error("expected rotated key to be primary")
+		return
+	}
+
+	newCiphertext, err := kr.Encrypt(nil, plaintext, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.HasPrefix(newCiphertext, newID[:]) {
+		t.Error("expected ciphertext to be prefixed with new key ID")
+		return
+	}
+
+	for _, ciphertext := range [][]byte{oldCiphertext, newCiphertext} {
+		res, err := kr.Decrypt(nil, ciphertext, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !bytes.Equal(res, plaintext) {
+			t.Error("invalid plaintext")
+			return
+		}
+	}
+
+	modified := append([]byte{}, newCiphertext...)
+	modified[len(modified)-1] ^= 1
+	_, err = kr.Decrypt(nil, modified, nil)
+	if err == nil {
+		t.Error("expected modified ciphertext to be rejected")
+		return
+	}
+
+	err = kr.RemoveKey(newID)
+	if !errors.Is(err, crypka.ErrKeyringKeyNotUsable) {
+		t.Error("expected primary key removal to fail, got", err)
+		return
+	}
+
+	err = kr.RemoveKey(oldID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = kr.Decrypt(nil, oldCiphertext, nil)
+	if !errors.Is(err, crypka.ErrKeyringKeyNotFound) {
+		t.Error("expected key not found error, got", err)
+		return
+	}
+}
+
+func TestKeyring_Sign(t *testing.T) {
+	reg := crypka.NewRegistry()
+	crypka.RegisterSTLHashes(reg)
+	crypka.RegisterEd25519(reg, crypka.RegisterEd25519Options{})
+
+	var algo crypka.SignAsymAlgo
+	err := reg.GetAlgorithmTyped("ed25519-sha-256", &algo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	oldSK, oldVK, err := algo.GenerateKeyPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	newSK, newVK, err := algo.GenerateKeyPair(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	signer := &crypka.SignKeyring{}
+	verifier := &crypka.SignKeyring{}
+
+	data := []byte("some data")
+
+	_, err = signer.Rotate("ed25519-sha-256", oldSK, oldVK)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	oldSign, err := signer.Sign(nil, data, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err = signer.Rotate("ed25519-sha-256", newSK, newVK)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	newSign, err := signer.Sign(nil, data, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// verifier knows only public keys, but computes same IDs
+	for _, vk := range []crypka.VerifyingKey{oldVK, newVK} {
+		id, err := verifier.AddKey("ed25519-sha-256", nil, vk)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		err = verifier.SetPrimary(id)
+		if !errors.Is(err, crypka.ErrKeyringKeyNotUsable) {
+			t.Error("expected verify only key to be rejected as primary, got", err)
+			return
+		}
+	}
+
+	for _, sign := range [][]byte{oldSign, newSign} {
+		err = verifier.Verify(nil, data, sign)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		err = verifier.Verify(nil, []byte("other data"), sign)
+		if !errors.Is(err, crypka.ErrSignInvalid) {
+			t.Error("expected invalid sign error, got", err)
+			return
+		}
+	}
+
+	_, err = verifier.AddKey("ed25519-sha-256", nil, newVK)
+	if !errors.Is(err, crypka.ErrKeyringKeyExists) {
+		t.Error("expected key exists error, got", err)
+		return
+	}
+}
+
+func TestKeyring_KeyIDDependsOnAlgorithm(t *testing.T) {
+	key, err := (&crypka.HashSignAlgorithm{Hash: crypto.SHA256}).GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	id1, err := crypka.ComputeKeyID("sha-256", key)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	id2, err := crypka.ComputeKeyID("sha-512", key)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if id1 == id2 {
+		t.Error("expected IDs to differ")
+	}
+}
+
+func TestKeyring_EncIsRandomized(t *testing.T) {
+	reg := makeKeyJSONRegistry()
+
+	generate := func(name string) crypka.EncSymmKey {
+		var algo crypka.EncSymmAlgo
+		err := reg.GetAlgorithmTyped(name, &algo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := algo.GenerateKey(nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+
+	kr := &crypka.EncKeyring{}
+	for _, name := range []string{"aes-128-gcm-counter", "cpk-stream-aes-128-gcm-counter"} {
+		_, err := kr.Rotate(name, generate(name))
+		if !errors.Is(err, crypka.ErrEncNotRandomized) {
+			t.Error("expected counter nonce key to be rejected as primary, got", name, err)
+			return
+		}
+
+		id, err := kr.AddKey(name, generate(name))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = kr.SetPrimary(id)
+		if !errors.Is(err, crypka.ErrKeyringKeyNotUsable) {
+			t.Error("expected counter nonce key to be rejected as primary, got", name, err)
+			return
+		}
+	}
+
+	_, err := kr.Rotate("aes-128-gcm-rng", generate("aes-128-gcm-rng"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	plaintext := []byte("same message")
+	lhs, err := kr.Encrypt(nil, plaintext, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	rhs, err := kr.Encrypt(nil, plaintext, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if bytes.Equal(lhs, rhs) {
+		t.Error("expected encryptions of same plaintext to differ")
+		return
+	}
+}
@@ -0,0 +1,112 @@
+package crypka
+
+// DeprecatedUsage describes usage of deprecated algorithm.
+// It's reported to hooks, so it's possible to track how much data still has to be migrated.
+type DeprecatedUsage struct {
+	// Name of deprecated algorithm and its successor, if any.
+	Algorithm string
+	Successor string
+
+	Operation PolicyOperation
+
+	// Amount of data processed using deprecated algorithm.
+	Bytes int
+}
+
+// reportDeprecatedUsage calls hook if algorithm with given name is deprecated in registry.
+func reportDeprecatedUsage(reg Registry, hook func(usage DeprecatedUsage), algo string, op PolicyOperation, bytes int) {
+	if hook == nil {
+		return
+	}
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	info, err := reg.AlgorithmInfo(algo)
+	if err != nil || !info.Deprecated {
+		return
+	}
+
+	hook(DeprecatedUsage{
+		Algorithm: algo,
+		Successor: info.Successor,
+		Operation: op,
+		Bytes:     bytes,
+	})
+}
+
+// Reencrypt decrypts ciphertext with old key and encrypts it with new one.
+// It's meant for bulk migration jobs, which move data from deprecated algorithm to its successor.
+//
+// Ciphertext is decrypted as single chunk followed by finalization and plaintext is encrypted the same way,
+// so it works with ciphertexts created that way or with stream algorithms.
+//
+// Each call creates new encryptor, so new key must yield randomized encryptors(see EncInfo.IsRandomized).
+// Otherwise ErrEncNotRandomized is returned, since all ciphertexts would be encrypted with same nonces.
+func Reencrypt(ctx KeyContext, oldKey DecKey, newKey EncKey, ciphertext, appendTo []byte) (res []byte, err error) {
+	res = appendTo
+
+	dec, err := oldKey.MakeDecryptor(ctx)
+	if err != nil {
+		return
+	}
+
+	plaintext, err := dec.Decrypt(ciphertext, nil)
+	if err != nil {
+		return
+	}
+	defer zeroBytes(plaintext)
+
+	err = dec.Finalize()
+	if err != nil {
+		return
+	}
+
+	enc, err := newKey.MakeEncryptor(ctx)
+	if err != nil {
+		return
+	}
+	if !enc.GetEncInfo().IsRandomized {
+		err = ErrEncNotRandomized
+		return
+	}
+
+	res, err = enc.Encrypt(plaintext, res)
+	if err != nil {
+		res = appendTo
+		return
+	}
+
+	res, err = enc.Finalize(res)
+	if err != nil {
+		res = appendTo
+		return
+	}
+	return
+}
+
+// Reencrypter reencrypts data from old key to new one and reports amount of data,
+// which was encrypted with deprecated algorithm.
+type Reencrypter struct {
+	OldKey DecKey
+	NewKey EncKey
+
+	// Name of algorithm of OldKey. Used to check if it's deprecated.
+	OldAlgo string
+
+	// Registry used to check if OldAlgo is deprecated. GlobalRegistry is used if nil.
+	Registry Registry
+
+	// Optional, called after each successful reencryption if OldAlgo is deprecated.
+	OnDeprecatedUse func(usage DeprecatedUsage)
+}
+
+func (r *Reencrypter) Reencrypt(ctx KeyContext, ciphertext, appendTo []byte) (res []byte, err error) {
+	res, err = Reencrypt(ctx, r.OldKey, r.NewKey, ciphertext, appendTo)
+	if err != nil {
+		return
+	}
+
+	reportDeprecatedUsage(r.Registry, r.OnDeprecatedUse, r.OldAlgo, MakeDecryptorPolicyOperation, len(ciphertext))
+	return
+}
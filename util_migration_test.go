@@ -0,0 +1,309 @@
+package crypka_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+func makeMigrationRegistry() crypka.Registry {
+	reg := crypka.NewRegistry()
+	crypka.RegisterDefaults(reg)
+	reg.DeprecateAlgo("aes-128-gcm-rng", "aes-256-gcm-rng")
+	return reg
+}
+
+func TestRegistry_Deprecation(t *testing.T) {
+	reg := makeMigrationRegistry()
+
+	info, err := reg.AlgorithmInfo("aes-128-gcm-rng")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !info.Deprecated || info.Successor != "aes-256-gcm-rng" {
+		t.Error("expected algorithm to be deprecated", info)
+		return
+	}
+
+	info, err = reg.AlgorithmInfo("aes-256-gcm-rng")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if info.Deprecated {
+		t.Error("expected algorithm not to be deprecated")
+		return
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic for missing successor")
+			}
+		}()
+		reg.DeprecateAlgo("aes-128-gcm-counter", "no-such-algo")
+	}()
+}
+
+func TestPolicy_RejectDeprecated(t *testing.T) {
+	reg := makeMigrationRegistry()
+
+	var violations []crypka.PolicyViolation
+	ctx := &crypka.Context{
+		Policy: &crypka.Policy{
+			RejectDeprecated: true,
+			Registry:         reg,
+			OnViolation: func(violation crypka.PolicyViolation) {
+				violations = append(violations, violation)
+			},
+		},
+	}
+
+	oldAlgo, err := crypka.GetTyped[crypka.EncSymmAlgo](reg, "aes-128-gcm-rng")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err = oldAlgo.GenerateKey(ctx, nil)
+	if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+		t.Error("expected policy error, got", err)
+		return
+	}
+
+	// existing keys can be loaded and used for decryption
+	key, err := oldAlgo.ParseSymmEncKey(ctx, make([]byte, 16))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = key.MakeDecryptor(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = key.MakeEncryptor(ctx)
+	if !errors.Is(err, crypka.ErrAlgorithmDisallowedByPolicy) {
+		t.Error("expected policy error, got", err)
+		return
+	}
+
+	if len(violations) != 2 {
+		t.Error("invalid violation count", len(violations))
+		return
+	}
+	for _, v := range violations {
+		if v.Reason != crypka.DeprecatedAlgorithmPolicyViolation || v.Info.Successor != "aes-256-gcm-rng" {
+			t.Error("invalid violation", v)
+			return
+		}
+	}
+}
+
+func TestKeyring_Migration(t *testing.T) {
+	reg := makeMigrationRegistry()
+
+	oldAlgo, err := crypka.GetTyped[crypka.EncSymmAlgo](reg, "aes-128-gcm-rng")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	newAlgo, err := crypka.GetTyped[crypka.EncSymmAlgo](reg, "aes-256-gcm-rng")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	oldKey, err := oldAlgo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	newKey, err := newAlgo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	deprecatedBytes := 0
+	kr := &crypka.EncKeyring{
+		Registry: reg,
+		OnDeprecatedUse: func(usage crypka.DeprecatedUsage) {
+			if usage.Algorithm != "aes-128-gcm-rng" || usage.Successor != "aes-256-gcm-rng" {
+				t.Error("invalid usage", usage)
+			}
+			deprecatedBytes += usage.Bytes
+		},
+	}
+
+	_, err = kr.Rotate("aes-128-gcm-rng", oldKey)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	plaintext := []byte("some data")
+	oldCiphertext, err := kr.Encrypt(nil, plaintext, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	newID, err := kr.Rotate("aes-256-gcm-rng", newKey)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err = kr.Decrypt(nil, oldCiphertext, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if deprecatedBytes == 0 {
+		t.Error("expected deprecated usage to be reported")
+		return
+	}
+
+	migrated, err := kr.Reencrypt(nil, oldCiphertext, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.HasPrefix(migrated, newID[:]) {
+		t.Error("expected data to be encrypted with new key")
+		return
+	}
+
+	again, err := kr.Reencrypt(nil, migrated, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.Equal(again, migrated) {
+		t.Error("expected data encrypted with primary key to be left unchanged")
+		return
+	}
+
+	deprecatedBytes = 0
+	res, err := kr.Decrypt(nil, migrated, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.Equal(res, plaintext) {
+		t.Error("invalid plaintext")
+		return
+	}
+	if deprecatedBytes != 0 {
+		t.Error("expected no deprecated usage for migrated data")
+		return
+	}
+}
+
+func TestReencrypter(t *testing.T) {
+	reg := makeMigrationRegistry()
+
+	oldAlgo, err := crypka.GetTyped[crypka.EncSymmAlgo](reg, "aes-128-gcm-rng")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	newInner, err := crypka.GetTyped[crypka.EncSymmAlgo](reg, "aes-256-gcm-rng")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	newAlgo := &crypka.CPKStreamSymmEncAlgo{EncSymmAlgo: newInner}
+
+	oldKey, err := oldAlgo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	newKey, err := newAlgo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	plaintext := []byte("some data")
+	enc, err := oldKey.MakeEncryptor(nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ciphertext, err := enc.Encrypt(plaintext, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var usages []crypka.DeprecatedUsage
+	r := &crypka.Reencrypter{
+		OldKey:   oldKey,
+		NewKey:   newKey,
+		OldAlgo:  "aes-128-gcm-rng",
+		Registry: reg,
+		OnDeprecatedUse: func(usage crypka.DeprecatedUsage) {
+			usages = append(usages, usage)
+		},
+	}
+
+	migrated, err := r.Reencrypt(nil, ciphertext, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(usages) != 1 || usages[0].Bytes != len(ciphertext) {
+		t.Error("invalid usages reported", usages)
+		return
+	}
+
+	dec, err := newKey.MakeDecryptor(nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	res, err := dec.Decrypt(migrated, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = dec.Finalize()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.Equal(res, plaintext) {
+		t.Error("invalid plaintext")
+		return
+	}
+
+	modified := append([]byte{}, ciphertext...)
+	modified[len(modified)-1] ^= 1
+	_, err = crypka.Reencrypt(nil, oldKey, newKey, modified, nil)
+	if err == nil {
+		t.Error("expected modified ciphertext to be rejected")
+		return
+	}
+
+	counterAlgo, err := crypka.GetTyped[crypka.EncSymmAlgo](reg, "cpk-stream-aes-256-gcm-counter")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	counterKey, err := counterAlgo.GenerateKey(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = crypka.Reencrypt(nil, oldKey, counterKey, ciphertext, nil)
+	if !errors.Is(err, crypka.ErrEncNotRandomized) {
+		t.Error("expected counter nonce key to be rejected, got", err)
+		return
+	}
+}
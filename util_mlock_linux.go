@@ -0,0 +1,66 @@
+//go:build linux
+
+package crypka
+
+import (
+	"os"
+	"sync"
+	"syscall"
+)
+
+// Locked memory is allocated using mmap rather than taken from go's heap, since mlock works on whole pages,
+// and unlocking page shared with other locked buffer would unlock that one as well.
+var lockedMemory = struct {
+	lock    sync.Mutex
+	regions map[*byte][]byte
+}{
+	regions: map[*byte][]byte{},
+}
+
+// allocLockedMemory allocates buffer, which is locked in RAM.
+// It's best effort, so it returns false if it's not possible, for instance because of RLIMIT_MEMLOCK.
+func allocLockedMemory(length int) (buf []byte, ok bool) {
+	if length <= 0 {
+		return
+	}
+
+	pageSize := os.Getpagesize()
+	size := (length + pageSize - 1) / pageSize * pageSize
+
+	region, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return
+	}
+
+	err = syscall.Mlock(region)
+	if err != nil {
+		_ = syscall.Munmap(region)
+		return
+	}
+
+	lockedMemory.lock.Lock()
+	lockedMemory.regions[&region[0]] = region
+	lockedMemory.lock.Unlock()
+
+	return region[:length], true
+}
+
+// freeLockedMemory frees buffer allocated with allocLockedMemory.
+// Does nothing for other buffers.
+func freeLockedMemory(buf []byte) {
+	if len(buf) == 0 {
+		return
+	}
+
+	lockedMemory.lock.Lock()
+	region, ok := lockedMemory.regions[&buf[0]]
+	delete(lockedMemory.regions, &buf[0])
+	lockedMemory.lock.Unlock()
+
+	if !ok {
+		return
+	}
+
+	_ = syscall.Munlock(region)
+	_ = syscall.Munmap(region)
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package crypka
+
+// Memory locking is supported on linux only.
+
+func allocLockedMemory(length int) (buf []byte, ok bool) {
+	return
+}
+
+func freeLockedMemory(buf []byte) {
+}
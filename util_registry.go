@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 	"sync/atomic"
 )
@@ -14,26 +15,108 @@ var ErrInvalidDestinationType = errors.New("crypka: Invalid getter destintation
 
 // Registry for any kind of algorithm.
 type Registry interface {
+	// RegisterAlgo registers algorithm with given name.
+	// Algorithms, which tag their keys with their name, like ed25519 or AEAD ones, get it set, unless it was set already.
 	RegisterAlgo(name string, algo interface{})
 	GetAlgo(name string) (algo interface{})
 	Lock()
 
 	GetAlgorithmTyped(name string, dstAlgo interface{}) (err error)
+
+	// ListAlgorithms returns sorted names of all registered algorithms.
+	ListAlgorithms() []string
+
+	// ListByType returns sorted names of registered algorithms of given type.
+	// Algorithms, which do not provide info, are skipped.
+	ListByType(algorithmType AlgorithmType) []string
+
+	// AlgorithmInfo returns normalized info of algorithm with given name.
+	AlgorithmInfo(name string) (info AlgorithmInfo, err error)
+
+	// DeprecateAlgo marks registered algorithm as deprecated.
+	// Successor is name of algorithm, which should be used instead. It may be empty.
+	//
+	// Deprecated algorithms are still available, so existing data can be decrypted or verified.
+	DeprecateAlgo(name string, successor string)
+
+	// RegisterAlgoOverride registers algorithm, replacing one registered with same name, if any.
+	// Like RegisterAlgo, it panics once registry is locked.
+	RegisterAlgoOverride(name string, algo interface{})
+
+	// HideAlgo makes algorithm with given name unavailable, including one inherited from parent registry.
+	// Like RegisterAlgo, it panics once registry is locked.
+	HideAlgo(name string)
+
+	// Snapshot returns locked copy of registry, which contains all algorithms visible in it, including inherited ones.
+	// Later changes to registry or its parent do not affect snapshot, so it can be safely shared across goroutines.
+	Snapshot() Registry
 }
 
 // defaultRegistry is map of string to interface{}, which contains some features to make it suitable
 // for handling algorithms.
+//
+// It may have parent registry, in which case algorithms, which are not registered in it, are looked up in parent,
+// unless they are hidden.
 type defaultRegistry struct {
 	locked   int32
 	lock     *sync.Mutex
 	contents map[string]interface{}
+
+	// maps name of deprecated algorithm to its successor
+	deprecated map[string]string
+
+	parent Registry
+	hidden map[string]struct{}
+}
+
+// registerDefault registers algorithm, unless there is already one registered with given name.
+// It's used by built-in Register* functions, so calling them on registry, which contains them already,
+// like GlobalRegistry, does nothing instead of panicking.
+func registerDefault(reg Registry, name string, algo interface{}) {
+	if reg.GetAlgo(name) != nil {
+		return
+	}
+	reg.RegisterAlgo(name, algo)
 }
 
 func NewRegistry() Registry {
 	return &defaultRegistry{
-		contents: map[string]interface{}{},
-		lock:     &sync.Mutex{},
+		contents:   map[string]interface{}{},
+		deprecated: map[string]string{},
+		hidden:     map[string]struct{}{},
+		lock:       &sync.Mutex{},
+	}
+}
+
+// NewChildRegistry creates registry, which inherits all algorithms from parent.
+// Algorithms can be added, overridden or hidden in child without affecting parent.
+// If parent is nil, GlobalRegistry is used.
+//
+// Child does not copy parent, so changes made to parent are visible in child, until parent is locked.
+// Use Snapshot of parent in order to prevent that.
+func NewChildRegistry(parent Registry) Registry {
+	if parent == nil {
+		parent = GlobalRegistry
+	}
+
+	reg := NewRegistry().(*defaultRegistry)
+	reg.parent = parent
+	return reg
+}
+
+// lookup returns algorithm visible in registry.
+// Must be called with lock held, unless registry is locked.
+func (reg *defaultRegistry) lookup(name string) (algo interface{}, own bool) {
+	algo, own = reg.contents[name]
+	if own {
+		return
 	}
+
+	if _, hidden := reg.hidden[name]; hidden || reg.parent == nil {
+		return
+	}
+	algo = reg.parent.GetAlgo(name)
+	return
 }
 
 func (reg *defaultRegistry) RegisterAlgo(name string, algo interface{}) {
@@ -43,11 +126,13 @@ func (reg *defaultRegistry) RegisterAlgo(name string, algo interface{}) {
 
 	reg.lock.Lock()
 	defer reg.lock.Unlock()
-	_, ok := reg.contents[name]
-	if ok {
+	existing, _ := reg.lookup(name)
+	if existing != nil {
 		panic(fmt.Errorf("algorithm with name %s is already registered", name))
 	}
 	reg.contents[name] = algo
+	delete(reg.hidden, name)
+	tagAlgoName(name, algo)
 }
 
 func (reg *defaultRegistry) RegisterAlgoOverride(name string, algo interface{}) {
@@ -59,17 +144,35 @@ func (reg *defaultRegistry) RegisterAlgoOverride(name string, algo interface{})
 	defer reg.lock.Unlock()
 
 	reg.contents[name] = algo
+	delete(reg.hidden, name)
+	delete(reg.deprecated, name)
+	tagAlgoName(name, algo)
+}
+
+func (reg *defaultRegistry) HideAlgo(name string) {
+	if atomic.LoadInt32(&reg.locked) != 0 {
+		panic("register already locked")
+	}
+
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+
+	delete(reg.contents, name)
+	delete(reg.deprecated, name)
+	reg.hidden[name] = struct{}{}
 }
 
 func (reg *defaultRegistry) GetAlgo(name string) (algo interface{}) {
 	locked := atomic.LoadInt32(&reg.locked)
 	if locked != 0 {
-		return reg.contents[name]
+		algo, _ = reg.lookup(name)
+		return
 	} else {
 		reg.lock.Lock()
 		defer reg.lock.Unlock()
 
-		return reg.contents[name]
+		algo, _ = reg.lookup(name)
+		return
 	}
 }
 
@@ -79,6 +182,21 @@ func (reg *defaultRegistry) Lock() {
 	reg.lock.Unlock()
 }
 
+func (reg *defaultRegistry) Snapshot() Registry {
+	res := NewRegistry().(*defaultRegistry)
+	for _, name := range reg.ListAlgorithms() {
+		res.contents[name] = reg.GetAlgo(name)
+
+		successor, deprecated := reg.getDeprecation(name)
+		if deprecated {
+			res.deprecated[name] = successor
+		}
+	}
+
+	res.Lock()
+	return res
+}
+
 func (reg *defaultRegistry) GetAlgorithmTyped(name string, dstAlgo interface{}) (err error) {
 	rawAlgo := reg.GetAlgo(name)
 	if rawAlgo == nil {
@@ -100,3 +218,121 @@ func (reg *defaultRegistry) GetAlgorithmTyped(name string, dstAlgo interface{})
 	dstAlgoValue.Elem().Set(reflect.ValueOf(rawAlgo))
 	return
 }
+
+func (reg *defaultRegistry) ListAlgorithms() (names []string) {
+	if atomic.LoadInt32(&reg.locked) == 0 {
+		reg.lock.Lock()
+		defer reg.lock.Unlock()
+	}
+
+	names = make([]string, 0, len(reg.contents))
+	for name := range reg.contents {
+		names = append(names, name)
+	}
+
+	if reg.parent != nil {
+		for _, name := range reg.parent.ListAlgorithms() {
+			_, own := reg.contents[name]
+			_, hidden := reg.hidden[name]
+			if !own && !hidden {
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return
+}
+
+func (reg *defaultRegistry) ListByType(algorithmType AlgorithmType) (names []string) {
+	for _, name := range reg.ListAlgorithms() {
+		info, err := reg.AlgorithmInfo(name)
+		if err != nil || info.Type != algorithmType {
+			continue
+		}
+		names = append(names, name)
+	}
+	return
+}
+
+func (reg *defaultRegistry) AlgorithmInfo(name string) (info AlgorithmInfo, err error) {
+	rawAlgo := reg.GetAlgo(name)
+	if rawAlgo == nil {
+		err = ErrNoSuchAlgorithm
+		return
+	}
+
+	info, err = GetAlgorithmInfo(rawAlgo)
+	if err != nil {
+		return
+	}
+	info.Name = name
+	info.Successor, info.Deprecated = reg.getDeprecation(name)
+	return
+}
+
+func (reg *defaultRegistry) getDeprecation(name string) (successor string, deprecated bool) {
+	if atomic.LoadInt32(&reg.locked) == 0 {
+		reg.lock.Lock()
+		defer reg.lock.Unlock()
+	}
+
+	successor, deprecated = reg.deprecated[name]
+	if deprecated {
+		return
+	}
+
+	if _, own := reg.contents[name]; own {
+		return
+	}
+	if _, hidden := reg.hidden[name]; hidden || reg.parent == nil {
+		return
+	}
+
+	info, err := reg.parent.AlgorithmInfo(name)
+	if err == nil {
+		successor, deprecated = info.Successor, info.Deprecated
+	}
+	return
+}
+
+func (reg *defaultRegistry) DeprecateAlgo(name string, successor string) {
+	if atomic.LoadInt32(&reg.locked) != 0 {
+		panic("register already locked")
+	}
+
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+
+	if algo, _ := reg.lookup(name); algo == nil {
+		panic(fmt.Errorf("algorithm with name %s is not registered", name))
+	}
+	if algo, _ := reg.lookup(successor); len(successor) > 0 && algo == nil {
+		panic(fmt.Errorf("successor algorithm with name %s is not registered", successor))
+	}
+
+	reg.deprecated[name] = successor
+}
+
+// GetTyped returns algorithm with given name from registry, if it has type T.
+// It uses GlobalRegistry if reg is nil.
+//
+// Note: T is usually interface like EncSymmAlgo.
+func GetTyped[T any](reg Registry, name string) (algo T, err error) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	rawAlgo := reg.GetAlgo(name)
+	if rawAlgo == nil {
+		err = ErrNoSuchAlgorithm
+		return
+	}
+
+	algo, ok := rawAlgo.(T)
+	if !ok {
+		err = ErrInvalidAlgorithmType
+		return
+	}
+	return
+}
@@ -1,3 +1,62 @@
 package crypka
 
+import (
+	// Hashes used by default algorithms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+
+	_ "golang.org/x/crypto/sha3"
+)
+
+// GlobalRegistry is registry used when nil registry is passed to functions, which accept it.
+// It contains all algorithms registered by RegisterDefaults.
 var GlobalRegistry = NewRegistry()
+
+func init() {
+	RegisterDefaults(GlobalRegistry)
+}
+
+// Symmetric encryption algorithms, which are wrapped with CPK stream and used for asymmetric encryption by RegisterDefaults.
+var defaultInnerEncAlgorithms = []string{
+	"aes-128-gcm-counter",
+	"aes-256-gcm-counter",
+	"chacha20-poly1305-counter",
+}
+
+// RegisterDefaults registers all built-in algorithms, which are not meant for testing only, in right order.
+// See registry_convention.go for names they are registered with.
+//
+// Like all built-in Register* functions, it skips names, which are already registered, so calling it
+// on GlobalRegistry, which already contains defaults, does nothing.
+// In particular, calling Register* with custom options does not replace defaults; use RegisterAlgoOverride for that.
+func RegisterDefaults(reg Registry) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	RegisterSTLHashes(reg)
+	RegisterSTLHMACs(reg, RegisterSTLHMACsOptions{})
+	RegisterEd25519(reg, RegisterEd25519Options{})
+
+	RegisterAES128GCM(reg)
+	RegisterAES256GCM(reg)
+	RegisterChaCha20Poly1305(reg)
+	RegisterAESKW(reg)
+
+	for _, name := range defaultInnerEncAlgorithms {
+		RegisterCPKStream(reg, name)
+	}
+
+	RegisterX25519(reg)
+	for _, name := range defaultInnerEncAlgorithms {
+		RegisterEncAsymKX(reg, "x25519", name)
+	}
+
+	RegisterSTLRNGs(reg)
+	RegisterStreamCipherRNGs(reg)
+
+	RegisterArgon2(reg)
+	RegisterHashPoW(reg)
+
+	RegisterPaddings(reg)
+}
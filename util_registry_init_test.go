@@ -0,0 +1,264 @@
+package crypka_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+)
+
+func TestRegisterDefaults_Names(t *testing.T) {
+	reg := crypka.NewRegistry()
+	crypka.RegisterDefaults(reg)
+
+	names := reg.ListAlgorithms()
+	if len(names) == 0 {
+		t.Error("expected some algorithms to be registered")
+		return
+	}
+
+	for _, name := range names {
+		if !crypka.IsValidAlgorithmName(name) {
+			t.Error("name does not follow convention:", name)
+		}
+
+		info, err := reg.AlgorithmInfo(name)
+		if err != nil {
+			t.Error("algorithm", name, "has no info:", err)
+			continue
+		}
+		if !info.IsSecure {
+			t.Error("insecure algorithm registered by default:", name)
+		}
+	}
+
+	globalNames := crypka.GlobalRegistry.ListAlgorithms()
+	if len(globalNames) != len(names) {
+		t.Error("expected global registry to contain defaults")
+		return
+	}
+}
+
+func TestIsValidAlgorithmName(t *testing.T) {
+	for _, name := range []string{"sha-256", "aes-128-gcm-counter", "x25519", "a"} {
+		if !crypka.IsValidAlgorithmName(name) {
+			t.Error("expected name to be valid:", name)
+		}
+	}
+	for _, name := range []string{"", "-sha", "sha-", "sha--256", "SHA-256", "sha_256", "1sha", "cpk-stream(aes)"} {
+		if crypka.IsValidAlgorithmName(name) {
+			t.Error("expected name to be invalid:", name)
+		}
+	}
+}
+
+func TestRegisterDefaults_Usable(t *testing.T) {
+	reg := crypka.NewRegistry()
+	crypka.RegisterDefaults(reg)
+
+	// multiple of 8, so it's valid for unpadded key wrapping as well
+	data := bytes.Repeat([]byte("somedata"), 4)
+
+	for _, name := range reg.ListByType(crypka.SymmEncAlgorithmType) {
+		algo, err := crypka.GetTyped[crypka.EncSymmAlgo](reg, name)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+
+		key, err := algo.GenerateKey(nil, nil)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+
+		err = checkEncRoundTrip(key, key, data)
+		if err != nil {
+			t.Error(name, err)
+		}
+	}
+
+	for _, name := range reg.ListByType(crypka.AsymEncAlgorithmType) {
+		algo, err := crypka.GetTyped[crypka.EncAsymAlgo](reg, name)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+
+		ek, dk, err := algo.GenerateKeyPair(nil, nil)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+
+		err = checkEncRoundTrip(ek, dk, data)
+		if err != nil {
+			t.Error(name, err)
+		}
+	}
+
+	for _, name := range reg.ListByType(crypka.AsymSignAlgorithmType) {
+		algo, err := crypka.GetTyped[crypka.SignAsymAlgo](reg, name)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+
+		sk, vk, err := algo.GenerateKeyPair(nil, nil)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+
+		signer, err := sk.MakeSigner(nil)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+		signer.Write(data)
+		sign, err := signer.Finalize(nil)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+
+		verifier, err := vk.MakeVerifier(nil)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+		verifier.Write(data)
+		err = verifier.Verify(sign)
+		if err != nil {
+			t.Error(name, err)
+		}
+	}
+
+	for _, name := range reg.ListByType(crypka.RNGAlgorithmType) {
+		algo, err := crypka.GetTyped[crypka.RNGAlgo](reg, name)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+
+		seed, err := crypka.GenerateReasonableRNGSeed(rand.Reader, algo.GetInfo())
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+
+		rng, err := algo.MakeRng(nil, seed)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+
+		_, err = io.ReadFull(rng, make([]byte, 1024))
+		if err != nil {
+			t.Error(name, err)
+		}
+	}
+}
+
+func TestRegisterDefaults_Paddings(t *testing.T) {
+	reg := crypka.NewRegistry()
+	crypka.RegisterDefaults(reg)
+
+	names := reg.ListByType(crypka.PaddingAlgorithmType)
+	if len(names) != 4 {
+		t.Error("expected 4 paddings to be registered, got", names)
+	}
+
+	for _, name := range names {
+		algo, err := crypka.GetTyped[crypka.PaddingAlgo](reg, name)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+
+		padded, err := algo.PadMessage(nil, []byte("some message"), nil)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+		msg, err := algo.UnpadMessage(padded)
+		if err != nil || string(msg) != "some message" {
+			t.Error(name, "invalid unpadded message", err)
+		}
+	}
+}
+
+func checkEncRoundTrip(ek crypka.EncKey, dk crypka.DecKey, data []byte) (err error) {
+	enc, err := ek.MakeEncryptor(nil)
+	if err != nil {
+		return
+	}
+
+	ciphertext, err := enc.Encrypt(data, nil)
+	if err != nil {
+		return
+	}
+	ciphertext, err = enc.Finalize(ciphertext)
+	if err != nil {
+		return
+	}
+
+	dec, err := dk.MakeDecryptor(nil)
+	if err != nil {
+		return
+	}
+
+	plaintext, err := dec.Decrypt(ciphertext, nil)
+	if err != nil {
+		return
+	}
+	err = dec.Finalize()
+	if err != nil {
+		return
+	}
+
+	if !bytes.Equal(plaintext, data) {
+		err = io.ErrUnexpectedEOF
+	}
+	return
+}
+
+func TestRegisterEd25519_PanicsWhenCompressorMissing(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+
+	reg := crypka.NewRegistry()
+	crypka.RegisterEd25519(reg, crypka.RegisterEd25519Options{})
+}
+
+// Programs written before defaults were registered in GlobalRegistry call Register* functions on it themselves.
+func TestRegisterDefaults_Idempotent(t *testing.T) {
+	before := crypka.GlobalRegistry.ListAlgorithms()
+
+	crypka.RegisterSTLHashes(nil)
+	crypka.RegisterSTLHMACs(nil, crypka.RegisterSTLHMACsOptions{})
+	crypka.RegisterEd25519(nil, crypka.RegisterEd25519Options{})
+	crypka.RegisterX25519(nil)
+	crypka.RegisterAES256GCM(nil)
+	crypka.RegisterCPKStream(nil, "aes-256-gcm-counter")
+	crypka.RegisterEncAsymKX(nil, "x25519", "aes-256-gcm-counter")
+	crypka.RegisterDefaults(nil)
+
+	after := crypka.GlobalRegistry.ListAlgorithms()
+	if len(before) != len(after) {
+		t.Error("expected registering defaults again to do nothing")
+		return
+	}
+
+	reg := crypka.NewRegistry()
+	crypka.RegisterDefaults(reg)
+	crypka.RegisterDefaults(reg)
+	if len(reg.ListAlgorithms()) != len(after) {
+		t.Error("expected registering defaults twice to do nothing")
+	}
+}
@@ -1,7 +1,10 @@
 package crypka_test
 
 import (
+	"errors"
 	"io"
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/teawithsand/crypka"
@@ -49,3 +52,194 @@ func TestRegistry_GetAlgorithmTyped_ErrorWhenInvalidType(t *testing.T) {
 		return
 	}
 }
+
+func TestRegistry_ListAndInfo(t *testing.T) {
+	registry := crypka.NewRegistry()
+	crypka.RegisterAES128GCM(registry)
+	crypka.RegisterX25519(registry)
+	registry.RegisterAlgo("argon2", &crypka.Argon2PasswordHasher{})
+	registry.RegisterAlgo("xor", &crypka.XorEncSymmAlgo{})
+	registry.RegisterAlgo("no-info", &algo2{})
+
+	names := registry.ListAlgorithms()
+	if !sort.StringsAreSorted(names) {
+		t.Error("expected names to be sorted")
+		return
+	}
+	if len(names) != 6 {
+		t.Error("invalid algorithm count", names)
+		return
+	}
+
+	symmEnc := registry.ListByType(crypka.SymmEncAlgorithmType)
+	if !reflect.DeepEqual(symmEnc, []string{"aes-128-gcm-counter", "aes-128-gcm-rng", "xor"}) {
+		t.Error("invalid symmetric encryption algorithms", symmEnc)
+		return
+	}
+
+	phash := registry.ListByType(crypka.PHashAlgorithmType)
+	if !reflect.DeepEqual(phash, []string{"argon2"}) {
+		t.Error("invalid password hashing algorithms", phash)
+		return
+	}
+
+	info, err := registry.AlgorithmInfo("xor")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if info.Name != "xor" || info.IsSecure || info.Type != crypka.SymmEncAlgorithmType {
+		t.Error("invalid info", info)
+		return
+	}
+	if _, ok := info.Details.(crypka.EncAlgoInfo); !ok {
+		t.Error("expected details to be EncAlgoInfo")
+		return
+	}
+
+	info, err = registry.AlgorithmInfo("x25519")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !info.IsSecure || info.Type != crypka.KXAlgorithmType {
+		t.Error("invalid info", info)
+		return
+	}
+
+	_, err = registry.AlgorithmInfo("no-info")
+	if !errors.Is(err, crypka.ErrInvalidAlgorithmType) {
+		t.Error("expected invalid algorithm type error, got", err)
+		return
+	}
+
+	_, err = registry.AlgorithmInfo("missing")
+	if !errors.Is(err, crypka.ErrNoSuchAlgorithm) {
+		t.Error("expected no such algorithm error, got", err)
+		return
+	}
+}
+
+func TestRegistry_GetTyped(t *testing.T) {
+	registry := InitTestRegistry()
+
+	res, err := crypka.GetTyped[io.Reader](registry, "a1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_ = res.(*algo1)
+
+	_, err = crypka.GetTyped[io.Writer](registry, "a1")
+	if !errors.Is(err, crypka.ErrInvalidAlgorithmType) {
+		t.Error("expected invalid algorithm type error, got", err)
+		return
+	}
+
+	_, err = crypka.GetTyped[io.Reader](registry, "missing")
+	if !errors.Is(err, crypka.ErrNoSuchAlgorithm) {
+		t.Error("expected no such algorithm error, got", err)
+		return
+	}
+}
+
+func TestRegistry_Child(t *testing.T) {
+	parent := InitTestRegistry()
+	parent.RegisterAlgo("a3", &algo2{})
+	parent.DeprecateAlgo("a3", "a2")
+	parent.Lock()
+
+	child := crypka.NewChildRegistry(parent)
+	child.RegisterAlgo("c1", &algo2{})
+	child.RegisterAlgoOverride("a2", &algo1{})
+	child.HideAlgo("a1")
+
+	if _, ok := child.GetAlgo("a2").(*algo1); !ok {
+		t.Error("expected overridden algorithm")
+		return
+	}
+	if _, ok := parent.GetAlgo("a2").(*algo2); !ok {
+		t.Error("expected parent to be unaffected by override")
+		return
+	}
+	if child.GetAlgo("a1") != nil {
+		t.Error("expected hidden algorithm to be unavailable")
+		return
+	}
+	if parent.GetAlgo("a1") == nil {
+		t.Error("expected parent to be unaffected by hiding")
+		return
+	}
+
+	names := child.ListAlgorithms()
+	if !reflect.DeepEqual(names, []string{"a2", "a3", "c1"}) {
+		t.Error("invalid algorithms", names)
+		return
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic when registering inherited name")
+			}
+		}()
+		child.RegisterAlgo("a3", &algo2{})
+	}()
+
+	child.Lock()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic when overriding in locked registry")
+			}
+		}()
+		child.RegisterAlgoOverride("c1", &algo1{})
+	}()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic when hiding in locked registry")
+			}
+		}()
+		child.HideAlgo("c1")
+	}()
+}
+
+func TestRegistry_Snapshot(t *testing.T) {
+	parent := crypka.NewRegistry()
+	crypka.RegisterAES128GCM(parent)
+	parent.DeprecateAlgo("aes-128-gcm-rng", "aes-128-gcm-counter")
+
+	child := crypka.NewChildRegistry(parent)
+	child.RegisterAlgo("a1", &algo1{})
+
+	snapshot := child.Snapshot()
+
+	parent.RegisterAlgo("late", &algo2{})
+	child.HideAlgo("a1")
+
+	names := snapshot.ListAlgorithms()
+	if !reflect.DeepEqual(names, []string{"a1", "aes-128-gcm-counter", "aes-128-gcm-rng"}) {
+		t.Error("invalid snapshot algorithms", names)
+		return
+	}
+
+	info, err := snapshot.AlgorithmInfo("aes-128-gcm-rng")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !info.Deprecated || info.Successor != "aes-128-gcm-counter" {
+		t.Error("expected deprecation to be preserved in snapshot", info)
+		return
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected snapshot to be locked")
+			}
+		}()
+		snapshot.RegisterAlgo("a2", &algo2{})
+	}()
+}
@@ -1,9 +1,161 @@
 package crypka
 
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+)
+
 type SerializedKeyType uint8
 
+const (
+	PublicSerializedKeyType SerializedKeyType = 1
+	SecretSerializedKeyType SerializedKeyType = 2 // both symmetric and asymmetric secret keys
+)
+
+func (ty SerializedKeyType) String() string {
+	switch ty {
+	case PublicSerializedKeyType:
+		return "public"
+	case SecretSerializedKeyType:
+		return "secret"
+	default:
+		return ""
+	}
+}
+
+func parseSerializedKeyType(name string) (ty SerializedKeyType, err error) {
+	switch name {
+	case "public":
+		ty = PublicSerializedKeyType
+	case "secret":
+		ty = SecretSerializedKeyType
+	default:
+		err = ErrKeyParseField
+	}
+	return
+}
+
+// SerializedKey is marshaled key tagged with name of algorithm, which it belongs to.
+//
+// It's text form is "<algo>:<type>:<base64url of data>".
+// It's JSON form is object with "algo", "type" and "key" fields, where "key" is base64 encoded data.
 type SerializedKey struct {
 	Algo string
 	Type SerializedKeyType
 	Data []byte
 }
+
+type serializedKeyJSON struct {
+	Algo string `json:"algo"`
+	Type string `json:"type"`
+	Key  []byte `json:"key"`
+}
+
+func (sk SerializedKey) MarshalText() (res []byte, err error) {
+	if len(sk.Algo) == 0 || bytes.ContainsRune([]byte(sk.Algo), ':') || len(sk.Type.String()) == 0 {
+		err = ErrKeyNotMarshalable
+		return
+	}
+
+	res = append(res, sk.Algo...)
+	res = append(res, ':')
+	res = append(res, sk.Type.String()...)
+	res = append(res, ':')
+	res = append(res, base64.RawURLEncoding.EncodeToString(sk.Data)...)
+	return
+}
+
+func (sk *SerializedKey) UnmarshalText(text []byte) (err error) {
+	parts := bytes.SplitN(text, []byte{':'}, 3)
+	if len(parts) != 3 || len(parts[0]) == 0 {
+		err = ErrKeyParseField
+		return
+	}
+
+	ty, err := parseSerializedKeyType(string(parts[1]))
+	if err != nil {
+		return
+	}
+
+	data, err := base64.RawURLEncoding.Strict().DecodeString(string(parts[2]))
+	if err != nil {
+		err = ErrKeyParseField
+		return
+	}
+
+	*sk = SerializedKey{
+		Algo: string(parts[0]),
+		Type: ty,
+		Data: data,
+	}
+	return
+}
+
+func (sk SerializedKey) MarshalJSON() (res []byte, err error) {
+	if len(sk.Algo) == 0 || len(sk.Type.String()) == 0 {
+		err = ErrKeyNotMarshalable
+		return
+	}
+
+	return json.Marshal(serializedKeyJSON{
+		Algo: sk.Algo,
+		Type: sk.Type.String(),
+		Key:  sk.Data,
+	})
+}
+
+func (sk *SerializedKey) UnmarshalJSON(data []byte) (err error) {
+	var raw serializedKeyJSON
+	err = json.Unmarshal(data, &raw)
+	if err != nil || len(raw.Algo) == 0 {
+		err = ErrKeyParseField
+		return
+	}
+
+	ty, err := parseSerializedKeyType(raw.Type)
+	if err != nil {
+		return
+	}
+
+	*sk = SerializedKey{
+		Algo: raw.Algo,
+		Type: ty,
+		Data: raw.Key,
+	}
+	return
+}
+
+// ParseSerializedKey parses key using algorithm with name stored in it.
+// Name may also be specification of algorithm, as accepted by BuildAlgorithm.
+// If registry is nil, GlobalRegistry is used.
+//
+// Key returned is one, which would be returned by algorithm's parse method, like SigningKey or KXPublic.
+func ParseSerializedKey(ctx KeyParseContext, reg Registry, sk SerializedKey) (key interface{}, err error) {
+	if reg == nil {
+		reg = GlobalRegistry
+	}
+
+	algo := resolveAlgorithm(reg, sk.Algo)
+	if algo == nil {
+		err = ErrNoSuchAlgorithm
+		return
+	}
+
+	switch sk.Type {
+	case PublicSerializedKeyType:
+		switch typedAlgo := algo.(type) {
+		case SignAsymKeyParser:
+			return typedAlgo.ParseVerifyingKey(ctx, sk.Data)
+		case KXParser:
+			return typedAlgo.ParseKXPublic(ctx, sk.Data)
+		case EncAsymKeyParser:
+			return typedAlgo.ParseEncKey(ctx, sk.Data)
+		}
+	case SecretSerializedKeyType:
+		return parseSecretKey(ctx, algo, sk.Data)
+	}
+
+	err = ErrInvalidAlgorithmType
+	return
+}
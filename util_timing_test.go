@@ -0,0 +1,55 @@
+package crypka_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"io"
+	"testing"
+
+	"github.com/teawithsand/crypka"
+	"github.com/teawithsand/crypka/crypkatest"
+)
+
+// makeMismatchTimingTester prepares timing test of comparison of secret against data, which never matches it.
+// Class 0 differs from secret in last byte only, class 1 is random, so early exit makes class 1 faster.
+func makeMismatchTimingTester(secret []byte, run func(input []byte)) *crypkatest.TimingTester {
+	return &crypkatest.TimingTester{
+		Prepare: func(class int, rng crypka.RNG) (input []byte, err error) {
+			input = append([]byte{}, secret...)
+			if class == 1 {
+				_, err = io.ReadFull(rng, input)
+				if err != nil {
+					return
+				}
+			}
+			input[len(input)-1] ^= 1
+			return
+		},
+		Run: run,
+	}
+}
+
+func TestTiming_DetectsLeak(t *testing.T) {
+	crypkatest.SkipUnlessTimingEnabled(t)
+
+	secret := bytes.Repeat([]byte{0xaa}, 512)
+	tester := makeMismatchTimingTester(secret, func(input []byte) {
+		bytes.Equal(secret, input)
+	})
+
+	res, err := tester.Measure()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if res.T <= crypkatest.DefaultTimingThreshold {
+		t.Error("expected bytes.Equal to leak timing, got t =", res.T)
+	}
+}
+
+func TestTiming_HMACEqual(t *testing.T) {
+	secret := bytes.Repeat([]byte{0xaa}, 512)
+	makeMismatchTimingTester(secret, func(input []byte) {
+		hmac.Equal(secret, input)
+	}).Test(t)
+}